@@ -239,18 +239,9 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, clusterName string,
 		klog.Infof("Using SSH public key: %v\n", c.SSHPublicKey)
 	}
 
-	var phase cloudup.Phase
-	if c.Phase != "" {
-		switch strings.ToLower(c.Phase) {
-		case string(cloudup.PhaseNetwork):
-			phase = cloudup.PhaseNetwork
-		case string(cloudup.PhaseSecurity), "iam": // keeping IAM for backwards compatibility
-			phase = cloudup.PhaseSecurity
-		case string(cloudup.PhaseCluster):
-			phase = cloudup.PhaseCluster
-		default:
-			return results, fmt.Errorf("unknown phase %q, available phases: %s", c.Phase, strings.Join(cloudup.Phases.List(), ","))
-		}
+	phase, err := parsePhase(c.Phase)
+	if err != nil {
+		return results, err
 	}
 
 	lifecycleOverrideMap := make(map[string]fi.Lifecycle)
@@ -421,6 +412,27 @@ func RunUpdateCluster(ctx context.Context, f *util.Factory, clusterName string,
 	return results, nil
 }
 
+// parsePhase returns the phase named by the --phase flag, or the empty phase if none is given
+func parsePhase(name string) (cloudup.Phase, error) {
+	switch strings.ToLower(name) {
+	case "":
+		return cloudup.Phase(""), nil
+	case string(cloudup.PhaseNetwork):
+		return cloudup.PhaseNetwork, nil
+	case string(cloudup.PhaseSecurity):
+		return cloudup.PhaseSecurity, nil
+	case "iam": // keeping IAM for backwards compatibility
+		klog.Warningf("phase %q is deprecated and runs the %q phase; use %q to apply only IAM resources", name, cloudup.PhaseSecurity, cloudup.PhaseIAM)
+		return cloudup.PhaseSecurity, nil
+	case string(cloudup.PhaseIAM):
+		return cloudup.PhaseIAM, nil
+	case string(cloudup.PhaseCluster):
+		return cloudup.PhaseCluster, nil
+	default:
+		return "", fmt.Errorf("unknown phase %q, available phases: %s", name, strings.Join(cloudup.Phases.List(), ","))
+	}
+}
+
 func parseLifecycle(lifecycle string) (fi.Lifecycle, error) {
 	if v, ok := fi.LifecycleNameMap[lifecycle]; ok {
 		return v, nil
@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+)
+
+func TestParsePhase(t *testing.T) {
+	grid := []struct {
+		Name        string
+		Expected    cloudup.Phase
+		ExpectError bool
+	}{
+		{Name: "", Expected: cloudup.Phase("")},
+		{Name: "network", Expected: cloudup.PhaseNetwork},
+		{Name: "security", Expected: cloudup.PhaseSecurity},
+		{Name: "Security", Expected: cloudup.PhaseSecurity},
+		{Name: "iam", Expected: cloudup.PhaseSecurity},
+		{Name: "iam-only", Expected: cloudup.PhaseIAM},
+		{Name: "cluster", Expected: cloudup.PhaseCluster},
+		{Name: "firewall", ExpectError: true},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			phase, err := parsePhase(g.Name)
+			if g.ExpectError {
+				if err == nil {
+					t.Errorf("expected error for phase %q, got %q", g.Name, phase)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if phase != g.Expected {
+				t.Errorf("expected phase %q, got %q", g.Expected, phase)
+			}
+		})
+	}
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package main // import "k8s.io/kops/cmd/nodeup"
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -36,21 +39,23 @@ const (
 func main() {
 	klog.InitFlags(nil)
 
-	var flagConf, flagCacheDir, gitVersion string
+	var flagConf, flagCacheDir, flagSkipBuilders, gitVersion string
 	var flagRetries int
-	var dryrun, installSystemdUnit bool
+	var dryrun, installSystemdUnit, flagStrictAssetVerification bool
 	target := "direct"
 
 	if kops.GitVersion != "" {
 		gitVersion = fmt.Sprintf(" (git-%s)", kops.GitVersion)
 	}
 	fmt.Printf("nodeup version %s%s\n", kops.Version, gitVersion)
-	flag.StringVar(&flagConf, "conf", "node.yaml", "configuration location")
+	flag.StringVar(&flagConf, "conf", "node.yaml", "configuration location, or - to read the configuration from stdin")
 	flag.StringVar(&flagCacheDir, "cache", "/var/cache/nodeup", "the location for the local asset cache")
 	flag.IntVar(&flagRetries, "retries", -1, "maximum number of retries on failure: -1 means retry forever")
 	flag.BoolVar(&dryrun, "dryrun", false, "Don't create cloud resources; just show what would be done")
 	flag.StringVar(&target, "target", target, "Target - direct, cloudinit")
 	flag.BoolVar(&installSystemdUnit, "install-systemd-unit", installSystemdUnit, "If true, will install a systemd unit instead of running directly")
+	flag.StringVar(&flagSkipBuilders, "skip-builders", "", "comma-separated list of builders not to run, for example DockerBuilder")
+	flag.BoolVar(&flagStrictAssetVerification, "strict-asset-verification", false, "If true, abort if any asset does not match its declared hash, rather than trying the asset's other URLs")
 
 	if dryrun {
 		target = "dryrun"
@@ -63,6 +68,25 @@ func main() {
 		klog.Exitf("--conf is required")
 	}
 
+	// Read stdin once, so that the configuration is available on retries
+	var stdin []byte
+	if flagConf == "-" {
+		if installSystemdUnit {
+			klog.Exitf("--conf=- cannot be used with --install-systemd-unit")
+		}
+
+		var err error
+		stdin, err = ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			klog.Exitf("error reading configuration from stdin: %v", err)
+		}
+	}
+
+	var skipBuilders []string
+	if flagSkipBuilders != "" {
+		skipBuilders = strings.Split(flagSkipBuilders, ",")
+	}
+
 	retries := flagRetries
 
 	for {
@@ -110,9 +134,12 @@ func main() {
 			}
 		} else {
 			cmd := &nodeup.NodeUpCommand{
-				ConfigLocation: flagConf,
-				Target:         target,
-				CacheDir:       flagCacheDir,
+				ConfigLocation:          flagConf,
+				Stdin:                   bytes.NewReader(stdin),
+				SkipBuilders:            skipBuilders,
+				Target:                  target,
+				CacheDir:                flagCacheDir,
+				StrictAssetVerification: flagStrictAssetVerification,
 			}
 			err = cmd.Run(os.Stdout)
 			if err == nil {
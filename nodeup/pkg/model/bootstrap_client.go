@@ -23,7 +23,6 @@ import (
 	"strconv"
 
 	"k8s.io/kops/pkg/apis/kops"
-	"k8s.io/kops/pkg/wellknownports"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
@@ -62,7 +61,7 @@ func (b BootstrapClientBuilder) Build(c *fi.ModelBuilderContext) error {
 
 	baseURL := url.URL{
 		Scheme: "https",
-		Host:   net.JoinHostPort("kops-controller.internal."+b.Cluster.ObjectMeta.Name, strconv.Itoa(wellknownports.KopsControllerPort)),
+		Host:   net.JoinHostPort("kops-controller.internal."+b.Cluster.ObjectMeta.Name, strconv.Itoa(b.Cluster.Spec.GetKopsControllerPort())),
 		Path:   "/",
 	}
 
@@ -127,7 +127,7 @@ func (b *ContainerdBuilder) installContainerd(c *fi.ModelBuilderContext) error {
 	var containerRuntimeVersion string
 	if b.Cluster.Spec.ContainerRuntime == "containerd" {
 		if b.Cluster.Spec.Containerd != nil {
-			containerRuntimeVersion = fi.StringValue(b.Cluster.Spec.Containerd.Version)
+			containerRuntimeVersion = fi.StringValue(b.Cluster.Spec.Containerd.VersionForArchitecture(b.Architecture))
 		} else {
 			return fmt.Errorf("error finding contained version")
 		}
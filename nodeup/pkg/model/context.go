@@ -355,7 +355,7 @@ func (c *NodeupModelContext) UseEtcdManager() bool {
 func (c *NodeupModelContext) UseEtcdTLS() bool {
 	// @note: because we enforce that 'both' have to be enabled for TLS we only need to check one here.
 	for _, x := range c.Cluster.Spec.EtcdClusters {
-		if x.EnableEtcdTLS {
+		if fi.BoolValue(x.EnableEtcdTLS) {
 			return true
 		}
 	}
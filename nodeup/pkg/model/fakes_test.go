@@ -120,6 +120,22 @@ func (k fakeCAStore) ListKeysets() ([]*kops.Keyset, error) {
 	panic("fakeCAStore does not implement ListKeysets")
 }
 
+func (k fakeCAStore) ListKeysetsByType(t kops.KeysetType) ([]*kops.Keyset, error) {
+	panic("fakeCAStore does not implement ListKeysetsByType")
+}
+
 func (k fakeCAStore) DeleteKeysetItem(item *kops.Keyset, id string) error {
 	panic("fakeCAStore does not implement DeleteKeysetItem")
 }
+
+func (k fakeCAStore) PromoteToPrimary(name string, id string) error {
+	panic("fakeCAStore does not implement PromoteToPrimary")
+}
+
+func (k fakeCAStore) ExportClientBundle(caName, clientName string) ([]byte, []byte, []byte, error) {
+	panic("fakeCAStore does not implement ExportClientBundle")
+}
+
+func (k fakeCAStore) StoreKeysets(keysets map[string]*fi.Keyset) error {
+	panic("fakeCAStore does not implement StoreKeysets")
+}
@@ -79,9 +79,14 @@ func (f *FileAssetsBuilder) buildFileAssets(c *fi.ModelBuilderContext, assets []
 			Mode: s("0755"),
 		})
 
+		mode := asset.Mode
+		if mode == "" {
+			mode = "0440"
+		}
+
 		c.AddTask(&nodetasks.File{
 			Contents: fi.NewStringResource(content),
-			Mode:     s("0440"),
+			Mode:     s(mode),
 			Path:     assetPath,
 			Type:     nodetasks.FileType_File,
 		})
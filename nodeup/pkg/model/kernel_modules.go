@@ -0,0 +1,64 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strings"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+// KernelModulesBuilder configures the kernel modules to load on boot
+type KernelModulesBuilder struct {
+	*NodeupModelContext
+}
+
+var _ fi.ModelBuilder = &KernelModulesBuilder{}
+
+// Build writes the kernel modules to modules-load.d, so systemd-modules-load loads them on boot
+func (b *KernelModulesBuilder) Build(c *fi.ModelBuilderContext) error {
+	c.AddTask(&nodetasks.File{
+		Path:     "/etc/modules-load.d/99-kops.conf",
+		Contents: fi.NewStringResource(strings.Join(b.KernelModules(), "\n") + "\n"),
+		Type:     nodetasks.FileType_File,
+	})
+
+	return nil
+}
+
+// KernelModules returns the kernel modules to load: br_netfilter, followed by
+// those from the cluster spec and then the instance group, without duplicates.
+func (c *NodeupModelContext) KernelModules() []string {
+	modules := []string{"br_netfilter"}
+	seen := map[string]bool{"br_netfilter": true}
+
+	add := func(names []string) {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				modules = append(modules, name)
+			}
+		}
+	}
+	add(c.Cluster.Spec.KernelModules)
+	if c.NodeupConfig != nil {
+		add(c.NodeupConfig.KernelModules)
+	}
+
+	return modules
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+func TestKernelModulesBuilder(t *testing.T) {
+	b := &KernelModulesBuilder{
+		NodeupModelContext: &NodeupModelContext{
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					KernelModules: []string{"ip_vs", "br_netfilter", "nf_conntrack"},
+				},
+			},
+			NodeupConfig: &nodeup.Config{
+				KernelModules: []string{"nf_conntrack", "ip_vs_rr"},
+			},
+		},
+	}
+	ctx := &fi.ModelBuilderContext{
+		Tasks: map[string]fi.Task{},
+	}
+	if err := b.Build(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	task, ok := ctx.Tasks["File//etc/modules-load.d/99-kops.conf"].(*nodetasks.File)
+	if !ok {
+		t.Fatalf("expected file task for modules-load.d, got %v", ctx.Tasks)
+	}
+	contents, err := fi.ResourceAsString(task.Contents)
+	if err != nil {
+		t.Fatalf("unexpected error reading contents: %s", err)
+	}
+
+	expected := "br_netfilter\nip_vs\nnf_conntrack\nip_vs_rr\n"
+	if contents != expected {
+		t.Errorf("unexpected modules-load.d contents, expected %q, got %q", expected, contents)
+	}
+}
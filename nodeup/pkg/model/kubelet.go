@@ -461,18 +461,12 @@ func (b *KubeletBuilder) buildKubeletConfigSpec() (*kops.KubeletConfigSpec, erro
 			maxPods = *c.MaxPods
 		}
 
-		// AWS VPC CNI plugin-specific maximum pod calculation based on:
-		// https://github.com/aws/amazon-vpc-cni-k8s/blob/f52ad45/README.md
+		// AWS VPC CNI plugin-specific maximum pod calculation.
 		//
 		// Treat the calculated value as a hard max, since networking with the CNI
 		// plugin won't work correctly once we exceed that maximum.
-		enis := instanceType.InstanceENIs
-		ips := instanceType.InstanceIPsPerENI
-		if enis > 0 && ips > 0 {
-			instanceMaxPods := enis*(ips-1) + 2
-			if int32(instanceMaxPods) < maxPods {
-				maxPods = int32(instanceMaxPods)
-			}
+		if instanceMaxPods := instanceType.AmazonVPCMaxPods(); instanceMaxPods > 0 && int32(instanceMaxPods) < maxPods {
+			maxPods = int32(instanceMaxPods)
 		}
 
 		// Write back values that could have changed
@@ -35,6 +35,9 @@ const (
 	DefaultChannel = "stable"
 )
 
+// KnownChannels are the names of the channels published at DefaultChannelBase.
+var KnownChannels = []string{"alpha", DefaultChannel}
+
 type Channel struct {
 	metav1.TypeMeta `json:",inline"`
 	ObjectMeta      metav1.ObjectMeta `json:"metadata,omitempty"`
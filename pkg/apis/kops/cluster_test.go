@@ -19,8 +19,10 @@ package kops
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestWarmPoolSpec_IsEnabled(t *testing.T) {
@@ -154,6 +156,56 @@ func TestWarmPoolSpec_ResolveDefaults(t *testing.T) {
 	}
 }
 
+func TestWarmPoolSpec_ResolveDefaults_LifecycleHookGracePeriod(t *testing.T) {
+	minute := &metav1.Duration{Duration: time.Minute}
+	second := &metav1.Duration{Duration: time.Second}
+
+	for _, tc := range []struct {
+		name     string
+		cluster  *WarmPoolSpec
+		ig       *WarmPoolSpec
+		expected *metav1.Duration
+	}{
+		{
+			name: "unset",
+			ig:   &WarmPoolSpec{},
+		},
+		{
+			name:     "from cluster",
+			cluster:  &WarmPoolSpec{LifecycleHookGracePeriod: minute},
+			ig:       &WarmPoolSpec{},
+			expected: minute,
+		},
+		{
+			name:     "from cluster without instance group warm pool",
+			cluster:  &WarmPoolSpec{LifecycleHookGracePeriod: minute},
+			expected: minute,
+		},
+		{
+			name:     "from instance group",
+			ig:       &WarmPoolSpec{LifecycleHookGracePeriod: second},
+			expected: second,
+		},
+		{
+			name:     "instance group overrides cluster",
+			cluster:  &WarmPoolSpec{LifecycleHookGracePeriod: minute},
+			ig:       &WarmPoolSpec{LifecycleHookGracePeriod: second},
+			expected: second,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			instanceGroup := &InstanceGroup{
+				Spec: InstanceGroupSpec{
+					Role:     InstanceGroupRoleNode,
+					WarmPool: tc.ig,
+				},
+			}
+			resolved := tc.cluster.ResolveDefaults(instanceGroup)
+			assert.Equal(t, tc.expected, resolved.LifecycleHookGracePeriod)
+		})
+	}
+}
+
 func setFieldValue(aStruct interface{}, fieldName string, fieldValue interface{}) {
 	field := reflect.ValueOf(aStruct).Elem().FieldByName(fieldName)
 	fieldType := field.Type()
@@ -16,6 +16,8 @@ limitations under the License.
 
 package kops
 
+import "k8s.io/kops/util/pkg/architectures"
+
 // ContainerdConfig is the configuration for containerd
 type ContainerdConfig struct {
 	// Address of containerd's GRPC server (default "/run/containerd/containerd.sock").
@@ -36,4 +38,24 @@ type ContainerdConfig struct {
 	State *string `json:"state,omitempty" flag:"state"`
 	// Version used to pick the containerd package.
 	Version *string `json:"version,omitempty"`
+	// VersionAmd64 overrides the version used to pick the containerd package for AMD64 instances.
+	VersionAmd64 *string `json:"versionAmd64,omitempty"`
+	// VersionArm64 overrides the version used to pick the containerd package for ARM64 instances.
+	VersionArm64 *string `json:"versionArm64,omitempty"`
+}
+
+// VersionForArchitecture returns the version used to pick the containerd package for the architecture,
+// which is the architecture specific override if set, otherwise Version.
+func (c *ContainerdConfig) VersionForArchitecture(arch architectures.Architecture) *string {
+	switch arch {
+	case architectures.ArchitectureAmd64:
+		if c.VersionAmd64 != nil {
+			return c.VersionAmd64
+		}
+	case architectures.ArchitectureArm64:
+		if c.VersionArm64 != nil {
+			return c.VersionArm64
+		}
+	}
+	return c.Version
 }
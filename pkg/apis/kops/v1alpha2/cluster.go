@@ -87,6 +87,9 @@ type ClusterSpec struct {
 	SecretStore string `json:"secretStore,omitempty"`
 	// KeyStore is the VFS path to where SSL keys and certificates are stored
 	KeyStore string `json:"keyStore,omitempty"`
+	// CAKeyType is the type of private key generated for new certificate authorities.
+	// Options: rsa-2048 (default), rsa-4096, or ecdsa-p256
+	CAKeyType string `json:"caKeyType,omitempty"`
 	// ConfigStore is the VFS path to where the configuration (Cluster, InstanceGroups etc) is stored
 	ConfigStore string `json:"configStore,omitempty"`
 	// DNSZone is the DNS zone we should use when configuring DNS
@@ -119,6 +122,9 @@ type ClusterSpec struct {
 	EgressProxy *EgressProxySpec `json:"egressProxy,omitempty"`
 	// SSHKeyName specifies a preexisting SSH key to use
 	SSHKeyName *string `json:"sshKeyName,omitempty"`
+	// DisableSSHAccess disables SSH access to the instances, for clusters accessed only through other means such as AWS SSM.
+	// No SSH key is set on the instances, and SSHAccess is ignored. Only supported on AWS.
+	DisableSSHAccess bool `json:"disableSSHAccess,omitempty"`
 	// KubernetesAPIAccess determines the permitted access to the API endpoints (master HTTPS)
 	// Currently only a single CIDR is supported (though a richer grammar could be added in future)
 	KubernetesAPIAccess []string `json:"kubernetesApiAccess,omitempty"`
@@ -183,6 +189,16 @@ type ClusterSpec struct {
 	Hooks []HookSpec `json:"hooks,omitempty"`
 	// Alternative locations for files and containers
 	Assets *Assets `json:"assets,omitempty"`
+	// NodeUp overrides the location of the nodeup binary, which is otherwise derived from the kOps base URL.
+	NodeUp *NodeUpSpec `json:"nodeUp,omitempty"`
+	// Protokube overrides the location of the protokube binary, which is otherwise derived from the kOps base URL.
+	Protokube *ProtokubeSpec `json:"protokube,omitempty"`
+	// Channels overrides the location of the channels binary, which is otherwise derived from the kOps base URL.
+	// It is unrelated to Channel, which is the location of the channel definition.
+	Channels *ChannelsSpec `json:"channels,omitempty"`
+	// PreloadImages are additional container images to pull onto instances before they are needed, keyed by lower-case instance group role
+	// (master, apiserver, node, bastion). Each entry is an image reference, optionally pinned with a digest.
+	PreloadImages map[string][]string `json:"preloadImages,omitempty"`
 	// IAM field adds control over the IAM security policies applied to resources
 	IAM *IAMSpec `json:"iam,omitempty"`
 	// EncryptionConfig holds the encryption config
@@ -198,6 +214,9 @@ type ClusterSpec struct {
 	// specified, each parameter must follow the form variable=value, the way
 	// it would appear in sysctl.conf.
 	SysctlParameters []string `json:"sysctlParameters,omitempty"`
+	// KernelModules are additional kernel modules to load on the instances, and to load again on boot.
+	// The br_netfilter module is always loaded.
+	KernelModules []string `json:"kernelModules,omitempty"`
 	// RollingUpdate defines the default rolling-update settings for instance groups
 	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
 	// ClusterAutoscaler defines the cluaster autoscaler configuration.
@@ -210,6 +229,9 @@ type ClusterSpec struct {
 
 	// SnapshotController defines the CSI Snapshot Controller configuration.
 	SnapshotController *SnapshotControllerConfig `json:"snapshotController,omitempty"`
+
+	// KopsControllerPort is the port on which kops-controller serves node bootstrap requests (default 3988).
+	KopsControllerPort int32 `json:"kopsControllerPort,omitempty"`
 }
 
 // ServiceAccountIssuerDiscoveryConfig configures an OIDC Issuer.
@@ -282,6 +304,8 @@ type FileAssetSpec struct {
 	Content string `json:"content,omitempty"`
 	// IsBase64 indicates the contents is base64 encoded
 	IsBase64 bool `json:"isBase64,omitempty"`
+	// Mode is the file mode as an octal string (default: 0440)
+	Mode string `json:"mode,omitempty"`
 }
 
 // Assets defined the privately hosted assets
@@ -292,6 +316,45 @@ type Assets struct {
 	FileRepository *string `json:"fileRepository,omitempty"`
 	// ContainerProxy is a url for a pull-through proxy of a docker registry
 	ContainerProxy *string `json:"containerProxy,omitempty"`
+	// ContainerRegistryMirrors maps upstream registry hosts, such as docker.io or quay.io, to the registries mirroring them.
+	// Images from a mirrored registry are pulled from its mirror in preference to the ContainerRegistry.
+	ContainerRegistryMirrors map[string]string `json:"containerRegistryMirrors,omitempty"`
+}
+
+// NodeUpSpec overrides the location of the nodeup binary
+type NodeUpSpec struct {
+	// URL overrides the URL of the nodeup binary for AMD64 instances.
+	URL string `json:"url,omitempty"`
+	// Hash is the SHA256 hash of the nodeup binary at URL.
+	Hash string `json:"hash,omitempty"`
+	// URLArm64 overrides the URL of the nodeup binary for ARM64 instances.
+	URLArm64 string `json:"urlArm64,omitempty"`
+	// HashArm64 is the SHA256 hash of the nodeup binary at URLArm64.
+	HashArm64 string `json:"hashArm64,omitempty"`
+}
+
+// ProtokubeSpec overrides the location of the protokube binary
+type ProtokubeSpec struct {
+	// URL overrides the URL of the protokube binary for AMD64 instances.
+	URL string `json:"url,omitempty"`
+	// Hash is the SHA256 hash of the protokube binary at URL.
+	Hash string `json:"hash,omitempty"`
+	// URLArm64 overrides the URL of the protokube binary for ARM64 instances.
+	URLArm64 string `json:"urlArm64,omitempty"`
+	// HashArm64 is the SHA256 hash of the protokube binary at URLArm64.
+	HashArm64 string `json:"hashArm64,omitempty"`
+}
+
+// ChannelsSpec overrides the location of the channels binary
+type ChannelsSpec struct {
+	// URL overrides the URL of the channels binary for AMD64 instances.
+	URL string `json:"url,omitempty"`
+	// Hash is the SHA256 hash of the channels binary at URL.
+	Hash string `json:"hash,omitempty"`
+	// URLArm64 overrides the URL of the channels binary for ARM64 instances.
+	URLArm64 string `json:"urlArm64,omitempty"`
+	// HashArm64 is the SHA256 hash of the channels binary at URLArm64.
+	HashArm64 string `json:"hashArm64,omitempty"`
 }
 
 // IAMSpec adds control over the IAM security policies applied to resources
@@ -299,6 +362,9 @@ type IAMSpec struct {
 	Legacy                 bool    `json:"legacy"`
 	AllowContainerRegistry bool    `json:"allowContainerRegistry,omitempty"`
 	PermissionsBoundary    *string `json:"permissionsBoundary,omitempty"`
+	// KopsControllerAdditionalPolicy is a JSON array of IAM statements to merge into the policy of the masters,
+	// which is used by kops-controller, e.g. for custom controllers sharing its role.
+	KopsControllerAdditionalPolicy string `json:"kopsControllerAdditionalPolicy,omitempty"`
 	// ServiceAccountExternalPermissions defines the relatinship between Kubernetes ServiceAccounts and permissions with external resources.
 	ServiceAccountExternalPermissions []ServiceAccountExternalPermission `json:"serviceAccountExternalPermissions,omitempty"`
 }
@@ -526,8 +592,9 @@ type EtcdClusterSpec struct {
 	Provider EtcdProviderType `json:"provider,omitempty"`
 	// Members stores the configurations for each member of the cluster (including the data volume)
 	Members []EtcdMemberSpec `json:"etcdMembers,omitempty"`
-	// EnableEtcdTLS indicates the etcd service should use TLS between peers and clients
-	EnableEtcdTLS bool `json:"enableEtcdTLS,omitempty"`
+	// EnableEtcdTLS indicates the etcd service should use TLS between peers and clients.
+	// TLS is always enabled with the Manager provider.
+	EnableEtcdTLS *bool `json:"enableEtcdTLS,omitempty"`
 	// EnableTLSAuth indicates client and peer TLS auth should be enforced
 	EnableTLSAuth bool `json:"enableTLSAuth,omitempty"`
 	// Version is the version of etcd to run.
@@ -756,4 +823,7 @@ type WarmPoolSpec struct {
 	// EnableLifecycleHook determines if an ASG lifecycle hook will be added ensuring that nodeup runs to completion.
 	// Note that the metadata API must be protected from arbitrary Pods when this is enabled.
 	EnableLifecycleHook bool `json:"enableLifecycleHook,omitempty"`
+	// LifecycleHookGracePeriod is how long nodeup waits after it completes before continuing the lifecycle hook,
+	// to let services stabilize. It must be less than the lifecycle hook's heartbeat timeout of 10 minutes.
+	LifecycleHookGracePeriod *metav1.Duration `json:"lifecycleHookGracePeriod,omitempty"`
 }
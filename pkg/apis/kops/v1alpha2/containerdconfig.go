@@ -36,4 +36,8 @@ type ContainerdConfig struct {
 	State *string `json:"state,omitempty" flag:"state"`
 	// Version used to pick the containerd package.
 	Version *string `json:"version,omitempty"`
+	// VersionAmd64 overrides the version used to pick the containerd package for AMD64 instances.
+	VersionAmd64 *string `json:"versionAmd64,omitempty"`
+	// VersionArm64 overrides the version used to pick the containerd package for ARM64 instances.
+	VersionArm64 *string `json:"versionArm64,omitempty"`
 }
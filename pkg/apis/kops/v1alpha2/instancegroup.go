@@ -133,6 +133,9 @@ type InstanceGroupSpec struct {
 	// specified, each parameter must follow the form variable=value, the way
 	// it would appear in sysctl.conf.
 	SysctlParameters []string `json:"sysctlParameters,omitempty"`
+	// KernelModules are additional kernel modules to load on the instances, and to load again on boot.
+	// The br_netfilter module is always loaded.
+	KernelModules []string `json:"kernelModules,omitempty"`
 	// RollingUpdate defines the rolling-update behavior
 	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
 	// InstanceInterruptionBehavior defines if a spot instance should be terminated, hibernated,
@@ -138,7 +138,7 @@ type CalicoNetworkingSpec struct {
 	// employing such encapsulation at the necessary scope per the related CrossSubnet field. In
 	// "ipip" mode, Calico will use IP-in-IP encapsulation as needed. In "vxlan" mode, Calico will
 	// encapsulate packets as needed using the VXLAN scheme.
-	// Options: ipip (default) or vxlan
+	// Options: ipip (default), vxlan, or none
 	EncapsulationMode string `json:"encapsulationMode,omitempty"`
 	// IPIPMode is the encapsulation mode to use for the default Calico IPv4 pool created at start
 	// up, determining when to use IP-in-IP encapsulation, conveyed to the "calico-node" daemon
@@ -163,6 +163,11 @@ type CalicoNetworkingSpec struct {
 	LogSeverityScreen string `json:"logSeverityScreen,omitempty"`
 	// MTU to be set in the cni-network-config for calico.
 	MTU *int32 `json:"mtu,omitempty"`
+	// NetworkingBackend selects the Calico networking backend, conveyed to the "calico-node"
+	// daemon container via the CALICO_NETWORKING_BACKEND environment variable. The "bird"
+	// backend uses BGP to distribute routes, which is required when encapsulationMode is "none".
+	// Options: bird (default unless encapsulationMode is vxlan), vxlan, or none
+	NetworkingBackend string `json:"networkingBackend,omitempty"`
 	// PrometheusMetricsEnabled can be set to enable the experimental Prometheus
 	// metrics server (default: false)
 	PrometheusMetricsEnabled bool `json:"prometheusMetricsEnabled,omitempty"`
@@ -235,6 +240,8 @@ type CanalNetworkingSpec struct {
 
 // KuberouterNetworkingSpec declares that we want Kube-router networking
 type KuberouterNetworkingSpec struct {
+	// RunServiceProxy enables kube-router's service proxy, which replaces kube-proxy. Default: true
+	RunServiceProxy *bool `json:"runServiceProxy,omitempty"`
 }
 
 // RomanaNetworkingSpec declares that we want Romana networking
@@ -213,6 +213,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ChannelsSpec)(nil), (*kops.ChannelsSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ChannelsSpec_To_kops_ChannelsSpec(a.(*ChannelsSpec), b.(*kops.ChannelsSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.ChannelsSpec)(nil), (*ChannelsSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_ChannelsSpec_To_v1alpha2_ChannelsSpec(a.(*kops.ChannelsSpec), b.(*ChannelsSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*CiliumNetworkingSpec)(nil), (*kops.CiliumNetworkingSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_CiliumNetworkingSpec_To_kops_CiliumNetworkingSpec(a.(*CiliumNetworkingSpec), b.(*kops.CiliumNetworkingSpec), scope)
 	}); err != nil {
@@ -863,6 +873,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*NodeUpSpec)(nil), (*kops.NodeUpSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_NodeUpSpec_To_kops_NodeUpSpec(a.(*NodeUpSpec), b.(*kops.NodeUpSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.NodeUpSpec)(nil), (*NodeUpSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_NodeUpSpec_To_v1alpha2_NodeUpSpec(a.(*kops.NodeUpSpec), b.(*NodeUpSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*OpenstackBlockStorageConfig)(nil), (*kops.OpenstackBlockStorageConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_OpenstackBlockStorageConfig_To_kops_OpenstackBlockStorageConfig(a.(*OpenstackBlockStorageConfig), b.(*kops.OpenstackBlockStorageConfig), scope)
 	}); err != nil {
@@ -933,6 +953,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ProtokubeSpec)(nil), (*kops.ProtokubeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ProtokubeSpec_To_kops_ProtokubeSpec(a.(*ProtokubeSpec), b.(*kops.ProtokubeSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kops.ProtokubeSpec)(nil), (*ProtokubeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kops_ProtokubeSpec_To_v1alpha2_ProtokubeSpec(a.(*kops.ProtokubeSpec), b.(*ProtokubeSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*RBACAuthorizationSpec)(nil), (*kops.RBACAuthorizationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(a.(*RBACAuthorizationSpec), b.(*kops.RBACAuthorizationSpec), scope)
 	}); err != nil {
@@ -1314,6 +1344,7 @@ func autoConvert_v1alpha2_Assets_To_kops_Assets(in *Assets, out *kops.Assets, s
 	out.ContainerRegistry = in.ContainerRegistry
 	out.FileRepository = in.FileRepository
 	out.ContainerProxy = in.ContainerProxy
+	out.ContainerRegistryMirrors = in.ContainerRegistryMirrors
 	return nil
 }
 
@@ -1326,6 +1357,7 @@ func autoConvert_kops_Assets_To_v1alpha2_Assets(in *kops.Assets, out *Assets, s
 	out.ContainerRegistry = in.ContainerRegistry
 	out.FileRepository = in.FileRepository
 	out.ContainerProxy = in.ContainerProxy
+	out.ContainerRegistryMirrors = in.ContainerRegistryMirrors
 	return nil
 }
 
@@ -1600,6 +1632,7 @@ func autoConvert_v1alpha2_CalicoNetworkingSpec_To_kops_CalicoNetworkingSpec(in *
 	out.IptablesBackend = in.IptablesBackend
 	out.LogSeverityScreen = in.LogSeverityScreen
 	out.MTU = in.MTU
+	out.NetworkingBackend = in.NetworkingBackend
 	out.PrometheusMetricsEnabled = in.PrometheusMetricsEnabled
 	out.PrometheusMetricsPort = in.PrometheusMetricsPort
 	out.PrometheusGoMetricsEnabled = in.PrometheusGoMetricsEnabled
@@ -1635,6 +1668,7 @@ func autoConvert_kops_CalicoNetworkingSpec_To_v1alpha2_CalicoNetworkingSpec(in *
 	out.IptablesBackend = in.IptablesBackend
 	out.LogSeverityScreen = in.LogSeverityScreen
 	out.MTU = in.MTU
+	out.NetworkingBackend = in.NetworkingBackend
 	out.PrometheusMetricsEnabled = in.PrometheusMetricsEnabled
 	out.PrometheusMetricsPort = in.PrometheusMetricsPort
 	out.PrometheusGoMetricsEnabled = in.PrometheusGoMetricsEnabled
@@ -1726,6 +1760,32 @@ func Convert_kops_CertManagerConfig_To_v1alpha2_CertManagerConfig(in *kops.CertM
 	return autoConvert_kops_CertManagerConfig_To_v1alpha2_CertManagerConfig(in, out, s)
 }
 
+func autoConvert_v1alpha2_ChannelsSpec_To_kops_ChannelsSpec(in *ChannelsSpec, out *kops.ChannelsSpec, s conversion.Scope) error {
+	out.URL = in.URL
+	out.Hash = in.Hash
+	out.URLArm64 = in.URLArm64
+	out.HashArm64 = in.HashArm64
+	return nil
+}
+
+// Convert_v1alpha2_ChannelsSpec_To_kops_ChannelsSpec is an autogenerated conversion function.
+func Convert_v1alpha2_ChannelsSpec_To_kops_ChannelsSpec(in *ChannelsSpec, out *kops.ChannelsSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ChannelsSpec_To_kops_ChannelsSpec(in, out, s)
+}
+
+func autoConvert_kops_ChannelsSpec_To_v1alpha2_ChannelsSpec(in *kops.ChannelsSpec, out *ChannelsSpec, s conversion.Scope) error {
+	out.URL = in.URL
+	out.Hash = in.Hash
+	out.URLArm64 = in.URLArm64
+	out.HashArm64 = in.HashArm64
+	return nil
+}
+
+// Convert_kops_ChannelsSpec_To_v1alpha2_ChannelsSpec is an autogenerated conversion function.
+func Convert_kops_ChannelsSpec_To_v1alpha2_ChannelsSpec(in *kops.ChannelsSpec, out *ChannelsSpec, s conversion.Scope) error {
+	return autoConvert_kops_ChannelsSpec_To_v1alpha2_ChannelsSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_CiliumNetworkingSpec_To_kops_CiliumNetworkingSpec(in *CiliumNetworkingSpec, out *kops.CiliumNetworkingSpec, s conversion.Scope) error {
 	out.Version = in.Version
 	out.MemoryRequest = in.MemoryRequest
@@ -2256,6 +2316,7 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	}
 	out.SecretStore = in.SecretStore
 	out.KeyStore = in.KeyStore
+	out.CAKeyType = in.CAKeyType
 	out.ConfigStore = in.ConfigStore
 	out.DNSZone = in.DNSZone
 	if in.DNSControllerGossipConfig != nil {
@@ -2284,6 +2345,7 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 		out.EgressProxy = nil
 	}
 	out.SSHKeyName = in.SSHKeyName
+	out.DisableSSHAccess = in.DisableSSHAccess
 	out.KubernetesAPIAccess = in.KubernetesAPIAccess
 	out.IsolateMasters = in.IsolateMasters
 	out.UpdatePolicy = in.UpdatePolicy
@@ -2530,6 +2592,34 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.Assets = nil
 	}
+	if in.NodeUp != nil {
+		in, out := &in.NodeUp, &out.NodeUp
+		*out = new(kops.NodeUpSpec)
+		if err := Convert_v1alpha2_NodeUpSpec_To_kops_NodeUpSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeUp = nil
+	}
+	if in.Protokube != nil {
+		in, out := &in.Protokube, &out.Protokube
+		*out = new(kops.ProtokubeSpec)
+		if err := Convert_v1alpha2_ProtokubeSpec_To_kops_ProtokubeSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Protokube = nil
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = new(kops.ChannelsSpec)
+		if err := Convert_v1alpha2_ChannelsSpec_To_kops_ChannelsSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Channels = nil
+	}
+	out.PreloadImages = in.PreloadImages
 	if in.IAM != nil {
 		in, out := &in.IAM, &out.IAM
 		*out = new(kops.IAMSpec)
@@ -2552,6 +2642,7 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	}
 	out.UseHostCertificates = in.UseHostCertificates
 	out.SysctlParameters = in.SysctlParameters
+	out.KernelModules = in.KernelModules
 	if in.RollingUpdate != nil {
 		in, out := &in.RollingUpdate, &out.RollingUpdate
 		*out = new(kops.RollingUpdate)
@@ -2597,6 +2688,7 @@ func autoConvert_v1alpha2_ClusterSpec_To_kops_ClusterSpec(in *ClusterSpec, out *
 	} else {
 		out.SnapshotController = nil
 	}
+	out.KopsControllerPort = in.KopsControllerPort
 	return nil
 }
 
@@ -2659,6 +2751,7 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	}
 	out.SecretStore = in.SecretStore
 	out.KeyStore = in.KeyStore
+	out.CAKeyType = in.CAKeyType
 	out.ConfigStore = in.ConfigStore
 	out.DNSZone = in.DNSZone
 	if in.DNSControllerGossipConfig != nil {
@@ -2687,6 +2780,7 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 		out.EgressProxy = nil
 	}
 	out.SSHKeyName = in.SSHKeyName
+	out.DisableSSHAccess = in.DisableSSHAccess
 	out.KubernetesAPIAccess = in.KubernetesAPIAccess
 	out.IsolateMasters = in.IsolateMasters
 	out.UpdatePolicy = in.UpdatePolicy
@@ -2933,6 +3027,34 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.Assets = nil
 	}
+	if in.NodeUp != nil {
+		in, out := &in.NodeUp, &out.NodeUp
+		*out = new(NodeUpSpec)
+		if err := Convert_kops_NodeUpSpec_To_v1alpha2_NodeUpSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.NodeUp = nil
+	}
+	if in.Protokube != nil {
+		in, out := &in.Protokube, &out.Protokube
+		*out = new(ProtokubeSpec)
+		if err := Convert_kops_ProtokubeSpec_To_v1alpha2_ProtokubeSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Protokube = nil
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = new(ChannelsSpec)
+		if err := Convert_kops_ChannelsSpec_To_v1alpha2_ChannelsSpec(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Channels = nil
+	}
+	out.PreloadImages = in.PreloadImages
 	if in.IAM != nil {
 		in, out := &in.IAM, &out.IAM
 		*out = new(IAMSpec)
@@ -2955,6 +3077,7 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	}
 	out.UseHostCertificates = in.UseHostCertificates
 	out.SysctlParameters = in.SysctlParameters
+	out.KernelModules = in.KernelModules
 	if in.RollingUpdate != nil {
 		in, out := &in.RollingUpdate, &out.RollingUpdate
 		*out = new(RollingUpdate)
@@ -3000,6 +3123,7 @@ func autoConvert_kops_ClusterSpec_To_v1alpha2_ClusterSpec(in *kops.ClusterSpec,
 	} else {
 		out.SnapshotController = nil
 	}
+	out.KopsControllerPort = in.KopsControllerPort
 	return nil
 }
 
@@ -3062,6 +3186,8 @@ func autoConvert_v1alpha2_ContainerdConfig_To_kops_ContainerdConfig(in *Containe
 	out.SkipInstall = in.SkipInstall
 	out.State = in.State
 	out.Version = in.Version
+	out.VersionAmd64 = in.VersionAmd64
+	out.VersionArm64 = in.VersionArm64
 	return nil
 }
 
@@ -3088,6 +3214,8 @@ func autoConvert_kops_ContainerdConfig_To_v1alpha2_ContainerdConfig(in *kops.Con
 	out.SkipInstall = in.SkipInstall
 	out.State = in.State
 	out.Version = in.Version
+	out.VersionAmd64 = in.VersionAmd64
+	out.VersionArm64 = in.VersionArm64
 	return nil
 }
 
@@ -3624,6 +3752,7 @@ func autoConvert_v1alpha2_FileAssetSpec_To_kops_FileAssetSpec(in *FileAssetSpec,
 	}
 	out.Content = in.Content
 	out.IsBase64 = in.IsBase64
+	out.Mode = in.Mode
 	return nil
 }
 
@@ -3646,6 +3775,7 @@ func autoConvert_kops_FileAssetSpec_To_v1alpha2_FileAssetSpec(in *kops.FileAsset
 	}
 	out.Content = in.Content
 	out.IsBase64 = in.IsBase64
+	out.Mode = in.Mode
 	return nil
 }
 
@@ -3896,6 +4026,7 @@ func autoConvert_v1alpha2_IAMSpec_To_kops_IAMSpec(in *IAMSpec, out *kops.IAMSpec
 	out.Legacy = in.Legacy
 	out.AllowContainerRegistry = in.AllowContainerRegistry
 	out.PermissionsBoundary = in.PermissionsBoundary
+	out.KopsControllerAdditionalPolicy = in.KopsControllerAdditionalPolicy
 	if in.ServiceAccountExternalPermissions != nil {
 		in, out := &in.ServiceAccountExternalPermissions, &out.ServiceAccountExternalPermissions
 		*out = make([]kops.ServiceAccountExternalPermission, len(*in))
@@ -3919,6 +4050,7 @@ func autoConvert_kops_IAMSpec_To_v1alpha2_IAMSpec(in *kops.IAMSpec, out *IAMSpec
 	out.Legacy = in.Legacy
 	out.AllowContainerRegistry = in.AllowContainerRegistry
 	out.PermissionsBoundary = in.PermissionsBoundary
+	out.KopsControllerAdditionalPolicy = in.KopsControllerAdditionalPolicy
 	if in.ServiceAccountExternalPermissions != nil {
 		in, out := &in.ServiceAccountExternalPermissions, &out.ServiceAccountExternalPermissions
 		*out = make([]ServiceAccountExternalPermission, len(*in))
@@ -4130,6 +4262,7 @@ func autoConvert_v1alpha2_InstanceGroupSpec_To_kops_InstanceGroupSpec(in *Instan
 	out.SecurityGroupOverride = in.SecurityGroupOverride
 	out.InstanceProtection = in.InstanceProtection
 	out.SysctlParameters = in.SysctlParameters
+	out.KernelModules = in.KernelModules
 	if in.RollingUpdate != nil {
 		in, out := &in.RollingUpdate, &out.RollingUpdate
 		*out = new(kops.RollingUpdate)
@@ -4292,6 +4425,7 @@ func autoConvert_kops_InstanceGroupSpec_To_v1alpha2_InstanceGroupSpec(in *kops.I
 	out.SecurityGroupOverride = in.SecurityGroupOverride
 	out.InstanceProtection = in.InstanceProtection
 	out.SysctlParameters = in.SysctlParameters
+	out.KernelModules = in.KernelModules
 	if in.RollingUpdate != nil {
 		in, out := &in.RollingUpdate, &out.RollingUpdate
 		*out = new(RollingUpdate)
@@ -5275,6 +5409,7 @@ func Convert_kops_KubenetNetworkingSpec_To_v1alpha2_KubenetNetworkingSpec(in *ko
 }
 
 func autoConvert_v1alpha2_KuberouterNetworkingSpec_To_kops_KuberouterNetworkingSpec(in *KuberouterNetworkingSpec, out *kops.KuberouterNetworkingSpec, s conversion.Scope) error {
+	out.RunServiceProxy = in.RunServiceProxy
 	return nil
 }
 
@@ -5284,6 +5419,7 @@ func Convert_v1alpha2_KuberouterNetworkingSpec_To_kops_KuberouterNetworkingSpec(
 }
 
 func autoConvert_kops_KuberouterNetworkingSpec_To_v1alpha2_KuberouterNetworkingSpec(in *kops.KuberouterNetworkingSpec, out *KuberouterNetworkingSpec, s conversion.Scope) error {
+	out.RunServiceProxy = in.RunServiceProxy
 	return nil
 }
 
@@ -5942,6 +6078,32 @@ func Convert_kops_NodeTerminationHandlerConfig_To_v1alpha2_NodeTerminationHandle
 	return autoConvert_kops_NodeTerminationHandlerConfig_To_v1alpha2_NodeTerminationHandlerConfig(in, out, s)
 }
 
+func autoConvert_v1alpha2_NodeUpSpec_To_kops_NodeUpSpec(in *NodeUpSpec, out *kops.NodeUpSpec, s conversion.Scope) error {
+	out.URL = in.URL
+	out.Hash = in.Hash
+	out.URLArm64 = in.URLArm64
+	out.HashArm64 = in.HashArm64
+	return nil
+}
+
+// Convert_v1alpha2_NodeUpSpec_To_kops_NodeUpSpec is an autogenerated conversion function.
+func Convert_v1alpha2_NodeUpSpec_To_kops_NodeUpSpec(in *NodeUpSpec, out *kops.NodeUpSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NodeUpSpec_To_kops_NodeUpSpec(in, out, s)
+}
+
+func autoConvert_kops_NodeUpSpec_To_v1alpha2_NodeUpSpec(in *kops.NodeUpSpec, out *NodeUpSpec, s conversion.Scope) error {
+	out.URL = in.URL
+	out.Hash = in.Hash
+	out.URLArm64 = in.URLArm64
+	out.HashArm64 = in.HashArm64
+	return nil
+}
+
+// Convert_kops_NodeUpSpec_To_v1alpha2_NodeUpSpec is an autogenerated conversion function.
+func Convert_kops_NodeUpSpec_To_v1alpha2_NodeUpSpec(in *kops.NodeUpSpec, out *NodeUpSpec, s conversion.Scope) error {
+	return autoConvert_kops_NodeUpSpec_To_v1alpha2_NodeUpSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_OpenstackBlockStorageConfig_To_kops_OpenstackBlockStorageConfig(in *OpenstackBlockStorageConfig, out *kops.OpenstackBlockStorageConfig, s conversion.Scope) error {
 	out.Version = in.Version
 	out.IgnoreAZ = in.IgnoreAZ
@@ -6212,6 +6374,32 @@ func Convert_kops_PackagesConfig_To_v1alpha2_PackagesConfig(in *kops.PackagesCon
 	return autoConvert_kops_PackagesConfig_To_v1alpha2_PackagesConfig(in, out, s)
 }
 
+func autoConvert_v1alpha2_ProtokubeSpec_To_kops_ProtokubeSpec(in *ProtokubeSpec, out *kops.ProtokubeSpec, s conversion.Scope) error {
+	out.URL = in.URL
+	out.Hash = in.Hash
+	out.URLArm64 = in.URLArm64
+	out.HashArm64 = in.HashArm64
+	return nil
+}
+
+// Convert_v1alpha2_ProtokubeSpec_To_kops_ProtokubeSpec is an autogenerated conversion function.
+func Convert_v1alpha2_ProtokubeSpec_To_kops_ProtokubeSpec(in *ProtokubeSpec, out *kops.ProtokubeSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ProtokubeSpec_To_kops_ProtokubeSpec(in, out, s)
+}
+
+func autoConvert_kops_ProtokubeSpec_To_v1alpha2_ProtokubeSpec(in *kops.ProtokubeSpec, out *ProtokubeSpec, s conversion.Scope) error {
+	out.URL = in.URL
+	out.Hash = in.Hash
+	out.URLArm64 = in.URLArm64
+	out.HashArm64 = in.HashArm64
+	return nil
+}
+
+// Convert_kops_ProtokubeSpec_To_v1alpha2_ProtokubeSpec is an autogenerated conversion function.
+func Convert_kops_ProtokubeSpec_To_v1alpha2_ProtokubeSpec(in *kops.ProtokubeSpec, out *ProtokubeSpec, s conversion.Scope) error {
+	return autoConvert_kops_ProtokubeSpec_To_v1alpha2_ProtokubeSpec(in, out, s)
+}
+
 func autoConvert_v1alpha2_RBACAuthorizationSpec_To_kops_RBACAuthorizationSpec(in *RBACAuthorizationSpec, out *kops.RBACAuthorizationSpec, s conversion.Scope) error {
 	return nil
 }
@@ -6652,6 +6840,7 @@ func autoConvert_v1alpha2_WarmPoolSpec_To_kops_WarmPoolSpec(in *WarmPoolSpec, ou
 	out.MinSize = in.MinSize
 	out.MaxSize = in.MaxSize
 	out.EnableLifecycleHook = in.EnableLifecycleHook
+	out.LifecycleHookGracePeriod = in.LifecycleHookGracePeriod
 	return nil
 }
 
@@ -6664,6 +6853,7 @@ func autoConvert_kops_WarmPoolSpec_To_v1alpha2_WarmPoolSpec(in *kops.WarmPoolSpe
 	out.MinSize = in.MinSize
 	out.MaxSize = in.MaxSize
 	out.EnableLifecycleHook = in.EnableLifecycleHook
+	out.LifecycleHookGracePeriod = in.LifecycleHookGracePeriod
 	return nil
 }
 
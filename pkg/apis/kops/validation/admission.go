@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kops/pkg/apis/kops/util"
+)
+
+// admissionPluginVersions records the kubernetes versions in which admission plugins were added or removed.
+// An empty version means the plugin was added before, or has not been removed in, any kubernetes version supported by kOps.
+var admissionPluginVersions = map[string]struct {
+	added   string
+	removed string
+}{
+	"AlwaysAdmit":                          {},
+	"AlwaysDeny":                           {},
+	"AlwaysPullImages":                     {},
+	"CertificateApproval":                  {added: "1.18"},
+	"CertificateSigning":                   {added: "1.18"},
+	"CertificateSubjectRestriction":        {added: "1.18"},
+	"DefaultIngressClass":                  {added: "1.18"},
+	"DefaultStorageClass":                  {},
+	"DefaultTolerationSeconds":             {},
+	"DenyEscalatingExec":                   {removed: "1.21"},
+	"DenyExecOnPrivileged":                 {removed: "1.21"},
+	"DenyServiceExternalIPs":               {added: "1.21"},
+	"EventRateLimit":                       {},
+	"ExtendedResourceToleration":           {},
+	"ImagePolicyWebhook":                   {},
+	"LimitPodHardAntiAffinityTopology":     {},
+	"LimitRanger":                          {},
+	"MutatingAdmissionWebhook":             {},
+	"NamespaceAutoProvision":               {},
+	"NamespaceExists":                      {},
+	"NamespaceLifecycle":                   {},
+	"NodeRestriction":                      {},
+	"OwnerReferencesPermissionEnforcement": {},
+	"PersistentVolumeClaimResize":          {},
+	"PersistentVolumeLabel":                {},
+	"PodNodeSelector":                      {},
+	"PodPreset":                            {removed: "1.20"},
+	"PodSecurity":                          {added: "1.22"},
+	"PodSecurityPolicy":                    {removed: "1.25"},
+	"PodTolerationRestriction":             {},
+	"Priority":                             {},
+	"ResourceQuota":                        {},
+	"RuntimeClass":                         {},
+	"SecurityContextDeny":                  {},
+	"ServiceAccount":                       {},
+	"StorageObjectInUseProtection":         {},
+	"TaintNodesByCondition":                {},
+	"ValidatingAdmissionWebhook":           {},
+}
+
+// admissionPluginsForVersion returns the sorted names of the admission plugins available in the kubernetes version.
+func admissionPluginsForVersion(version semver.Version) []string {
+	var plugins []string
+	for name, v := range admissionPluginVersions {
+		if v.added != "" && version.LT(semver.MustParse(v.added+".0")) {
+			continue
+		}
+		if v.removed != "" && version.GTE(semver.MustParse(v.removed+".0")) {
+			continue
+		}
+		plugins = append(plugins, name)
+	}
+	sort.Strings(plugins)
+	return plugins
+}
+
+// validateAdmissionPlugins checks that each named admission plugin exists in the cluster's kubernetes version.
+func validateAdmissionPlugins(plugins []string, kubernetesVersion string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(plugins) == 0 {
+		return allErrs
+	}
+
+	sv, err := util.ParseKubernetesVersion(kubernetesVersion)
+	if err != nil {
+		// The kubernetes version is validated elsewhere
+		return allErrs
+	}
+	version := semver.Version{Major: sv.Major, Minor: sv.Minor, Patch: sv.Patch}
+
+	valid := admissionPluginsForVersion(version)
+	for i, entry := range plugins {
+		// Entries are passed through to the flag, so may themselves be comma-separated lists
+		for _, plugin := range strings.Split(entry, ",") {
+			plugin = strings.TrimSpace(plugin)
+			idx := sort.SearchStrings(valid, plugin)
+			if idx < len(valid) && valid[idx] == plugin {
+				continue
+			}
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), plugin,
+				fmt.Sprintf("unknown admission plugin for kubernetes %s, valid plugins are: %s", version, strings.Join(valid, ", "))))
+		}
+	}
+
+	return allErrs
+}
+
+// requiredAdmissionPlugins are the admission plugins kOps relies on, with the reason each one is required.
+var requiredAdmissionPlugins = map[string]string{
+	"NodeRestriction": "it limits the kubelet credentials issued to bootstrapping nodes to their own Node and Pod objects",
+	"ServiceAccount":  "the kOps addons rely on it to mount their service account tokens",
+}
+
+// validateDisableAdmissionPlugins checks that none of the admission plugins required by kOps are disabled.
+func validateDisableAdmissionPlugins(plugins []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, entry := range plugins {
+		for _, plugin := range strings.Split(entry, ",") {
+			plugin = strings.TrimSpace(plugin)
+			if reason, found := requiredAdmissionPlugins[plugin]; found {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Index(i), fmt.Sprintf("admission plugin %s cannot be disabled: %s", plugin, reason)))
+			}
+		}
+	}
+
+	return allErrs
+}
@@ -19,13 +19,16 @@ package validation
 import (
 	"fmt"
 	"net"
+	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model/iam"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 )
@@ -36,6 +39,7 @@ func awsValidateCluster(c *kops.Cluster) field.ErrorList {
 	if c.Spec.API != nil {
 		if c.Spec.API.LoadBalancer != nil {
 			allErrs = append(allErrs, awsValidateAdditionalSecurityGroups(field.NewPath("spec", "api", "loadBalancer", "additionalSecurityGroups"), c.Spec.API.LoadBalancer.AdditionalSecurityGroups)...)
+			allErrs = append(allErrs, awsValidateSSLCertificate(field.NewPath("spec", "api", "loadBalancer", "sslCertificate"), c.Spec.API.LoadBalancer.SSLCertificate)...)
 			allErrs = append(allErrs, awsValidateSSLPolicy(field.NewPath("spec", "api", "loadBalancer", "sslPolicy"), c.Spec.API.LoadBalancer)...)
 			allErrs = append(allErrs, awsValidateLoadBalancerSubnets(field.NewPath("spec", "api", "loadBalancer", "subnets"), c.Spec)...)
 		}
@@ -43,9 +47,33 @@ func awsValidateCluster(c *kops.Cluster) field.ErrorList {
 
 	allErrs = append(allErrs, awsValidateExternalCloudControllerManager(c.Spec)...)
 
+	// The IAM role of the masters is not truncated, unlike e.g. the load balancer names.
+	// Roles with longer prefixes are checked by awsValidateIAMRoleName, for the instance groups that use them.
+	allErrs = append(allErrs, validateClusterNameLength(c.ObjectMeta.Name, "masters.", iam.MaxLengthIAMRoleName, "IAM role of the masters")...)
+
 	return allErrs
 }
 
+// awsIAMRolePrefixes are the prefixes of the IAM role names that are longer than the "masters." prefix every cluster uses.
+var awsIAMRolePrefixes = map[kops.InstanceGroupRole]string{
+	kops.InstanceGroupRoleAPIServer: "apiservers.",
+	kops.InstanceGroupRoleBastion:   "bastions.",
+}
+
+// awsValidateIAMRoleName checks that the IAM role kOps creates for the instance group fits in the maximum length.
+func awsValidateIAMRoleName(ig *kops.InstanceGroup, cluster *kops.Cluster) field.ErrorList {
+	if ig.Spec.IAM != nil && ig.Spec.IAM.Profile != nil {
+		return nil
+	}
+
+	prefix, ok := awsIAMRolePrefixes[ig.Spec.Role]
+	if !ok {
+		return nil
+	}
+
+	return validateClusterNameLength(cluster.ObjectMeta.Name, prefix, iam.MaxLengthIAMRoleName, fmt.Sprintf("IAM role of the %s", strings.TrimSuffix(prefix, ".")))
+}
+
 func awsValidateExternalCloudControllerManager(c kops.ClusterSpec) (allErrs field.ErrorList) {
 
 	if c.ExternalCloudControllerManager != nil {
@@ -86,6 +114,42 @@ func awsValidateInstanceGroup(ig *kops.InstanceGroup, cloud awsup.AWSCloud) fiel
 	return allErrs
 }
 
+// amazonVPCLowMaxPods is the number of pods per node below which AmazonVPC networking is considered very limiting
+const amazonVPCLowMaxPods = 12
+
+// awsAmazonVPCInstanceTypeWarnings returns warnings for instance groups with machine types that support very few pods
+// with AmazonVPC networking. These are not errors, as prefix delegation can raise the limits.
+func awsAmazonVPCInstanceTypeWarnings(c *kops.Cluster, groups []*kops.InstanceGroup, cloud awsup.AWSCloud) []string {
+	if c.Spec.Networking == nil || c.Spec.Networking.AmazonVPC == nil {
+		return nil
+	}
+
+	var warnings []string
+	for _, ig := range groups {
+		var machineTypes []string
+		if ig.Spec.MachineType != "" {
+			// Spotinst uses the instance type field to keep a "," separated list of instance types
+			machineTypes = append(machineTypes, strings.Split(ig.Spec.MachineType, ",")...)
+		}
+		if ig.Spec.MixedInstancesPolicy != nil {
+			machineTypes = append(machineTypes, ig.Spec.MixedInstancesPolicy.Instances...)
+		}
+
+		for _, machineType := range machineTypes {
+			machineInfo, err := awsup.GetMachineTypeInfo(cloud, machineType)
+			if err != nil {
+				// Invalid machine types are reported by awsValidateInstanceTypeAndImage
+				continue
+			}
+			if maxPods := machineInfo.AmazonVPCMaxPods(); maxPods > 0 && maxPods < amazonVPCLowMaxPods {
+				warnings = append(warnings, fmt.Sprintf("instance group %q uses machine type %q, which supports only %d pods per node with AmazonVPC networking; see https://kops.sigs.k8s.io/networking/aws-vpc/", ig.Name, machineType, maxPods))
+			}
+		}
+	}
+
+	return warnings
+}
+
 func awsValidateInstanceMetadata(fieldPath *field.Path, instanceMetadata *kops.InstanceMetadataOptions) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -104,6 +168,11 @@ func awsValidateInstanceMetadata(fieldPath *field.Path, instanceMetadata *kops.I
 	return allErrs
 }
 
+// awsSecurityGroupIDRegexp matches AWS security group ids.
+var awsSecurityGroupIDRegexp = regexp.MustCompile(`^sg-[0-9a-f]+$`)
+
+// awsValidateAdditionalSecurityGroups checks that the additional security groups are unique AWS security group ids.
+// Other clouds are not validated, e.g. OpenStack refers to security groups by name.
 func awsValidateAdditionalSecurityGroups(fieldPath *field.Path, groups []string) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -117,8 +186,8 @@ func awsValidateAdditionalSecurityGroups(fieldPath *field.Path, groups []string)
 			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i), s, "security group cannot be empty, if specified"))
 			continue
 		}
-		if !strings.HasPrefix(s, "sg-") {
-			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i), s, "security group does not match the expected AWS format"))
+		if !awsSecurityGroupIDRegexp.MatchString(s) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i), s, "security group does not match the expected AWS format, e.g. sg-0123456789abcdef0"))
 		}
 	}
 
@@ -219,6 +288,25 @@ func awsValidateMixedInstancesPolicy(path *field.Path, spec *kops.MixedInstances
 	return errs
 }
 
+// awsValidateSSLCertificate checks that the certificate is the ARN of an ACM certificate,
+// or of an IAM server certificate, which classic load balancers also accept.
+func awsValidateSSLCertificate(fieldPath *field.Path, certificate string) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if certificate == "" {
+		return allErrs
+	}
+
+	parsed, err := arn.Parse(certificate)
+	if err != nil || !(parsed.Service == "acm" && strings.HasPrefix(parsed.Resource, "certificate/") ||
+		parsed.Service == "iam" && strings.HasPrefix(parsed.Resource, "server-certificate/")) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, certificate,
+			"SSL certificate must be the ARN of an ACM certificate such as arn:aws:acm:us-east-1:123456789012:certificate/12345678-1234-1234-1234-123456789012"))
+	}
+
+	return allErrs
+}
+
 func awsValidateSSLPolicy(fieldPath *field.Path, spec *kops.LoadBalancerAccessSpec) field.ErrorList {
 	allErrs := field.ErrorList{}
 
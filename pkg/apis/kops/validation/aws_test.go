@@ -17,6 +17,7 @@ limitations under the License.
 package validation
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -103,6 +104,24 @@ func TestValidateInstanceGroupSpec(t *testing.T) {
 			},
 			ExpectedErrors: []string{"Invalid value::spec.additionalSecurityGroups[0]"},
 		},
+		{
+			Input: kops.InstanceGroupSpec{
+				AdditionalSecurityGroups: []string{"sg-0123456789abcdef0"},
+			},
+		},
+		{
+			Input: kops.InstanceGroupSpec{
+				AdditionalSecurityGroups: []string{"sg-", "sg_1234abcd", "SG-1234abcd", "sg-1234abcd ", "sg-1234-abcd", "sg-1234wxyz"},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::spec.additionalSecurityGroups[0]",
+				"Invalid value::spec.additionalSecurityGroups[1]",
+				"Invalid value::spec.additionalSecurityGroups[2]",
+				"Invalid value::spec.additionalSecurityGroups[3]",
+				"Invalid value::spec.additionalSecurityGroups[4]",
+				"Invalid value::spec.additionalSecurityGroups[5]",
+			},
+		},
 		{
 			Input: kops.InstanceGroupSpec{
 				MachineType: "t2.micro",
@@ -540,3 +559,151 @@ func TestLoadBalancerSubnets(t *testing.T) {
 		testErrors(t, test, errs, test.expected)
 	}
 }
+
+func TestAWSValidateSSLCertificate(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          string
+		ExpectedErrors []string
+	}{
+		{
+			Description: "unset",
+		},
+		{
+			Description: "ACM certificate",
+			Input:       "arn:aws:acm:us-east-1:123456789012:certificate/12345678-1234-1234-1234-123456789012",
+		},
+		{
+			Description: "ACM certificate in another partition",
+			Input:       "arn:aws-cn:acm:cn-north-1:123456789012:certificate/12345678-1234-1234-1234-123456789012",
+		},
+		{
+			Description: "IAM server certificate",
+			Input:       "arn:aws:iam::123456789012:server-certificate/api-example-com",
+		},
+		{
+			Description:    "certificate body",
+			Input:          "-----BEGIN CERTIFICATE-----\nMIIB...\n-----END CERTIFICATE-----\n",
+			ExpectedErrors: []string{"Invalid value::spec.api.loadBalancer.sslCertificate"},
+		},
+		{
+			Description:    "certificate id",
+			Input:          "12345678-1234-1234-1234-123456789012",
+			ExpectedErrors: []string{"Invalid value::spec.api.loadBalancer.sslCertificate"},
+		},
+		{
+			Description:    "other ACM resource",
+			Input:          "arn:aws:acm-pca:us-east-1:123456789012:certificate-authority/12345678-1234-1234-1234-123456789012",
+			ExpectedErrors: []string{"Invalid value::spec.api.loadBalancer.sslCertificate"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			cluster := &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					API: &kops.AccessSpec{
+						LoadBalancer: &kops.LoadBalancerAccessSpec{
+							Class:          kops.LoadBalancerClassNetwork,
+							SSLCertificate: g.Input,
+						},
+					},
+				},
+			}
+			errs := awsValidateCluster(cluster)
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func TestAWSAmazonVPCInstanceTypeWarnings(t *testing.T) {
+	// The mock cloud reports a single ENI with a single IP for every machine type, which supports only 2 pods
+	cloud := awsup.BuildMockAWSCloud("us-east-1", "abc")
+
+	groups := []*kops.InstanceGroup{
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "nodes"},
+			Spec:       kops.InstanceGroupSpec{MachineType: "t3.medium"},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "mixed"},
+			Spec: kops.InstanceGroupSpec{
+				MixedInstancesPolicy: &kops.MixedInstancesPolicySpec{Instances: []string{"t3.medium", "t3.large"}},
+			},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "invalid"},
+			Spec:       kops.InstanceGroupSpec{MachineType: "t2.invalidType"},
+		},
+	}
+
+	cluster := &kops.Cluster{}
+	cluster.Spec.Networking = &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{}}
+	if warnings := awsAmazonVPCInstanceTypeWarnings(cluster, groups, cloud); len(warnings) != 0 {
+		t.Errorf("unexpected warnings without AmazonVPC networking: %v", warnings)
+	}
+
+	cluster.Spec.Networking = &kops.NetworkingSpec{AmazonVPC: &kops.AmazonVPCNetworkingSpec{}}
+	warnings := awsAmazonVPCInstanceTypeWarnings(cluster, groups, cloud)
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings with AmazonVPC networking, got %v", warnings)
+	}
+	for i, expected := range []string{`"nodes" uses machine type "t3.medium"`, `"mixed" uses machine type "t3.medium"`, `"mixed" uses machine type "t3.large"`} {
+		if !strings.Contains(warnings[i], expected) {
+			t.Errorf("expected warning %d to contain %q, got %q", i, expected, warnings[i])
+		}
+	}
+}
+
+func TestAWSValidateIAMRoleName(t *testing.T) {
+	grid := []struct {
+		Role           kops.InstanceGroupRole
+		ClusterName    string
+		Profile        *string
+		ExpectedErrors []string
+	}{
+		{
+			Role:        kops.InstanceGroupRoleNode,
+			ClusterName: strings.Repeat("a", 44) + ".example.com",
+		},
+		{
+			Role:        kops.InstanceGroupRoleBastion,
+			ClusterName: strings.Repeat("a", 43) + ".example.com",
+		},
+		{
+			Role:           kops.InstanceGroupRoleBastion,
+			ClusterName:    strings.Repeat("a", 44) + ".example.com",
+			ExpectedErrors: []string{"Invalid value::objectMeta.name"},
+		},
+		{
+			Role:        kops.InstanceGroupRoleBastion,
+			ClusterName: strings.Repeat("a", 44) + ".example.com",
+			Profile:     fi.String("arn:aws:iam::123456789012:instance-profile/bastions"),
+		},
+		{
+			Role:        kops.InstanceGroupRoleAPIServer,
+			ClusterName: strings.Repeat("a", 41) + ".example.com",
+		},
+		{
+			Role:           kops.InstanceGroupRoleAPIServer,
+			ClusterName:    strings.Repeat("a", 42) + ".example.com",
+			ExpectedErrors: []string{"Invalid value::objectMeta.name"},
+		},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{}
+		cluster.ObjectMeta.Name = g.ClusterName
+		ig := &kops.InstanceGroup{
+			Spec: kops.InstanceGroupSpec{
+				Role: g.Role,
+			},
+		}
+		if g.Profile != nil {
+			ig.Spec.IAM = &kops.IAMProfileSpec{Profile: g.Profile}
+		}
+
+		errs := awsValidateIAMRoleName(ig, cluster)
+		testErrors(t, g, errs, g.ExpectedErrors)
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+const (
+	// azureMaxLengthLoadBalancerName is the maximum length of the name of an Azure load balancer
+	azureMaxLengthLoadBalancerName = 80
+	// azureMaxLengthVirtualNetworkName is the maximum length of the name of an Azure virtual network
+	azureMaxLengthVirtualNetworkName = 64
+)
+
+func azureValidateCluster(c *kops.Cluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// The virtual network is named after the cluster, unless an existing one is used
+	if c.Spec.NetworkID == "" {
+		allErrs = append(allErrs, validateClusterNameLength(c.ObjectMeta.Name, "", azureMaxLengthVirtualNetworkName, "virtual network")...)
+	} else {
+		allErrs = append(allErrs, validateClusterNameLength(c.ObjectMeta.Name, "api-", azureMaxLengthLoadBalancerName, "API load balancer")...)
+	}
+
+	return allErrs
+}
@@ -21,6 +21,9 @@ import (
 	"k8s.io/kops/pkg/apis/kops"
 )
 
+// gceMaxLengthName is the maximum length of the name of most GCE resources
+const gceMaxLengthName = 63
+
 func gceValidateCluster(c *kops.Cluster) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -43,5 +46,8 @@ func gceValidateCluster(c *kops.Cluster) field.ErrorList {
 		}
 	}
 
+	// The names of the firewall rules are not truncated, and the NodePort rule has the longest prefix
+	allErrs = append(allErrs, validateClusterNameLength(c.ObjectMeta.Name, "nodeport-external-to-node-", gceMaxLengthName, "NodePort firewall rule")...)
+
 	return allErrs
 }
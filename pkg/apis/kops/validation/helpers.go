@@ -17,6 +17,7 @@ limitations under the License.
 package validation
 
 import (
+	"fmt"
 	"net/url"
 
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -49,3 +50,14 @@ func IsValidValue(fldPath *field.Path, v *string, validValues []string) field.Er
 	}
 	return allErrs
 }
+
+// validateClusterNameLength checks that the name of a cloud resource, which is built by prepending prefix
+// to the cluster name and is not truncated, fits in the maximum length allowed by the cloud provider.
+func validateClusterNameLength(clusterName string, prefix string, maxLength int, resource string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if limit := maxLength - len(prefix); len(clusterName) > limit {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("objectMeta", "name"), clusterName,
+			fmt.Sprintf("Cluster Name must be at most %d characters long, as it is used in the name of the %s, which is limited to %d characters", limit, resource, maxLength)))
+	}
+	return allErrs
+}
@@ -18,6 +18,7 @@ package validation
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"k8s.io/kops/pkg/nodeidentity/aws"
@@ -60,9 +61,14 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud) field.ErrorLis
 		allErrs = append(allErrs, IsValidValue(field.NewPath("spec", "tenancy"), &g.Spec.Tenancy, ec2.Tenancy_Values())...)
 	}
 
-	if g.Spec.MaxSize != nil && g.Spec.MinSize != nil {
-		if *g.Spec.MaxSize < *g.Spec.MinSize {
-			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "maxSize"), "maxSize must be greater than or equal to minSize."))
+	if g.Spec.MinSize != nil && *g.Spec.MinSize < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "minSize"), *g.Spec.MinSize, "minSize cannot be negative"))
+	}
+	if g.Spec.MaxSize != nil {
+		if *g.Spec.MaxSize < 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "maxSize"), *g.Spec.MaxSize, "maxSize cannot be negative"))
+		} else if g.Spec.MinSize != nil && *g.Spec.MaxSize < *g.Spec.MinSize {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "maxSize"), *g.Spec.MaxSize, "maxSize must be greater than or equal to minSize"))
 		}
 	}
 
@@ -88,6 +94,12 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud) field.ErrorLis
 		allErrs = append(allErrs, validateExtraUserData(&UserDataInfo)...)
 	}
 
+	allErrs = append(allErrs, validateSysctlParameters(g.Spec.SysctlParameters, field.NewPath("spec", "sysctlParameters"))...)
+
+	allErrs = append(allErrs, validateKernelModules(g.Spec.KernelModules, field.NewPath("spec", "kernelModules"))...)
+
+	allErrs = append(allErrs, validateMachineTypes(g.Spec.MachineType, field.NewPath("spec", "machineType"))...)
+
 	// @step: iterate and check the volume specs
 	for i, x := range g.Spec.Volumes {
 		devices := make(map[string]bool)
@@ -103,19 +115,7 @@ func ValidateInstanceGroup(g *kops.InstanceGroup, cloud fi.Cloud) field.ErrorLis
 		devices[x.Device] = true
 	}
 
-	// @step: iterate and check the volume mount specs
-	for i, x := range g.Spec.VolumeMounts {
-		used := make(map[string]bool)
-		path := field.NewPath("spec", "volumeMounts").Index(i)
-
-		allErrs = append(allErrs, validateVolumeMountSpec(path, x)...)
-		if _, found := used[x.Device]; found {
-			allErrs = append(allErrs, field.Duplicate(path.Child("device"), x.Device))
-		}
-		if _, found := used[x.Path]; found {
-			allErrs = append(allErrs, field.Duplicate(path.Child("path"), x.Path))
-		}
-	}
+	allErrs = append(allErrs, validateVolumeMounts(g.Spec.VolumeMounts, field.NewPath("spec", "volumeMounts"))...)
 
 	allErrs = append(allErrs, validateInstanceProfile(g.Spec.IAM, field.NewPath("spec", "iam"))...)
 
@@ -160,6 +160,34 @@ func validateVolumeSpec(path *field.Path, v kops.VolumeSpec) field.ErrorList {
 	return allErrs
 }
 
+// validateVolumeMounts is responsible for checking the volume mounts are ok and do not reuse a device or path
+func validateVolumeMounts(mounts []kops.VolumeMountSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	devices := make(map[string]bool)
+	paths := make(map[string]bool)
+	for i, x := range mounts {
+		path := fldPath.Index(i)
+
+		allErrs = append(allErrs, validateVolumeMountSpec(path, x)...)
+
+		if x.Device != "" {
+			if devices[x.Device] {
+				allErrs = append(allErrs, field.Duplicate(path.Child("device"), x.Device))
+			}
+			devices[x.Device] = true
+		}
+		if x.Path != "" {
+			if paths[x.Path] {
+				allErrs = append(allErrs, field.Duplicate(path.Child("path"), x.Path))
+			}
+			paths[x.Path] = true
+		}
+	}
+
+	return allErrs
+}
+
 // validateVolumeMountSpec is responsible for checking the volume mount is ok
 func validateVolumeMountSpec(path *field.Path, spec kops.VolumeMountSpec) field.ErrorList {
 	allErrs := field.ErrorList{}
@@ -172,6 +200,8 @@ func validateVolumeMountSpec(path *field.Path, spec kops.VolumeMountSpec) field.
 	}
 	if spec.Path == "" {
 		allErrs = append(allErrs, field.Required(path.Child("path"), "mount path required"))
+	} else if !strings.HasPrefix(spec.Path, "/") {
+		allErrs = append(allErrs, field.Invalid(path.Child("path"), spec.Path, "mount path must be absolute"))
 	}
 	allErrs = append(allErrs, IsValidValue(path.Child("filesystem"), &spec.Filesystem, kops.SupportedFilesystems)...)
 
@@ -185,12 +215,23 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, cl
 
 	if g.Spec.Role == kops.InstanceGroupRoleMaster {
 		allErrs = append(allErrs, ValidateMasterInstanceGroup(g, cluster)...)
+		allErrs = append(allErrs, validateNodeTerminationHandlerInstanceProfile(g, cluster)...)
 	}
 
 	if g.Spec.Role == kops.InstanceGroupRoleAPIServer && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
 		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "role"), "Apiserver role only supported on AWS"))
 	}
 
+	allErrs = append(allErrs, validateMachineTypeIdentifiers(g.Spec.MachineType, kops.CloudProviderID(cluster.Spec.CloudProvider), field.NewPath("spec", "machineType"))...)
+
+	if g.Spec.Role == kops.InstanceGroupRoleBastion && cluster.Spec.DisableSSHAccess {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "role"), "Bastion role cannot be used when SSH access is disabled"))
+	}
+
+	if fldPath := iptablesBackendNFTPath(&cluster.Spec); fldPath != nil && isLegacyIptablesImage(g.Spec.Image) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "image"), fmt.Sprintf("image %q is for a distribution without nftables support, as required by %s", g.Spec.Image, fldPath)))
+	}
+
 	// Check that instance groups are defined in subnets that are defined in the cluster
 	{
 		clusterSubnets := make(map[string]*kops.ClusterSubnetSpec)
@@ -210,6 +251,7 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, cl
 		if g.Spec.RootVolumeType != nil {
 			allErrs = append(allErrs, IsValidValue(field.NewPath("spec", "rootVolumeType"), g.Spec.RootVolumeType, []string{"standard", "gp3", "gp2", "io1", "io2"})...)
 		}
+		allErrs = append(allErrs, awsValidateIAMRoleName(g, cluster)...)
 	} else {
 		if g.Spec.WarmPool != nil {
 			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "warmPool"), "warm pool only supported on AWS"))
@@ -240,25 +282,61 @@ func CrossValidateInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster, cl
 		if warmPool.MinSize < 0 {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "warmPool", "minSize"), warmPool.MinSize, "warm pool minSize cannot be negative"))
 		}
+		allErrs = append(allErrs, validateLifecycleHookGracePeriod(warmPool, field.NewPath("spec", "warmPool", "lifecycleHookGracePeriod"))...)
+		if g.Spec.WarmPool != nil && g.Spec.WarmPool.EnableLifecycleHook {
+			fldPath := field.NewPath("spec", "warmPool", "enableLifecycleHook")
+			if kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+				allErrs = append(allErrs, field.Forbidden(fldPath, "warm pool lifecycle hook only supported on AWS"))
+			} else if !warmPool.IsEnabled() {
+				allErrs = append(allErrs, field.Forbidden(fldPath, "warm pool lifecycle hook requires the warm pool to be enabled"))
+			}
+		}
 	}
 
 	return allErrs
 }
 
+// ValidateMasterInstanceGroup checks that the master instance group is consistent with the etcd clusters:
+// each etcd cluster must have a member in it, and it must run at least as many instances as it has members
+// of any etcd cluster.
 func ValidateMasterInstanceGroup(g *kops.InstanceGroup, cluster *kops.Cluster) field.ErrorList {
 	allErrs := field.ErrorList{}
 	for _, etcd := range cluster.Spec.EtcdClusters {
-		hasEtcd := false
+		members := 0
 		for _, m := range etcd.Members {
 			if fi.StringValue(m.InstanceGroup) == g.ObjectMeta.Name {
-				hasEtcd = true
-				break
+				members++
 			}
 		}
-		if !hasEtcd {
+		if members == 0 {
 			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "metadata", "name"), fmt.Sprintf("InstanceGroup \"%s\" with role Master must have a member in etcd cluster \"%s\"", g.ObjectMeta.Name, etcd.Name)))
+			continue
 		}
+		// A size of zero pauses the instance group, so it is exempt
+		if g.Spec.MinSize != nil && *g.Spec.MinSize > 0 && int(*g.Spec.MinSize) < members {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "minSize"), *g.Spec.MinSize, fmt.Sprintf("InstanceGroup \"%s\" has %d members of etcd cluster \"%s\" and must have at least as many instances", g.ObjectMeta.Name, members, etcd.Name)))
+		} else if g.Spec.MaxSize != nil && *g.Spec.MaxSize > 0 && int(*g.Spec.MaxSize) < members {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "maxSize"), *g.Spec.MaxSize, fmt.Sprintf("InstanceGroup \"%s\" has %d members of etcd cluster \"%s\" and must have at least as many instances", g.ObjectMeta.Name, members, etcd.Name)))
+		}
+	}
+	return allErrs
+}
+
+// validateNodeTerminationHandlerInstanceProfile checks that a master instance group does not use an external IAM profile
+// when the Node Termination Handler drains nodes from an SQS queue, as the permissions it needs are only added to the roles kOps manages.
+func validateNodeTerminationHandlerInstanceProfile(g *kops.InstanceGroup, cluster *kops.Cluster) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	nth := cluster.Spec.NodeTerminationHandler
+	if nth == nil || !fi.BoolValue(nth.Enabled) || !fi.BoolValue(nth.EnableSQSTerminationDraining) {
+		return allErrs
 	}
+
+	if g.Spec.IAM != nil && g.Spec.IAM.Profile != nil {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "iam", "profile"),
+			"Node Termination Handler queue-processor mode needs autoscaling:CompleteLifecycleAction, autoscaling:DescribeAutoScalingInstances, sqs:DeleteMessage and sqs:ReceiveMessage, which kOps only grants to the IAM roles it manages"))
+	}
+
 	return allErrs
 }
 
@@ -290,6 +368,87 @@ func validateExtraUserData(userData *kops.UserData) field.ErrorList {
 	return allErrs
 }
 
+// validateSysctlParameters checks that each sysctl parameter takes the form variable=value, as in sysctl.conf
+func validateSysctlParameters(params []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, param := range params {
+		tokens := strings.SplitN(param, "=", 2)
+		if len(tokens) != 2 || strings.TrimSpace(tokens[0]) == "" || strings.TrimSpace(tokens[1]) == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), param, "sysctl parameter must take the form variable=value"))
+		}
+	}
+
+	return allErrs
+}
+
+// kernelModuleRegexp matches kernel module names, e.g. ip_vs or nf_conntrack
+var kernelModuleRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// maxKernelModuleNameLength is the longest kernel module name the kernel accepts
+const maxKernelModuleNameLength = 55
+
+// validateKernelModules checks that each kernel module to load is a plausible module name
+func validateKernelModules(modules []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, module := range modules {
+		if !kernelModuleRegexp.MatchString(module) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), module, "kernel module name may only contain letters, digits, '_' and '-'"))
+		} else if len(module) > maxKernelModuleNameLength {
+			allErrs = append(allErrs, field.TooLong(fldPath.Index(i), module, maxKernelModuleNameLength))
+		}
+	}
+
+	return allErrs
+}
+
+// machineTypeRegexp matches the machine type identifiers of AWS, GCE and Azure, e.g. m5.large, n1-standard-2 or Standard_D2s_v3
+var machineTypeRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// validateMachineTypes checks that a comma-separated list of machine types has no empty entries.
+// The first entry is used as the default machine type with AmazonVPC networking, so must not be empty.
+func validateMachineTypes(machineTypes string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if machineTypes == "" {
+		return allErrs
+	}
+
+	for _, machineType := range strings.Split(machineTypes, ",") {
+		if strings.TrimSpace(machineType) == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath, machineTypes, "machine type list must not contain empty entries"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateMachineTypeIdentifiers checks that each entry of a comma-separated list of machine types is a plausible
+// machine type identifier, for the clouds whose identifiers are known to match machineTypeRegexp.
+// Other clouds, such as OpenStack with its operator-defined flavor names, are not checked.
+func validateMachineTypeIdentifiers(machineTypes string, cloudProvider kops.CloudProviderID, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch cloudProvider {
+	case kops.CloudProviderAWS, kops.CloudProviderGCE, kops.CloudProviderAzure:
+	default:
+		return allErrs
+	}
+
+	if machineTypes == "" {
+		return allErrs
+	}
+
+	for _, machineType := range strings.Split(machineTypes, ",") {
+		if strings.TrimSpace(machineType) != "" && !machineTypeRegexp.MatchString(machineType) {
+			allErrs = append(allErrs, field.Invalid(fldPath, machineTypes, fmt.Sprintf("machine type %q is not a valid machine type identifier", machineType)))
+		}
+	}
+
+	return allErrs
+}
+
 // validateInstanceProfile checks the String values for the AuthProfile
 func validateInstanceProfile(v *kops.IAMProfileSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
@@ -17,7 +17,10 @@ limitations under the License.
 package validation
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/kops/pkg/nodeidentity/aws"
 
@@ -182,6 +185,69 @@ func TestValidMasterInstanceGroup(t *testing.T) {
 
 }
 
+func TestValidateNodeTerminationHandlerInstanceProfile(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          kops.NodeTerminationHandlerConfig
+		Profile        *string
+		ExpectedErrors []string
+	}{
+		{
+			Description: "IMDS mode with external profile",
+			Input: kops.NodeTerminationHandlerConfig{
+				Enabled: fi.Bool(true),
+			},
+			Profile: fi.String("arn:aws:iam::123456789012:instance-profile/masters"),
+		},
+		{
+			Description: "queue-processor mode",
+			Input: kops.NodeTerminationHandlerConfig{
+				Enabled:                      fi.Bool(true),
+				EnableSQSTerminationDraining: fi.Bool(true),
+			},
+		},
+		{
+			Description: "queue-processor mode with external profile",
+			Input: kops.NodeTerminationHandlerConfig{
+				Enabled:                      fi.Bool(true),
+				EnableSQSTerminationDraining: fi.Bool(true),
+			},
+			Profile:        fi.String("arn:aws:iam::123456789012:instance-profile/masters"),
+			ExpectedErrors: []string{"Forbidden::spec.iam.profile"},
+		},
+		{
+			Description: "disabled queue-processor mode with external profile",
+			Input: kops.NodeTerminationHandlerConfig{
+				Enabled:                      fi.Bool(false),
+				EnableSQSTerminationDraining: fi.Bool(true),
+			},
+			Profile: fi.String("arn:aws:iam::123456789012:instance-profile/masters"),
+		},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				NodeTerminationHandler: &g.Input,
+			},
+		}
+		ig := &kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "master-us-test-1a",
+			},
+			Spec: kops.InstanceGroupSpec{
+				Role: kops.InstanceGroupRoleMaster,
+			},
+		}
+		if g.Profile != nil {
+			ig.Spec.IAM = &kops.IAMProfileSpec{Profile: g.Profile}
+		}
+
+		errs := validateNodeTerminationHandlerInstanceProfile(ig, cluster)
+		testErrors(t, g.Description, errs, g.ExpectedErrors)
+	}
+}
+
 func TestValidBootDevice(t *testing.T) {
 
 	cluster := &kops.Cluster{
@@ -270,6 +336,397 @@ func TestValidNodeLabels(t *testing.T) {
 	}
 }
 
+func TestValidWarmPoolLifecycleHook(t *testing.T) {
+	var zero int64
+
+	grid := []struct {
+		description   string
+		cloudProvider kops.CloudProviderID
+		warmPool      *kops.WarmPoolSpec
+		expected      []string
+	}{
+		{
+			description:   "enabled on aws",
+			cloudProvider: kops.CloudProviderAWS,
+			warmPool:      &kops.WarmPoolSpec{EnableLifecycleHook: true},
+		},
+		{
+			description:   "disabled warm pool on aws",
+			cloudProvider: kops.CloudProviderAWS,
+			warmPool:      &kops.WarmPoolSpec{MaxSize: &zero, EnableLifecycleHook: true},
+			expected:      []string{"Forbidden::spec.warmPool.enableLifecycleHook"},
+		},
+		{
+			description:   "enabled on gce",
+			cloudProvider: kops.CloudProviderGCE,
+			warmPool:      &kops.WarmPoolSpec{EnableLifecycleHook: true},
+			expected: []string{
+				"Forbidden::spec.warmPool",
+				"Forbidden::spec.warmPool.enableLifecycleHook",
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.description, func(t *testing.T) {
+			cluster := &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					CloudProvider: string(g.cloudProvider),
+				},
+			}
+			ig := &kops.InstanceGroup{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "some-ig",
+				},
+				Spec: kops.InstanceGroupSpec{
+					Role:     kops.InstanceGroupRoleNode,
+					WarmPool: g.warmPool,
+				},
+			}
+			errs := CrossValidateInstanceGroup(ig, cluster, nil)
+			testErrors(t, g.description, errs, g.expected)
+		})
+	}
+}
+
+func TestValidWarmPoolLifecycleHookGracePeriod(t *testing.T) {
+	grid := []struct {
+		description string
+		gracePeriod time.Duration
+		expected    []string
+	}{
+		{
+			description: "zero",
+		},
+		{
+			description: "below heartbeat timeout",
+			gracePeriod: 5 * time.Minute,
+		},
+		{
+			description: "negative",
+			gracePeriod: -time.Second,
+			expected:    []string{"Invalid value::spec.warmPool.lifecycleHookGracePeriod"},
+		},
+		{
+			description: "equal to heartbeat timeout",
+			gracePeriod: kops.WarmPoolLifecycleHookHeartbeatTimeout,
+			expected:    []string{"Invalid value::spec.warmPool.lifecycleHookGracePeriod"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.description, func(t *testing.T) {
+			cluster := &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					CloudProvider: string(kops.CloudProviderAWS),
+				},
+			}
+			ig := &kops.InstanceGroup{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "some-ig",
+				},
+				Spec: kops.InstanceGroupSpec{
+					Role: kops.InstanceGroupRoleNode,
+					WarmPool: &kops.WarmPoolSpec{
+						EnableLifecycleHook:      true,
+						LifecycleHookGracePeriod: &v1.Duration{Duration: g.gracePeriod},
+					},
+				},
+			}
+			errs := CrossValidateInstanceGroup(ig, cluster, nil)
+			testErrors(t, g.description, errs, g.expected)
+		})
+	}
+}
+
+func TestBastionWithSSHAccessDisabled(t *testing.T) {
+	for _, disableSSHAccess := range []bool{false, true} {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				CloudProvider:    string(kops.CloudProviderAWS),
+				DisableSSHAccess: disableSSHAccess,
+			},
+		}
+		ig := &kops.InstanceGroup{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "bastions",
+			},
+			Spec: kops.InstanceGroupSpec{
+				Role: kops.InstanceGroupRoleBastion,
+			},
+		}
+
+		var expected []string
+		if disableSSHAccess {
+			expected = []string{"Forbidden::spec.role"}
+		}
+		errs := CrossValidateInstanceGroup(ig, cluster, nil)
+		testErrors(t, disableSSHAccess, errs, expected)
+	}
+}
+
+func TestIptablesBackendNFTImage(t *testing.T) {
+	grid := []struct {
+		description string
+		networking  *kops.NetworkingSpec
+		image       string
+		expected    []string
+	}{
+		{
+			description: "calico nft on a recent distribution",
+			networking:  &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{IptablesBackend: "NFT"}},
+			image:       "099720109477/ubuntu/images/hvm-ssd/ubuntu-focal-20.04-amd64-server-20210415",
+		},
+		{
+			description: "calico nft on an unrecognised image",
+			networking:  &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{IptablesBackend: "NFT"}},
+			image:       "my-custom-image",
+		},
+		{
+			description: "calico legacy on an old distribution",
+			networking:  &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{IptablesBackend: "Legacy"}},
+			image:       "kope.io/k8s-1.14-debian-stretch-amd64-hvm-ebs-2019-08-16",
+		},
+		{
+			description: "calico nft on an old distribution",
+			networking:  &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{IptablesBackend: "NFT"}},
+			image:       "kope.io/k8s-1.14-debian-stretch-amd64-hvm-ebs-2019-08-16",
+			expected:    []string{"Forbidden::spec.image"},
+		},
+		{
+			description: "canal nft on an old distribution",
+			networking:  &kops.NetworkingSpec{Canal: &kops.CanalNetworkingSpec{IptablesBackend: "NFT"}},
+			image:       "099720109477/ubuntu/images/hvm-ssd/ubuntu-xenial-16.04-amd64-server-20200407",
+			expected:    []string{"Forbidden::spec.image"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.description, func(t *testing.T) {
+			cluster := &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					CloudProvider: string(kops.CloudProviderAWS),
+					Networking:    g.networking,
+				},
+			}
+			ig := &kops.InstanceGroup{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "some-ig",
+				},
+				Spec: kops.InstanceGroupSpec{
+					Role:  kops.InstanceGroupRoleNode,
+					Image: g.image,
+				},
+			}
+			errs := CrossValidateInstanceGroup(ig, cluster, nil)
+			testErrors(t, g.description, errs, g.expected)
+		})
+	}
+}
+
+func TestValidateVolumeMounts(t *testing.T) {
+	grid := []struct {
+		mounts   []kops.VolumeMountSpec
+		expected []string
+	}{
+		{
+			mounts: []kops.VolumeMountSpec{
+				{Device: "/dev/xvdd", Filesystem: "ext4", Path: "/var/lib/docker"},
+				{Device: "/dev/xvde", Filesystem: "xfs", Path: "/data"},
+			},
+		},
+		{
+			mounts: []kops.VolumeMountSpec{
+				{Filesystem: "ext4", Path: "/var/lib/docker"},
+			},
+			expected: []string{"Required value::spec.volumeMounts[0].device"},
+		},
+		{
+			mounts: []kops.VolumeMountSpec{
+				{Device: "/dev/xvdd", Path: "/var/lib/docker"},
+			},
+			expected: []string{
+				"Required value::spec.volumeMounts[0].filesystem",
+				"Unsupported value::spec.volumeMounts[0].filesystem",
+			},
+		},
+		{
+			mounts: []kops.VolumeMountSpec{
+				{Device: "/dev/xvdd", Filesystem: "ext4"},
+			},
+			expected: []string{"Required value::spec.volumeMounts[0].path"},
+		},
+		{
+			mounts: []kops.VolumeMountSpec{
+				{Device: "/dev/xvdd", Filesystem: "ext4", Path: "var/lib/docker"},
+			},
+			expected: []string{"Invalid value::spec.volumeMounts[0].path"},
+		},
+		{
+			mounts: []kops.VolumeMountSpec{
+				{Device: "/dev/xvdd", Filesystem: "ext4", Path: "/var/lib/docker"},
+				{Device: "/dev/xvdd", Filesystem: "ext4", Path: "/data"},
+			},
+			expected: []string{"Duplicate value::spec.volumeMounts[1].device"},
+		},
+		{
+			mounts: []kops.VolumeMountSpec{
+				{Device: "/dev/xvdd", Filesystem: "ext4", Path: "/data"},
+				{Device: "/dev/xvde", Filesystem: "ext4", Path: "/data"},
+			},
+			expected: []string{"Duplicate value::spec.volumeMounts[1].path"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateVolumeMounts(g.mounts, field.NewPath("spec", "volumeMounts"))
+		testErrors(t, g.mounts, errs, g.expected)
+	}
+}
+
+func TestValidateSysctlParameters(t *testing.T) {
+	grid := []struct {
+		params   []string
+		expected []string
+	}{
+		{
+			params: []string{"net.ipv4.ip_forward=1", "kernel.pid_max = 4194304"},
+		},
+		{
+			params:   []string{"net.ipv4.ip_forward"},
+			expected: []string{"Invalid value::spec.sysctlParameters[0]"},
+		},
+		{
+			params:   []string{"net.ipv4.ip_forward=1", "=1"},
+			expected: []string{"Invalid value::spec.sysctlParameters[1]"},
+		},
+		{
+			params:   []string{"net.ipv4.ip_forward= "},
+			expected: []string{"Invalid value::spec.sysctlParameters[0]"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateSysctlParameters(g.params, field.NewPath("spec", "sysctlParameters"))
+		testErrors(t, g.params, errs, g.expected)
+	}
+}
+
+func TestValidateKernelModules(t *testing.T) {
+	grid := []struct {
+		modules  []string
+		expected []string
+	}{
+		{
+			modules: []string{"ip_vs", "nf_conntrack", "ip_vs-rr"},
+		},
+		{
+			modules:  []string{"ip_vs", ""},
+			expected: []string{"Invalid value::spec.kernelModules[1]"},
+		},
+		{
+			modules:  []string{"ip_vs rr"},
+			expected: []string{"Invalid value::spec.kernelModules[0]"},
+		},
+		{
+			modules:  []string{"../ip_vs"},
+			expected: []string{"Invalid value::spec.kernelModules[0]"},
+		},
+		{
+			modules:  []string{strings.Repeat("a", 56)},
+			expected: []string{"Too long::spec.kernelModules[0]"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateKernelModules(g.modules, field.NewPath("spec", "kernelModules"))
+		testErrors(t, g.modules, errs, g.expected)
+	}
+}
+
+func TestValidateMachineTypes(t *testing.T) {
+	grid := []struct {
+		machineType string
+		expected    []string
+	}{
+		{
+			machineType: "",
+		},
+		{
+			machineType: "m5.large",
+		},
+		{
+			machineType: "m5.large,c5.xlarge,n1-standard-2,Standard_D2s_v3",
+		},
+		{
+			machineType: ",m5.large",
+			expected:    []string{"Invalid value::spec.machineType"},
+		},
+		{
+			machineType: "m5.large, ",
+			expected:    []string{"Invalid value::spec.machineType"},
+		},
+		{
+			machineType: "m5.large,,c5.xlarge",
+			expected:    []string{"Invalid value::spec.machineType"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateMachineTypes(g.machineType, field.NewPath("spec", "machineType"))
+		testErrors(t, g.machineType, errs, g.expected)
+	}
+}
+
+func TestValidateMachineTypeIdentifiers(t *testing.T) {
+	grid := []struct {
+		cloudProvider kops.CloudProviderID
+		machineType   string
+		expected      []string
+	}{
+		{
+			cloudProvider: kops.CloudProviderAWS,
+			machineType:   "m5.large,c5.xlarge",
+		},
+		{
+			cloudProvider: kops.CloudProviderGCE,
+			machineType:   "n1-standard-2",
+		},
+		{
+			cloudProvider: kops.CloudProviderAzure,
+			machineType:   "Standard_D2s_v3",
+		},
+		{
+			cloudProvider: kops.CloudProviderAWS,
+			machineType:   "m5.large, c5.xlarge",
+			expected:      []string{"Invalid value::spec.machineType"},
+		},
+		{
+			cloudProvider: kops.CloudProviderGCE,
+			machineType:   "n1-standard-2;n1-standard-4",
+			expected:      []string{"Invalid value::spec.machineType"},
+		},
+		{
+			cloudProvider: kops.CloudProviderAzure,
+			machineType:   "Standard D2s v3",
+			expected:      []string{"Invalid value::spec.machineType"},
+		},
+		{
+			cloudProvider: kops.CloudProviderOpenstack,
+			machineType:   "m1.large (SSD)",
+		},
+		{
+			cloudProvider: kops.CloudProviderOpenstack,
+			machineType:   "Flavor:4vCPU/8GB",
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateMachineTypeIdentifiers(g.machineType, g.cloudProvider, field.NewPath("spec", "machineType"))
+		testErrors(t, g, errs, g.expected)
+	}
+}
+
 func TestValidateIGCloudLabels(t *testing.T) {
 
 	grid := []struct {
@@ -449,3 +906,135 @@ func TestValidInstanceGroup(t *testing.T) {
 		testErrors(t, g.Description, errList, []string{})
 	}
 }
+
+func TestValidateInstanceGroupSize(t *testing.T) {
+	for _, test := range []struct {
+		label    string
+		minSize  *int32
+		maxSize  *int32
+		expected []string
+	}{
+		{
+			label: "unset",
+		},
+		{
+			label:   "equal",
+			minSize: fi.Int32(2),
+			maxSize: fi.Int32(2),
+		},
+		{
+			label:   "zero",
+			minSize: fi.Int32(0),
+			maxSize: fi.Int32(0),
+		},
+		{
+			label:    "max lower than min",
+			minSize:  fi.Int32(3),
+			maxSize:  fi.Int32(2),
+			expected: []string{"Invalid value::spec.maxSize"},
+		},
+		{
+			label:    "negative min",
+			minSize:  fi.Int32(-1),
+			expected: []string{"Invalid value::spec.minSize"},
+		},
+		{
+			label:    "negative max",
+			maxSize:  fi.Int32(-1),
+			expected: []string{"Invalid value::spec.maxSize"},
+		},
+	} {
+		t.Run(test.label, func(t *testing.T) {
+			ig := &kops.InstanceGroup{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "some-ig",
+				},
+				Spec: kops.InstanceGroupSpec{
+					Role:    kops.InstanceGroupRoleNode,
+					MinSize: test.minSize,
+					MaxSize: test.maxSize,
+				},
+			}
+			errs := ValidateInstanceGroup(ig, nil)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
+
+func TestValidateMasterInstanceGroupSize(t *testing.T) {
+	for _, test := range []struct {
+		label    string
+		members  []string
+		minSize  *int32
+		maxSize  *int32
+		expected []string
+	}{
+		{
+			label:   "one member",
+			members: []string{"master-a", "master-b", "master-c"},
+			minSize: fi.Int32(1),
+			maxSize: fi.Int32(1),
+		},
+		{
+			label:   "all members in one instance group",
+			members: []string{"master-a", "master-a", "master-a"},
+			minSize: fi.Int32(3),
+			maxSize: fi.Int32(3),
+		},
+		{
+			label:    "fewer instances than members",
+			members:  []string{"master-a", "master-a", "master-a"},
+			minSize:  fi.Int32(1),
+			maxSize:  fi.Int32(3),
+			expected: []string{"Invalid value::spec.minSize"},
+		},
+		{
+			label:    "max fewer than members",
+			members:  []string{"master-a", "master-a", "master-a"},
+			maxSize:  fi.Int32(2),
+			expected: []string{"Invalid value::spec.maxSize"},
+		},
+		{
+			label:   "paused",
+			members: []string{"master-a", "master-a", "master-a"},
+			minSize: fi.Int32(0),
+			maxSize: fi.Int32(0),
+		},
+		{
+			label:   "paused with one member",
+			members: []string{"master-a", "master-b", "master-c"},
+			minSize: fi.Int32(0),
+			maxSize: fi.Int32(0),
+		},
+	} {
+		t.Run(test.label, func(t *testing.T) {
+			cluster := &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					EtcdClusters: []kops.EtcdClusterSpec{
+						{
+							Name: "main",
+						},
+					},
+				},
+			}
+			for i, ig := range test.members {
+				cluster.Spec.EtcdClusters[0].Members = append(cluster.Spec.EtcdClusters[0].Members, kops.EtcdMemberSpec{
+					Name:          fmt.Sprintf("member-%d", i),
+					InstanceGroup: fi.String(ig),
+				})
+			}
+			ig := &kops.InstanceGroup{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "master-a",
+				},
+				Spec: kops.InstanceGroupSpec{
+					Role:    kops.InstanceGroupRoleMaster,
+					MinSize: test.minSize,
+					MaxSize: test.maxSize,
+				},
+			}
+			errs := ValidateMasterInstanceGroup(ig, cluster)
+			testErrors(t, test.label, errs, test.expected)
+		})
+	}
+}
@@ -22,12 +22,13 @@ import (
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/apis/kops/util"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/util/subnet"
 	"k8s.io/kops/upup/pkg/fi"
-	"k8s.io/kops/util/pkg/vfs"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 )
 
 // legacy contains validation functions that don't match the apimachinery style
@@ -373,29 +374,6 @@ func ValidateCluster(c *kops.Cluster, strict bool) field.ErrorList {
 		}
 	}
 
-	said := c.Spec.ServiceAccountIssuerDiscovery
-	if said != nil && said.DiscoveryStore != "" {
-		saidStore := said.DiscoveryStore
-		saidStoreField := fieldSpec.Child("serviceAccountIssuerDiscovery", "discoveryStore")
-		base, err := vfs.Context.BuildVfsPath(saidStore)
-		if err != nil {
-			allErrs = append(allErrs, field.Invalid(saidStoreField, saidStore, "not a valid VFS path"))
-		} else {
-			switch base := base.(type) {
-			case *vfs.S3Path:
-				// OK
-			case *vfs.MemFSPath:
-				// memfs is ok for tests; not OK otherwise
-				if !base.IsClusterReadable() {
-					// (If this _is_ a test, we should call MarkClusterReadable)
-					allErrs = append(allErrs, field.Invalid(saidStoreField, saidStore, "S3 is the only supported VFS for discoveryStore"))
-				}
-			default:
-				allErrs = append(allErrs, field.Invalid(saidStoreField, saidStore, "S3 is the only supported VFS for discoveryStore"))
-			}
-		}
-	}
-
 	return allErrs
 }
 
@@ -442,6 +420,10 @@ func DeepValidate(c *kops.Cluster, groups []*kops.InstanceGroup, strict bool, cl
 		return fmt.Errorf("must configure at least one Node InstanceGroup")
 	}
 
+	if errs := validateBastionInstanceGroupExists(c, groups); len(errs) != 0 {
+		return errs.ToAggregate()
+	}
+
 	for _, g := range groups {
 		errs := CrossValidateInstanceGroup(g, c, cloud)
 
@@ -455,9 +437,46 @@ func DeepValidate(c *kops.Cluster, groups []*kops.InstanceGroup, strict bool, cl
 		}
 	}
 
+	for _, warning := range DeepValidateWarnings(c, groups, cloud) {
+		klog.Warning(warning)
+	}
+
 	return nil
 }
 
+// DeepValidateWarnings returns the warnings which DeepValidate logs without failing,
+// about settings which are valid but likely to cause problems.
+func DeepValidateWarnings(c *kops.Cluster, groups []*kops.InstanceGroup, cloud fi.Cloud) []string {
+	var warnings []string
+	warnings = append(warnings, rollingUpdateMasterSurgeWarnings(c, groups)...)
+	warnings = append(warnings, iptablesBackendNFTWarnings(c)...)
+	warnings = append(warnings, etcdVolumeSizeWarnings(c)...)
+	warnings = append(warnings, hubbleMetricsWarnings(c)...)
+	warnings = append(warnings, packageHashWarnings(c)...)
+	if awsCloud, ok := cloud.(awsup.AWSCloud); ok {
+		warnings = append(warnings, awsAmazonVPCInstanceTypeWarnings(c, groups, awsCloud)...)
+	}
+	return warnings
+}
+
+// validateBastionInstanceGroupExists checks that a bastion instance group exists when the topology configures a bastion.
+func validateBastionInstanceGroupExists(c *kops.Cluster, groups []*kops.InstanceGroup) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.Spec.Topology == nil || c.Spec.Topology.Bastion == nil {
+		return allErrs
+	}
+
+	for _, g := range groups {
+		if g.Spec.Role == kops.InstanceGroupRoleBastion {
+			return allErrs
+		}
+	}
+
+	allErrs = append(allErrs, field.Required(field.NewPath("spec", "topology", "bastion"), "a bastion InstanceGroup must be configured when the topology configures a bastion"))
+	return allErrs
+}
+
 func isExperimentalClusterDNS(k *kops.KubeletConfigSpec, dns *kops.KubeDNSConfig) bool {
 
 	return k != nil && k.ClusterDNS != dns.ServerIP && dns.NodeLocalDNS != nil && k.ClusterDNS != dns.NodeLocalDNS.LocalIP
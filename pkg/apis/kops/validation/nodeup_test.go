@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kops/pkg/apis/nodeup"
+)
+
+func TestValidateConfigServerOptions(t *testing.T) {
+	grid := []struct {
+		Input          nodeup.ConfigServerOptions
+		ExpectedErrors []string
+	}{
+		{
+			Input: nodeup.ConfigServerOptions{
+				Server: "https://kops-controller.internal.example.com:3988/",
+			},
+		},
+		{
+			Input: nodeup.ConfigServerOptions{
+				Server:         "https://kops-controller.internal.example.com:3988/",
+				ClientCertFile: "/etc/kubernetes/kops-controller/client.crt",
+				ClientKeyFile:  "/etc/kubernetes/kops-controller/client.key",
+			},
+		},
+		{
+			Input: nodeup.ConfigServerOptions{
+				Server:         "https://kops-controller.internal.example.com:3988/",
+				ClientCertFile: "/etc/kubernetes/kops-controller/client.crt",
+			},
+			ExpectedErrors: []string{"Forbidden::configServer"},
+		},
+		{
+			Input: nodeup.ConfigServerOptions{
+				Server:        "https://kops-controller.internal.example.com:3988/",
+				ClientKeyFile: "/etc/kubernetes/kops-controller/client.key",
+			},
+			ExpectedErrors: []string{"Forbidden::configServer"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := ValidateConfigServerOptions(&g.Input, field.NewPath("configServer"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
@@ -17,11 +17,14 @@ limitations under the License.
 package validation
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
 	"net/url"
+	"path"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
@@ -35,11 +38,15 @@ import (
 	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
 	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/pkg/model/components"
 	"k8s.io/kops/pkg/model/iam"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/wellknownports"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/utils"
+	"k8s.io/kops/util/pkg/vfs"
 )
 
 func newValidateCluster(cluster *kops.Cluster) field.ErrorList {
@@ -67,6 +74,8 @@ func newValidateCluster(cluster *kops.Cluster) field.ErrorList {
 	switch kops.CloudProviderID(cluster.Spec.CloudProvider) {
 	case kops.CloudProviderAWS:
 		allErrs = append(allErrs, awsValidateCluster(cluster)...)
+	case kops.CloudProviderAzure:
+		allErrs = append(allErrs, azureValidateCluster(cluster)...)
 	case kops.CloudProviderGCE:
 		allErrs = append(allErrs, gceValidateCluster(cluster)...)
 	case kops.CloudProviderOpenstack:
@@ -81,11 +90,23 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 
 	allErrs = append(allErrs, validateSubnets(spec, fieldPath.Child("subnets"))...)
 
+	if spec.Channel != "" {
+		allErrs = append(allErrs, validateChannel(spec.Channel, fieldPath.Child("channel"))...)
+	}
+
+	if spec.CAKeyType != "" {
+		allErrs = append(allErrs, IsValidValue(fieldPath.Child("caKeyType"), &spec.CAKeyType, pki.SupportedPrivateKeyTypes)...)
+	}
+
 	// SSHAccess
 	for i, cidr := range spec.SSHAccess {
 		allErrs = append(allErrs, validateCIDR(cidr, fieldPath.Child("sshAccess").Index(i))...)
 	}
 
+	if spec.DisableSSHAccess {
+		allErrs = append(allErrs, validateDisableSSHAccess(spec, fieldPath)...)
+	}
+
 	// KubernetesAPIAccess
 	for i, cidr := range spec.KubernetesAPIAccess {
 		allErrs = append(allErrs, validateCIDR(cidr, fieldPath.Child("kubernetesAPIAccess").Index(i))...)
@@ -102,7 +123,11 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 	}
 
 	if spec.Topology != nil {
-		allErrs = append(allErrs, validateTopology(spec.Topology, fieldPath.Child("topology"))...)
+		allErrs = append(allErrs, validateTopology(spec, spec.Topology, fieldPath.Child("topology"))...)
+
+		if spec.Topology.Nodes == kops.TopologyPrivate && kops.CloudProviderID(spec.CloudProvider) == kops.CloudProviderAWS {
+			allErrs = append(allErrs, validatePrivateSubnetEgress(spec.Subnets, fieldPath.Child("subnets"))...)
+		}
 	}
 
 	// UpdatePolicy
@@ -119,14 +144,22 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 		}
 	}
 
+	allErrs = append(allErrs, validateKernelModules(spec.KernelModules, fieldPath.Child("kernelModules"))...)
+
 	if spec.KubeAPIServer != nil {
 		allErrs = append(allErrs, validateKubeAPIServer(spec.KubeAPIServer, c, fieldPath.Child("kubeAPIServer"))...)
 	}
 
+	if spec.KopsControllerPort != 0 {
+		allErrs = append(allErrs, validateKopsControllerPort(spec, fieldPath.Child("kopsControllerPort"))...)
+	}
+
 	if spec.ExternalCloudControllerManager != nil {
 		if kops.CloudProviderID(spec.CloudProvider) != kops.CloudProviderOpenstack && !featureflag.EnableExternalCloudController.Enabled() {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("cloudControllerManager"), "external cloud controller manager is an experimental feature; set `export KOPS_FEATURE_FLAGS=EnableExternalCloudController`"))
 		}
+		allErrs = append(allErrs, validateExternalCloudControllerKubelet(spec.Kubelet, fieldPath.Child("kubelet"))...)
+		allErrs = append(allErrs, validateExternalCloudControllerKubelet(spec.MasterKubelet, fieldPath.Child("masterKubelet"))...)
 	}
 
 	if spec.KubeProxy != nil {
@@ -141,6 +174,10 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 		allErrs = append(allErrs, validateKubelet(spec.MasterKubelet, c, fieldPath.Child("masterKubelet"))...)
 	}
 
+	if spec.Kubelet != nil && spec.MasterKubelet != nil {
+		allErrs = append(allErrs, validateMasterKubeletMatchesKubelet(spec.Kubelet, spec.MasterKubelet, fieldPath.Child("masterKubelet"))...)
+	}
+
 	if spec.Networking != nil {
 		allErrs = append(allErrs, validateNetworking(c, spec.Networking, fieldPath.Child("networking"))...)
 		if spec.Networking.Calico != nil {
@@ -198,8 +235,9 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 				allErrs = append(allErrs, validateEtcdClusterSpec(etcdCluster, c, fieldEtcdClusters.Index(i))...)
 			}
 			allErrs = append(allErrs, validateEtcdBackupStore(spec.EtcdClusters, fieldEtcdClusters)...)
+			allErrs = append(allErrs, validateEtcdMemberTopology(spec.EtcdClusters, fieldEtcdClusters)...)
 			allErrs = append(allErrs, validateEtcdTLS(spec.EtcdClusters, fieldEtcdClusters)...)
-			allErrs = append(allErrs, validateEtcdStorage(spec.EtcdClusters, fieldEtcdClusters)...)
+			allErrs = append(allErrs, validateEtcdStorage(spec, fieldEtcdClusters)...)
 		}
 	}
 
@@ -219,6 +257,34 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 		if spec.Assets.ContainerProxy != nil && spec.Assets.ContainerRegistry != nil {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("assets", "containerProxy"), "containerProxy cannot be used in conjunction with containerRegistry"))
 		}
+		if spec.Assets.ContainerRegistry != nil {
+			allErrs = append(allErrs, validateContainerRegistry(strings.TrimSuffix(*spec.Assets.ContainerRegistry, "/"), fieldPath.Child("assets", "containerRegistry"))...)
+		}
+		if spec.Assets.ContainerProxy != nil {
+			allErrs = append(allErrs, validateContainerRegistry(strings.TrimSuffix(*spec.Assets.ContainerProxy, "/"), fieldPath.Child("assets", "containerProxy"))...)
+		}
+		if len(spec.Assets.ContainerRegistryMirrors) > 0 {
+			if spec.Assets.ContainerProxy != nil {
+				allErrs = append(allErrs, field.Forbidden(fieldPath.Child("assets", "containerProxy"), "containerProxy cannot be used in conjunction with containerRegistryMirrors"))
+			}
+			allErrs = append(allErrs, validateContainerRegistryMirrors(spec.Assets.ContainerRegistryMirrors, fieldPath.Child("assets", "containerRegistryMirrors"))...)
+		}
+	}
+
+	if spec.NodeUp != nil {
+		allErrs = append(allErrs, validateNodeUpSpec(spec.NodeUp, fieldPath.Child("nodeUp"))...)
+	}
+
+	if spec.Protokube != nil {
+		allErrs = append(allErrs, validateProtokubeSpec(spec.Protokube, fieldPath.Child("protokube"))...)
+	}
+
+	if spec.Channels != nil {
+		allErrs = append(allErrs, validateChannelsSpec(spec.Channels, fieldPath.Child("channels"))...)
+	}
+
+	for role, images := range spec.PreloadImages {
+		allErrs = append(allErrs, validatePreloadImages(role, images, fieldPath.Child("preloadImages"))...)
 	}
 
 	if spec.IAM == nil || spec.IAM.Legacy {
@@ -259,6 +325,53 @@ func validateClusterSpec(spec *kops.ClusterSpec, c *kops.Cluster, fieldPath *fie
 			}
 			allErrs = append(allErrs, validateSAExternalPermissions(spec.IAM.ServiceAccountExternalPermissions, fieldPath.Child("iam", "serviceAccountExternalPermissions"))...)
 		}
+		if spec.IAM.KopsControllerAdditionalPolicy != "" {
+			allErrs = append(allErrs, validatePolicyStatements(spec.IAM.KopsControllerAdditionalPolicy, fieldPath.Child("iam", "kopsControllerAdditionalPolicy"))...)
+		}
+	}
+
+	allErrs = append(allErrs, validateServiceAccountIssuerDiscovery(spec, fieldPath)...)
+
+	return allErrs
+}
+
+// validateServiceAccountIssuerDiscovery checks that the discovery store is an S3 path,
+// and that the ServiceAccount issuer, if set, is an HTTPS URL as OIDC discovery requires.
+func validateServiceAccountIssuerDiscovery(spec *kops.ClusterSpec, fieldPath *field.Path) (allErrs field.ErrorList) {
+	said := spec.ServiceAccountIssuerDiscovery
+	if said != nil {
+		saidStoreField := fieldPath.Child("serviceAccountIssuerDiscovery", "discoveryStore")
+		if said.DiscoveryStore == "" {
+			if said.EnableAWSOIDCProvider {
+				allErrs = append(allErrs, field.Required(saidStoreField, "discoveryStore is required when enableAWSOIDCProvider is set"))
+			}
+		} else {
+			base, err := vfs.Context.BuildVfsPath(said.DiscoveryStore)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(saidStoreField, said.DiscoveryStore, "not a valid VFS path"))
+			} else {
+				switch base := base.(type) {
+				case *vfs.S3Path:
+					// OK
+				case *vfs.MemFSPath:
+					// memfs is ok for tests; not OK otherwise
+					if !base.IsClusterReadable() {
+						// (If this _is_ a test, we should call MarkClusterReadable)
+						allErrs = append(allErrs, field.Invalid(saidStoreField, said.DiscoveryStore, "S3 is the only supported VFS for discoveryStore"))
+					}
+				default:
+					allErrs = append(allErrs, field.Invalid(saidStoreField, said.DiscoveryStore, "S3 is the only supported VFS for discoveryStore"))
+				}
+			}
+		}
+	}
+
+	if spec.KubeAPIServer != nil && spec.KubeAPIServer.ServiceAccountIssuer != nil {
+		issuer := *spec.KubeAPIServer.ServiceAccountIssuer
+		u, err := url.Parse(issuer)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("kubeAPIServer", "serviceAccountIssuer"), issuer, "serviceAccountIssuer must be an https URL"))
+		}
 	}
 
 	return allErrs
@@ -349,7 +462,7 @@ func validateIPv6CIDR(cidr string, fieldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
-func validateTopology(topology *kops.TopologySpec, fieldPath *field.Path) field.ErrorList {
+func validateTopology(c *kops.ClusterSpec, topology *kops.TopologySpec, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	if topology.Masters == "" {
@@ -369,6 +482,17 @@ func validateTopology(topology *kops.TopologySpec, fieldPath *field.Path) field.
 		if topology.Masters == kops.TopologyPublic || topology.Nodes == kops.TopologyPublic {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("bastion"), "bastion requires masters and nodes to have private topology"))
 		}
+		// The bastion is reached through a load balancer in the utility subnets
+		hasUtilitySubnet := false
+		for _, subnet := range c.Subnets {
+			if subnet.Type == kops.SubnetTypeUtility {
+				hasUtilitySubnet = true
+				break
+			}
+		}
+		if !hasUtilitySubnet {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("bastion"), "bastion requires a utility subnet to provide a public ingress path"))
+		}
 		if bastion.IdleTimeoutSeconds != nil && *bastion.IdleTimeoutSeconds <= 0 {
 			allErrs = append(allErrs, field.Invalid(fieldPath.Child("bastion", "idleTimeoutSeconds"), *bastion.IdleTimeoutSeconds, "bastion idleTimeoutSeconds should be greater than zero"))
 		}
@@ -385,6 +509,46 @@ func validateTopology(topology *kops.TopologySpec, fieldPath *field.Path) field.
 	return allErrs
 }
 
+// validatePrivateSubnetEgress checks that each private subnet has a path to the internet, e.g. for nodes to pull images.
+// Unless egress is set, kOps creates a NAT gateway for the subnet in the utility subnet in the same zone.
+func validatePrivateSubnetEgress(subnets []kops.ClusterSubnetSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	utilityZones := sets.NewString()
+	for _, subnet := range subnets {
+		if subnet.Type == kops.SubnetTypeUtility {
+			utilityZones.Insert(subnet.Zone)
+		}
+	}
+
+	for i, subnet := range subnets {
+		if subnet.Type != kops.SubnetTypePrivate || subnet.Egress != "" || utilityZones.Has(subnet.Zone) {
+			continue
+		}
+		allErrs = append(allErrs, field.Required(fieldPath.Index(i).Child("egress"),
+			fmt.Sprintf("private subnet %q has no egress path: set egress to a NAT gateway, NAT instance, transit gateway or External, or add a utility subnet in zone %q for kOps to create a NAT gateway in", subnet.Name, subnet.Zone)))
+	}
+
+	return allErrs
+}
+
+// validateDisableSSHAccess checks that nothing requires SSH access when it is disabled
+func validateDisableSSHAccess(spec *kops.ClusterSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if kops.CloudProviderID(spec.CloudProvider) != kops.CloudProviderAWS {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("disableSSHAccess"), "disabling SSH access is only supported on AWS"))
+	}
+	if spec.SSHKeyName != nil && *spec.SSHKeyName != "" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("sshKeyName"), "an SSH key cannot be used when SSH access is disabled"))
+	}
+	if spec.Topology != nil && spec.Topology.Bastion != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("topology", "bastion"), "a bastion cannot be used when SSH access is disabled"))
+	}
+
+	return allErrs
+}
+
 func validateSubnets(cluster *kops.ClusterSpec, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -473,6 +637,14 @@ func validateFileAssetSpec(v *kops.FileAssetSpec, fieldPath *field.Path) field.E
 	if v.Content == "" {
 		allErrs = append(allErrs, field.Required(fieldPath.Child("content"), ""))
 	}
+	if v.Path != "" && !path.IsAbs(v.Path) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("path"), v.Path, "path must be absolute"))
+	}
+	if v.Mode != "" {
+		if _, err := fi.ParseFileMode(v.Mode, 0); err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("mode"), v.Mode, "mode must be an octal file mode (e.g. 0440)"))
+		}
+	}
 
 	return allErrs
 }
@@ -507,6 +679,40 @@ func validateHookSpec(v *kops.HookSpec, fieldPath *field.Path) field.ErrorList {
 
 	if v.ExecContainer != nil {
 		allErrs = append(allErrs, validateExecContainerAction(v.ExecContainer, fieldPath.Child("execContainer"))...)
+	} else if v.Manifest != "" && !v.UseRawManifest {
+		allErrs = append(allErrs, validateHookServiceManifest(v.Manifest, fieldPath.Child("manifest"))...)
+	}
+
+	return allErrs
+}
+
+// validateHookServiceManifest checks that a manifest which is not a raw manifest looks like the contents of the [Service]
+// section of a systemd unit, being directives of the form Key=Value along with blank lines, comments and continuation lines.
+// kOps generates the [Unit] and [Service] section headers, so those headers indicate a complete unit; other sections,
+// such as [Install], may follow the service directives.
+func validateHookServiceManifest(manifest string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	continuation := false
+	for i, line := range strings.Split(manifest, "\n") {
+		line = strings.TrimSpace(line)
+		isContinuation := continuation
+		continuation = strings.HasSuffix(line, "\\")
+		if isContinuation || line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if line == "[Unit]" || line == "[Service]" {
+				allErrs = append(allErrs, field.Invalid(fldPath, line, fmt.Sprintf("line %d is a %s section header, but kOps generates that section and uses the manifest as the [Service] section; set useRawManifest to use a complete systemd unit", i+1, line)))
+				break
+			}
+			continue
+		}
+		if key := strings.SplitN(line, "=", 2)[0]; !strings.Contains(line, "=") || key == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath, line, fmt.Sprintf("line %d is not a systemd directive of the form Key=Value", i+1)))
+			break
+		}
 	}
 
 	return allErrs
@@ -532,6 +738,11 @@ func validateKubeAPIServer(v *kops.KubeAPIServerConfig, c *kops.Cluster, fldPath
 		}
 	}
 
+	allErrs = append(allErrs, validateAdmissionPlugins(v.AdmissionControl, c.Spec.KubernetesVersion, fldPath.Child("admissionControl"))...)
+	allErrs = append(allErrs, validateAdmissionPlugins(v.DisableAdmissionPlugins, c.Spec.KubernetesVersion, fldPath.Child("disableAdmissionPlugins"))...)
+	allErrs = append(allErrs, validateDisableAdmissionPlugins(v.DisableAdmissionPlugins, fldPath.Child("disableAdmissionPlugins"))...)
+	allErrs = append(allErrs, validateKubeAPIServerOIDC(v, fldPath)...)
+
 	proxyClientCertIsNil := v.ProxyClientCertFile == nil
 	proxyClientKeyIsNil := v.ProxyClientKeyFile == nil
 
@@ -539,6 +750,14 @@ func validateKubeAPIServer(v *kops.KubeAPIServerConfig, c *kops.Cluster, fldPath
 		allErrs = append(allErrs, field.Forbidden(fldPath, "proxyClientCertFile and proxyClientKeyFile must both be specified (or neither)"))
 	}
 
+	if v.ProxyClientCertFile != nil && !path.IsAbs(*v.ProxyClientCertFile) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("proxyClientCertFile"), *v.ProxyClientCertFile, "proxyClientCertFile must be an absolute path"))
+	}
+
+	if v.ProxyClientKeyFile != nil && !path.IsAbs(*v.ProxyClientKeyFile) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("proxyClientKeyFile"), *v.ProxyClientKeyFile, "proxyClientKeyFile must be an absolute path"))
+	}
+
 	if v.ServiceNodePortRange != "" {
 		pr := &utilnet.PortRange{}
 		err := pr.Set(v.ServiceNodePortRange)
@@ -567,9 +786,9 @@ func validateKubeAPIServer(v *kops.KubeAPIServerConfig, c *kops.Cluster, fldPath
 					allErrs = append(allErrs, IsValidValue(fldPath.Child("authorizationMode"), &mode, []string{"ABAC", "Webhook", "Node", "RBAC", "AlwaysAllow", "AlwaysDeny"})...)
 				}
 			}
-			if kops.CloudProviderID(c.Spec.CloudProvider) == kops.CloudProviderAWS && c.IsKubernetesGTE("1.19") {
+			if c.IsKubernetesGTE("1.19") {
 				if !hasNode || !hasRBAC {
-					allErrs = append(allErrs, field.Required(fldPath.Child("authorizationMode"), "As of kubernetes 1.19 on AWS, authorizationMode must include RBAC and Node"))
+					allErrs = append(allErrs, field.Required(fldPath.Child("authorizationMode"), "As of kubernetes 1.19, authorizationMode must include RBAC and Node"))
 				}
 			}
 		}
@@ -577,19 +796,285 @@ func validateKubeAPIServer(v *kops.KubeAPIServerConfig, c *kops.Cluster, fldPath
 	return allErrs
 }
 
+// oidcClaimRegexp matches claim names, including namespaced claims such as https://example.com/groups
+var oidcClaimRegexp = regexp.MustCompile(`^[A-Za-z0-9_.:/-]+$`)
+
+// validateKubeAPIServerOIDC checks that, when any of the OIDC flags is set, the issuer URL is an https URL
+// and the client ID is set, as the apiserver refuses to start otherwise.
+func validateKubeAPIServerOIDC(v *kops.KubeAPIServerConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if v.OIDCUsernameClaim == nil && v.OIDCUsernamePrefix == nil && v.OIDCGroupsClaim == nil && v.OIDCGroupsPrefix == nil &&
+		v.OIDCIssuerURL == nil && v.OIDCClientID == nil && len(v.OIDCRequiredClaim) == 0 && v.OIDCCAFile == nil {
+		return allErrs
+	}
+
+	if issuer := fi.StringValue(v.OIDCIssuerURL); issuer == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("oidcIssuerURL"), "oidcIssuerURL is required when using OIDC"))
+	} else {
+		u, err := url.Parse(issuer)
+		if err != nil || u.Scheme != "https" || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("oidcIssuerURL"), issuer, "oidcIssuerURL must be an https URL without query or fragment"))
+		}
+	}
+
+	if fi.StringValue(v.OIDCClientID) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("oidcClientID"), "oidcClientID is required when using OIDC"))
+	}
+
+	if v.OIDCUsernameClaim != nil && !oidcClaimRegexp.MatchString(*v.OIDCUsernameClaim) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("oidcUsernameClaim"), *v.OIDCUsernameClaim, "oidcUsernameClaim must be a claim name"))
+	}
+	if v.OIDCGroupsClaim != nil && !oidcClaimRegexp.MatchString(*v.OIDCGroupsClaim) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("oidcGroupsClaim"), *v.OIDCGroupsClaim, "oidcGroupsClaim must be a claim name"))
+	}
+
+	return allErrs
+}
+
+// etcdPorts are the ports used by the etcd clusters and etcd-manager on the control plane nodes.
+var etcdPorts = map[int]string{
+	2380:                          "etcd main peer",
+	2381:                          "etcd events peer",
+	2382:                          "etcd cilium peer",
+	4001:                          "etcd main client",
+	4002:                          "etcd events client",
+	4003:                          "etcd cilium client",
+	wellknownports.EtcdCiliumGRPC: "etcd-manager cilium grpc",
+	wellknownports.EtcdCiliumQuarantinedClientPort: "etcd cilium quarantined client",
+	wellknownports.EtcdMainQuarantinedClientPort:   "etcd main quarantined client",
+	wellknownports.EtcdEventsQuarantinedClientPort: "etcd events quarantined client",
+	wellknownports.EtcdMainGRPC:                    "etcd-manager main grpc",
+	wellknownports.EtcdEventsGRPC:                  "etcd-manager events grpc",
+}
+
+// validatePackageHash checks that a package hash is hex-encoded and no longer than a SHA-256 hash, unless it is a SHA-512 hash.
+// Hashes shorter than a SHA-256 hash are accepted, as they were before SHA-512 support; packageHashWarnings reports them.
+func validatePackageHash(hash *string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	h := fi.StringValue(hash)
+	if len(h) > 64 && len(h) != 128 {
+		allErrs = append(allErrs, field.Invalid(fldPath, hash, "Package hash must be 64 (SHA-256) or 128 (SHA-512) characters long"))
+	} else if _, err := hex.DecodeString(h); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, hash, "Package hash must be hex-encoded"))
+	}
+
+	return allErrs
+}
+
+// packageHashWarnings returns warnings for containerd and docker package hashes shorter than a SHA-256 hash.
+func packageHashWarnings(c *kops.Cluster) []string {
+	var warnings []string
+	check := func(fldPath *field.Path, packages *kops.PackagesConfig) {
+		if packages == nil {
+			return
+		}
+		for _, p := range []struct {
+			field string
+			hash  *string
+		}{
+			{field: "hashAmd64", hash: packages.HashAmd64},
+			{field: "hashArm64", hash: packages.HashArm64},
+		} {
+			if p.hash != nil && len(*p.hash) < 64 {
+				warnings = append(warnings, fmt.Sprintf("%s is %d characters long; use a 64 character SHA-256 or 128 character SHA-512 hash", fldPath.Child(p.field), len(*p.hash)))
+			}
+		}
+	}
+	if c.Spec.Containerd != nil {
+		check(field.NewPath("spec", "containerd", "packages"), c.Spec.Containerd.Packages)
+	}
+	if c.Spec.Docker != nil {
+		check(field.NewPath("spec", "docker", "packages"), c.Spec.Docker.Packages)
+	}
+	return warnings
+}
+
+// validateNodeUpSpec checks that each nodeup URL override is an absolute URL with a SHA-256 hash.
+func validateNodeUpSpec(spec *kops.NodeUpSpec, fldPath *field.Path) field.ErrorList {
+	return validateBinaryLocations("nodeup", spec.URL, spec.Hash, spec.URLArm64, spec.HashArm64, fldPath)
+}
+
+// validateProtokubeSpec checks that each protokube URL override is an absolute URL with a SHA-256 hash.
+func validateProtokubeSpec(spec *kops.ProtokubeSpec, fldPath *field.Path) field.ErrorList {
+	return validateBinaryLocations("protokube", spec.URL, spec.Hash, spec.URLArm64, spec.HashArm64, fldPath)
+}
+
+// validateChannelsSpec checks that each channels URL override is an absolute URL with a SHA-256 hash.
+func validateChannelsSpec(spec *kops.ChannelsSpec, fldPath *field.Path) field.ErrorList {
+	return validateBinaryLocations("channels", spec.URL, spec.Hash, spec.URLArm64, spec.HashArm64, fldPath)
+}
+
+// validateBinaryLocations checks the per-architecture URL and hash overrides of the named binary.
+func validateBinaryLocations(binary string, urlAmd64, hashAmd64, urlArm64, hashArm64 string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, override := range []struct {
+		url, hash, urlField, hashField string
+	}{
+		{urlAmd64, hashAmd64, "url", "hash"},
+		{urlArm64, hashArm64, "urlArm64", "hashArm64"},
+	} {
+		if override.url == "" {
+			if override.hash != "" {
+				allErrs = append(allErrs, field.Required(fldPath.Child(override.urlField), fmt.Sprintf("%s must be set when %s is set", override.urlField, override.hashField)))
+			}
+			continue
+		}
+
+		if u, err := url.Parse(override.url); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(override.urlField), override.url, fmt.Sprintf("cannot parse %s URL: %v", binary, err)))
+		} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(override.urlField), override.url, fmt.Sprintf("%s URL must be an absolute http or https URL", binary)))
+		}
+
+		if override.hash == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child(override.hashField), fmt.Sprintf("%s must be set when %s is set", override.hashField, override.urlField)))
+		} else if len(override.hash) != 64 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(override.hashField), override.hash, fmt.Sprintf("%s hash must be 64 (SHA-256) characters long", binary)))
+		} else if _, err := hex.DecodeString(override.hash); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(override.hashField), override.hash, fmt.Sprintf("%s hash must be hex-encoded", binary)))
+		}
+	}
+
+	return allErrs
+}
+
+// validatePreloadImages checks that the role is a known instance group role and that each image is a valid image reference.
+func validatePreloadImages(role string, images []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	var valid []string
+	for _, r := range kops.AllInstanceGroupRoles {
+		valid = append(valid, strings.ToLower(string(r)))
+	}
+	allErrs = append(allErrs, IsValidValue(fldPath, &role, valid)...)
+
+	for i, image := range images {
+		if _, err := assets.NormalizeImage(image); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(role).Index(i), image, fmt.Sprintf("image must be a valid image reference, optionally with a digest: %v", err)))
+		}
+	}
+
+	return allErrs
+}
+
+// validateContainerRegistry checks that the registry is of the form host[:port][/path], without an image tag or digest.
+// An http or https scheme is tolerated, as existing configurations use one; it is removed when images are remapped.
+func validateContainerRegistry(registry string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	location := strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+
+	host, repository, hasPath := location, "", false
+	if i := strings.Index(location, "/"); i != -1 {
+		host, repository, hasPath = location[:i], location[i+1:], true
+	}
+
+	if !assets.IsValidRegistryHost(host) {
+		allErrs = append(allErrs, field.Invalid(fldPath, registry, "must be a registry host, with an optional port and path"))
+	} else if hasPath && !assets.IsValidRepositoryPath(repository) {
+		allErrs = append(allErrs, field.Invalid(fldPath, registry, "registry path must not contain an image tag or digest"))
+	}
+
+	return allErrs
+}
+
+// validateContainerRegistryMirrors checks that each mirrored registry is a known upstream registry, and each mirror a valid registry.
+func validateContainerRegistryMirrors(registryMirrors map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	var registries []string
+	for registry := range registryMirrors {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+
+	for _, registry := range registries {
+		allErrs = append(allErrs, IsValidValue(fldPath, &registry, assets.UpstreamRegistries)...)
+		allErrs = append(allErrs, validateContainerRegistry(strings.TrimSuffix(registryMirrors[registry], "/"), fldPath.Key(registry))...)
+	}
+
+	return allErrs
+}
+
+func validateKopsControllerPort(spec *kops.ClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	port := int(spec.KopsControllerPort)
+	if port < 1024 || port > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath, spec.KopsControllerPort, "must be between 1024 and 65535"))
+		return allErrs
+	}
+
+	if spec.KubeAPIServer != nil && int(spec.KubeAPIServer.SecurePort) == port {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "kops-controller port cannot be the same as the kube-apiserver securePort"))
+	}
+	if name, found := etcdPorts[port]; found {
+		allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("kops-controller port cannot be the same as the %s port", name)))
+	}
+
+	return allErrs
+}
+
+// validateExternalCloudControllerKubelet checks that the kubelet does not run an in-tree cloud provider
+// when the cloud provider is handled by the external cloud controller manager.
+func validateExternalCloudControllerKubelet(kubelet *kops.KubeletConfigSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if kubelet != nil && kubelet.CloudProvider != "" && kubelet.CloudProvider != "external" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("cloudProvider"), "cloudProvider must be \"external\" when the external cloud controller manager is enabled"))
+	}
+
+	return allErrs
+}
+
 func validateKubeProxy(k *kops.KubeProxyConfig, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	master := k.Master
 
+	if len(k.IPVSExcludeCIDRS) > 0 && k.ProxyMode != "ipvs" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("ipvsExcludeCidrs"), "ipvsExcludeCidrs requires proxyMode ipvs"))
+	}
+
 	for i, x := range k.IPVSExcludeCIDRS {
 		if _, _, err := net.ParseCIDR(x); err != nil {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("ipvsExcludeCidrs").Index(i), x, "Invalid network CIDR"))
 		}
 	}
 
-	if master != "" && !isValidAPIServersURL(master) {
-		allErrs = append(allErrs, field.Invalid(fldPath.Child("master"), master, "Not a valid APIServer URL"))
+	if master != "" {
+		if !isValidAPIServersURL(master) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("master"), master, "Not a valid APIServer URL"))
+		} else if u, _ := url.Parse(master); u.Scheme != "https" {
+			// The API server only serves https, which kube-proxy must use to authenticate
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("master"), master, "APIServer URL must use https"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateMasterKubeletMatchesKubelet checks that the settings which must agree between masters and nodes,
+// such as the cgroup driver which must match the container runtime, are not set differently on masters.
+func validateMasterKubeletMatchesKubelet(kubelet *kops.KubeletConfigSpec, masterKubelet *kops.KubeletConfigSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, setting := range []struct {
+		name   string
+		node   string
+		master string
+	}{
+		{"cgroupDriver", kubelet.CgroupDriver, masterKubelet.CgroupDriver},
+		{"clusterDomain", kubelet.ClusterDomain, masterKubelet.ClusterDomain},
+		{"nonMasqueradeCIDR", kubelet.NonMasqueradeCIDR, masterKubelet.NonMasqueradeCIDR},
+	} {
+		if setting.node != "" && setting.master != "" && setting.node != setting.master {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child(setting.name),
+				fmt.Sprintf("masterKubelet %s %q must match kubelet %s %q", setting.name, setting.master, setting.name, setting.node)))
+		}
 	}
 
 	return allErrs
@@ -637,6 +1122,10 @@ func validateKubelet(k *kops.KubeletConfigSpec, c *kops.Cluster, kubeletPath *fi
 			}
 		}
 
+		if k.VolumePluginDirectory != "" && !path.IsAbs(k.VolumePluginDirectory) {
+			allErrs = append(allErrs, field.Invalid(kubeletPath.Child("volumePluginDirectory"), k.VolumePluginDirectory, "volumePluginDirectory must be absolute"))
+		}
+
 	}
 	return allErrs
 }
@@ -651,6 +1140,9 @@ func validateNetworking(cluster *kops.Cluster, v *kops.NetworkingSpec, fldPath *
 	}
 
 	if v.Kubenet != nil {
+		if optionTaken {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("kubenet"), "only one networking option permitted"))
+		}
 		optionTaken = true
 	}
 
@@ -668,8 +1160,11 @@ func validateNetworking(cluster *kops.Cluster, v *kops.NetworkingSpec, fldPath *
 		optionTaken = true
 	}
 
-	if v.CNI != nil && optionTaken {
-		allErrs = append(allErrs, field.Forbidden(fldPath.Child("cni"), "only one networking option permitted"))
+	if v.CNI != nil {
+		if optionTaken {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("cni"), "only one networking option permitted"))
+		}
+		optionTaken = true
 	}
 
 	if v.Weave != nil {
@@ -708,10 +1203,9 @@ func validateNetworking(cluster *kops.Cluster, v *kops.NetworkingSpec, fldPath *
 		if optionTaken {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("kuberouter"), "only one networking option permitted"))
 		}
-		if c.KubeProxy != nil && (c.KubeProxy.Enabled == nil || *c.KubeProxy.Enabled) {
-			allErrs = append(allErrs, field.Forbidden(fldPath.Root().Child("spec", "kubeProxy", "enabled"), "kube-router requires kubeProxy to be disabled"))
-		}
 		optionTaken = true
+
+		allErrs = append(allErrs, validateNetworkingKuberouter(c, v.Kuberouter, fldPath.Child("kuberouter"))...)
 	}
 
 	if v.Romana != nil {
@@ -757,6 +1251,48 @@ func validateNetworking(cluster *kops.Cluster, v *kops.NetworkingSpec, fldPath *
 		allErrs = append(allErrs, validateNetworkingGCE(c, v.GCE, fldPath.Child("gce"))...)
 	}
 
+	if c.IsIPv6Only() || hasIPv6Subnets(c) {
+		allErrs = append(allErrs, validateNetworkingIPv6(c, v, fldPath)...)
+	}
+
+	return allErrs
+}
+
+// hasIPv6Subnets returns true if any of the cluster subnets declares an IPv6 CIDR.
+func hasIPv6Subnets(c *kops.ClusterSpec) bool {
+	for _, subnet := range c.Subnets {
+		if subnet.IPv6CIDR != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNetworkingIPv6 checks that the selected networking option is known to support IPv6.
+// The Cilium version requirement is checked in validateNetworkingCilium.
+func validateNetworkingIPv6(c *kops.ClusterSpec, v *kops.NetworkingSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch {
+	case v.Kubenet != nil && hasIPv6Subnets(c):
+		// kubenet assigns pods a single-family pod CIDR, so it works for IPv6-only clusters but not with IPv6 subnets
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("kubenet"), "kubenet networking does not support IPv6 subnets"))
+	case v.Kopeio != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("kopeio"), "kopeio networking does not support IPv6"))
+	case v.Weave != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("weave"), "Weave networking does not support IPv6"))
+	case v.Flannel != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("flannel"), "Flannel networking does not support IPv6"))
+	case v.Canal != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("canal"), "Canal networking does not support IPv6"))
+	case v.Kuberouter != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("kuberouter"), "kube-router networking does not support IPv6"))
+	case v.LyftVPC != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("lyftvpc"), "Lyft VPC networking does not support IPv6"))
+	case v.GCE != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("gce"), "GCE networking does not support IPv6"))
+	}
+
 	return allErrs
 }
 
@@ -772,6 +1308,19 @@ func validateNetworkingFlannel(v *kops.FlannelNetworkingSpec, fldPath *field.Pat
 	return allErrs
 }
 
+// validateNetworkingKuberouter checks that kube-router replaces kube-proxy, so that services are served
+func validateNetworkingKuberouter(c *kops.ClusterSpec, v *kops.KuberouterNetworkingSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.KubeProxy != nil && (c.KubeProxy.Enabled == nil || *c.KubeProxy.Enabled) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Root().Child("spec", "kubeProxy", "enabled"), "kube-router requires kubeProxy to be disabled"))
+	} else if v.RunServiceProxy != nil && !*v.RunServiceProxy {
+		allErrs = append(allErrs, field.Required(fldPath.Child("runServiceProxy"), "kube-router must run its service proxy when kubeProxy is disabled"))
+	}
+
+	return allErrs
+}
+
 func validateNetworkingCanal(v *kops.CanalNetworkingSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -816,11 +1365,11 @@ func validateNetworkingCilium(cluster *kops.Cluster, v *kops.CiliumNetworkingSpe
 			allErrs = append(allErrs, field.Invalid(versionFld, v.Version, "Could not parse as semantic version"))
 		}
 
-		if !(version.Minor >= 8 && version.Minor <= 10) {
+		if version.LT(minimumVersions["cilium"]) || version.Minor > 10 {
 			allErrs = append(allErrs, field.Invalid(versionFld, v.Version, "Only versions 1.8 through 1.10 are supported"))
 		}
 
-		if version.Minor < 10 && c.IsIPv6Only() {
+		if version.LT(minimumVersions["ciliumIPv6"]) && (c.IsIPv6Only() || hasIPv6Subnets(c)) {
 			allErrs = append(allErrs, field.Invalid(versionFld, v.Version, "kOps only supports IPv6 on version 1.10 or later"))
 		}
 
@@ -865,16 +1414,28 @@ func validateNetworkingCilium(cluster *kops.Cluster, v *kops.CiliumNetworkingSpe
 			if c.IsIPv6Only() {
 				allErrs = append(allErrs, field.Forbidden(fldPath.Child("ipam"), "Cilium ENI IPAM does not support IPv6"))
 			}
+			allErrs = append(allErrs, validateCiliumENIAdditionalPolicies(c, fldPath.Root().Child("spec", "additionalPolicies"))...)
 		}
 	}
 
 	if v.EtcdManaged {
-		hasCiliumCluster := false
+		mainVersion := ""
 		for _, cluster := range c.EtcdClusters {
+			if cluster.Name == "main" {
+				mainVersion = cluster.Version
+			}
+		}
+
+		hasCiliumCluster := false
+		for i, cluster := range c.EtcdClusters {
 			if cluster.Name == "cilium" {
 				if cluster.Provider == kops.EtcdProviderTypeLegacy {
 					allErrs = append(allErrs, field.Invalid(fldPath.Root().Child("etcdClusters"), kops.EtcdProviderTypeLegacy, "Legacy etcd provider is not supported for the cilium cluster"))
 				}
+				if cluster.Version != "" && mainVersion != "" && cluster.Version != mainVersion {
+					allErrs = append(allErrs, field.Invalid(fldPath.Root().Child("etcdClusters").Index(i).Child("version"), cluster.Version,
+						fmt.Sprintf("the cilium etcd cluster must use the same etcd version as the main etcd cluster (%s)", mainVersion)))
+				}
 				hasCiliumCluster = true
 				break
 			}
@@ -887,6 +1448,69 @@ func validateNetworkingCilium(cluster *kops.Cluster, v *kops.CiliumNetworkingSpe
 	return allErrs
 }
 
+// hubbleMetricsWarnings returns a warning when Hubble metrics are enabled, but the cluster has neither
+// the prometheus-operator addon nor metrics-server, so that nothing is likely to scrape them.
+func hubbleMetricsWarnings(c *kops.Cluster) []string {
+	if c.Spec.Networking == nil || c.Spec.Networking.Cilium == nil {
+		return nil
+	}
+	hubble := c.Spec.Networking.Cilium.Hubble
+	if hubble == nil || !fi.BoolValue(hubble.Enabled) || len(hubble.Metrics) == 0 {
+		return nil
+	}
+
+	if c.Spec.MetricsServer != nil && fi.BoolValue(c.Spec.MetricsServer.Enabled) {
+		return nil
+	}
+	for _, addon := range c.Spec.Addons {
+		if strings.Contains(addon.Manifest, "prometheus-operator") {
+			return nil
+		}
+	}
+
+	return []string{"Hubble metrics are enabled, but require a Prometheus scrape configuration to be collected; " +
+		"the cluster has neither the prometheus-operator addon nor metrics-server, so install Prometheus and configure it to scrape the Cilium agents"}
+}
+
+// validateCiliumENIAdditionalPolicies checks that the additional policies do not deny the EC2 actions needed by Cilium ENI IPAM.
+func validateCiliumENIAdditionalPolicies(c *kops.ClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if c.AdditionalPolicies == nil {
+		return allErrs
+	}
+
+	for _, role := range []kops.InstanceGroupRole{kops.InstanceGroupRoleMaster, kops.InstanceGroupRoleNode} {
+		key := strings.ToLower(string(role))
+		policy, found := (*c.AdditionalPolicies)[key]
+		if !found {
+			continue
+		}
+
+		statements, err := iam.ParseStatements(policy)
+		if err != nil {
+			// Invalid policies are reported by validateAdditionalPolicy
+			continue
+		}
+
+		var denied []string
+		for _, action := range iam.CiliumENIActions() {
+			for _, statement := range statements {
+				if statement.DeniesAction(action) {
+					denied = append(denied, action)
+					break
+				}
+			}
+		}
+		if len(denied) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(key),
+				fmt.Sprintf("Cilium ENI IPAM requires %s, which the additional policy denies", strings.Join(denied, ", "))))
+		}
+	}
+
+	return allErrs
+}
+
 func validateNetworkingGCE(c *kops.ClusterSpec, v *kops.GCENetworkingSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -904,22 +1528,44 @@ func validateAdditionalPolicy(role string, policy string, fldPath *field.Path) f
 	for _, r := range kops.AllInstanceGroupRoles {
 		valid = append(valid, strings.ToLower(string(r)))
 	}
+	// Roles must match exactly, so that e.g. Node and node cannot both be specified
 	allErrs = append(allErrs, IsValidValue(fldPath, &role, valid)...)
 
+	if strings.TrimSpace(policy) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Key(role), "policy must not be empty"))
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validatePolicyStatements(policy, fldPath.Key(role))...)
+
+	return allErrs
+}
+
+// validatePolicyStatements validates a JSON array of IAM policy statements
+func validatePolicyStatements(policy string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
 	statements, err := iam.ParseStatements(policy)
 	if err != nil {
-		allErrs = append(allErrs, field.Invalid(fldPath.Key(role), policy, "policy was not valid JSON: "+err.Error()))
+		allErrs = append(allErrs, field.Invalid(fldPath, policy, "policy was not valid JSON: "+err.Error()))
 	}
 
 	// Trivial validation of policy, mostly to make sure it isn't some other random object
 	for i, statement := range statements {
-		fldEffect := fldPath.Key(role).Index(i).Child("Effect")
+		fldStatement := fldPath.Index(i)
+		fldEffect := fldStatement.Child("Effect")
 		if statement.Effect == "" {
 			allErrs = append(allErrs, field.Required(fldEffect, "Effect must be specified for IAM policy"))
 		} else {
 			value := string(statement.Effect)
 			allErrs = append(allErrs, IsValidValue(fldEffect, &value, []string{"Allow", "Deny"})...)
 		}
+		if statement.Action.IsEmpty() && statement.NotAction.IsEmpty() {
+			allErrs = append(allErrs, field.Required(fldStatement.Child("Action"), "Action or NotAction must be specified for IAM policy"))
+		}
+		if statement.Resource.IsEmpty() && statement.NotResource.IsEmpty() {
+			allErrs = append(allErrs, field.Required(fldStatement.Child("Resource"), "Resource or NotResource must be specified for IAM policy"))
+		}
 	}
 
 	return allErrs
@@ -964,9 +1610,14 @@ func validateEtcdClusterSpec(spec kops.EtcdClusterSpec, c *kops.Cluster, fieldPa
 		// Not technically a requirement, but doesn't really make sense to allow
 		allErrs = append(allErrs, field.Invalid(fieldPath.Child("etcdMembers"), len(spec.Members), "Should be an odd number of master-zones for quorum. Use --zones and --master-zones to declare node zones and master zones separately"))
 	}
-	allErrs = append(allErrs, validateEtcdVersion(spec, fieldPath, nil)...)
+	allErrs = append(allErrs, validateEtcdVersion(spec, fieldPath)...)
 	for i, m := range spec.Members {
-		allErrs = append(allErrs, validateEtcdMemberSpec(m, fieldPath.Child("etcdMembers").Index(i))...)
+		allErrs = append(allErrs, validateEtcdMemberSpec(m, c, fieldPath.Child("etcdMembers").Index(i))...)
+	}
+	if spec.Manager != nil && spec.Manager.Image != "" {
+		if _, err := assets.NormalizeImage(spec.Manager.Image); err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("manager", "image"), spec.Manager.Image, fmt.Sprintf("image must be a valid image reference: %v", err)))
+		}
 	}
 
 	return allErrs
@@ -975,12 +1626,67 @@ func validateEtcdClusterSpec(spec kops.EtcdClusterSpec, c *kops.Cluster, fieldPa
 // validateEtcdBackupStore checks that the etcd clusters backupStore path is unique.
 func validateEtcdBackupStore(specs []kops.EtcdClusterSpec, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	etcdBackupStore := make(map[string]bool)
-	for _, x := range specs {
-		if _, alreadyUsed := etcdBackupStore[x.Name]; alreadyUsed {
-			allErrs = append(allErrs, field.Forbidden(fieldPath.Index(0).Child("backupStore"), "the backup store must be unique for each etcd cluster"))
+	etcdBackupStore := make(map[string]string)
+	for i, x := range specs {
+		if x.Backups == nil || x.Backups.BackupStore == "" {
+			// The default backup store is derived from the etcd cluster name, which is unique
+			continue
 		}
-		etcdBackupStore[x.Name] = true
+		backupStore := strings.TrimSuffix(x.Backups.BackupStore, "/")
+		if name, alreadyUsed := etcdBackupStore[backupStore]; alreadyUsed {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Index(i).Child("backups", "backupStore"), fmt.Sprintf("the backup store must be unique for each etcd cluster, but is also used by etcd cluster %q", name)))
+		}
+		etcdBackupStore[backupStore] = x.Name
+	}
+
+	return allErrs
+}
+
+// validateEtcdMemberTopology checks that every etcd cluster has members on the same instance groups as the first etcd cluster,
+// as the etcd clusters all run on the same control plane instances
+func validateEtcdMemberTopology(specs []kops.EtcdClusterSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	memberInstanceGroups := func(spec kops.EtcdClusterSpec) []string {
+		var instanceGroups []string
+		for _, m := range spec.Members {
+			instanceGroups = append(instanceGroups, fi.StringValue(m.InstanceGroup))
+		}
+		sort.Strings(instanceGroups)
+		return instanceGroups
+	}
+
+	if len(specs) < 2 {
+		return allErrs
+	}
+	expected := strings.Join(memberInstanceGroups(specs[0]), ",")
+	for i, x := range specs[1:] {
+		actual := strings.Join(memberInstanceGroups(x), ",")
+		if actual != expected {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Index(i+1).Child("etcdMembers"), actual,
+				fmt.Sprintf("etcd cluster members must be on the same instance groups as etcd cluster %q: %s", specs[0].Name, expected)))
+		}
+	}
+
+	return allErrs
+}
+
+// validateChannel checks that the channel is an absolute URL or the name of a known channel.
+func validateChannel(channel string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if channel == "none" {
+		return allErrs
+	}
+
+	u, err := url.Parse(channel)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, channel, fmt.Sprintf("channel is not a valid URL: %v", err)))
+		return allErrs
+	}
+
+	if !u.IsAbs() {
+		allErrs = append(allErrs, IsValidValue(fldPath, &channel, kops.KnownChannels)...)
 	}
 
 	return allErrs
@@ -989,14 +1695,23 @@ func validateEtcdBackupStore(specs []kops.EtcdClusterSpec, fieldPath *field.Path
 // validateEtcdTLS checks the TLS settings for etcd are valid
 func validateEtcdTLS(specs []kops.EtcdClusterSpec, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	var usingTLS int
-	for _, x := range specs {
-		if x.EnableEtcdTLS {
+	var legacy, usingTLS int
+	for i, x := range specs {
+		if x.Provider == "" || x.Provider == kops.EtcdProviderTypeManager {
+			// etcd-manager always uses TLS
+			if x.EnableEtcdTLS != nil && !*x.EnableEtcdTLS {
+				allErrs = append(allErrs, field.Forbidden(fieldPath.Index(i).Child("enableEtcdTLS"), "TLS cannot be disabled when using etcd-manager"))
+			}
+			continue
+		}
+
+		legacy++
+		if fi.BoolValue(x.EnableEtcdTLS) {
 			usingTLS++
 		}
 	}
 	// check both clusters are using tls if one is enabled
-	if usingTLS > 0 && usingTLS != len(specs) {
+	if usingTLS > 0 && usingTLS != legacy {
 		allErrs = append(allErrs, field.Forbidden(fieldPath.Index(0).Child("enableEtcdTLS"), "both etcd clusters must have TLS enabled or none at all"))
 	}
 
@@ -1004,10 +1719,23 @@ func validateEtcdTLS(specs []kops.EtcdClusterSpec, fieldPath *field.Path) field.
 }
 
 // validateEtcdStorage is responsible for checking versions are identical.
-func validateEtcdStorage(specs []kops.EtcdClusterSpec, fieldPath *field.Path) field.ErrorList {
+// The events cluster is permitted to run a different version from the main cluster.
+// The version of the cilium cluster is checked by validateNetworkingCilium when Cilium uses it.
+func validateEtcdStorage(c *kops.ClusterSpec, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
+	specs := c.EtcdClusters
+	ciliumEtcdManaged := c.Networking != nil && c.Networking.Cilium != nil && c.Networking.Cilium.EtcdManaged
 	version := specs[0].Version
+	for _, x := range specs {
+		if x.Name == "main" {
+			version = x.Version
+			break
+		}
+	}
 	for i, x := range specs {
+		if x.Name == "events" || (x.Name == "cilium" && ciliumEtcdManaged) {
+			continue
+		}
 		if x.Version != "" && x.Version != version {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Index(i).Child("version"), fmt.Sprintf("cluster: %q, has a different storage version: %q, both must be the same", x.Name, x.Version)))
 		}
@@ -1018,13 +1746,10 @@ func validateEtcdStorage(specs []kops.EtcdClusterSpec, fieldPath *field.Path) fi
 
 // validateEtcdVersion is responsible for validating the storage version of etcd
 // @TODO semvar package doesn't appear to ignore a 'v' in v1.1.1; could be a problem later down the line
-func validateEtcdVersion(spec kops.EtcdClusterSpec, fieldPath *field.Path, minimalVersion *semver.Version) field.ErrorList {
+func validateEtcdVersion(spec kops.EtcdClusterSpec, fieldPath *field.Path) field.ErrorList {
 	// @check if the storage is specified that it's valid
 
-	if minimalVersion == nil {
-		v := semver.MustParse("0.0.0")
-		minimalVersion = &v
-	}
+	minimalVersion := minimumVersions["etcd"]
 
 	version := spec.Version
 	if spec.Version == "" {
@@ -1038,7 +1763,7 @@ func validateEtcdVersion(spec kops.EtcdClusterSpec, fieldPath *field.Path, minim
 
 	// we only support v3 for now
 	if sem.Major == 3 {
-		if sem.LT(*minimalVersion) {
+		if sem.LT(minimalVersion) {
 			return field.ErrorList{field.Invalid(fieldPath.Child("version"), version, fmt.Sprintf("minimum version required is %s", minimalVersion.String()))}
 		}
 		return nil
@@ -1047,8 +1772,22 @@ func validateEtcdVersion(spec kops.EtcdClusterSpec, fieldPath *field.Path, minim
 	return field.ErrorList{field.Invalid(fieldPath.Child("version"), version, "unsupported storage version, we only support major version 3")}
 }
 
+const (
+	// minEtcdVolumeSize is the smallest etcd volume size in GiB we accept, leaving room for etcd's default 2GiB quota.
+	minEtcdVolumeSize = 5
+	// recommendedEtcdVolumeSize is the etcd volume size in GiB below which we warn, matching the default size.
+	recommendedEtcdVolumeSize = 20
+)
+
+// etcdVolumeTypes are the volume types suitable for etcd on each cloud provider.
+// Cloud providers without an entry accept any volume type.
+var etcdVolumeTypes = map[kops.CloudProviderID][]string{
+	kops.CloudProviderAWS: {"standard", "gp2", "gp3", "io1", "io2"},
+	kops.CloudProviderGCE: {"pd-standard", "pd-balanced", "pd-ssd"},
+}
+
 // validateEtcdMemberSpec is responsible for validate the cluster member
-func validateEtcdMemberSpec(spec kops.EtcdMemberSpec, fieldPath *field.Path) field.ErrorList {
+func validateEtcdMemberSpec(spec kops.EtcdMemberSpec, c *kops.Cluster, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if spec.Name == "" {
 		allErrs = append(allErrs, field.Required(fieldPath.Child("name"), "etcdMember did not have name"))
@@ -1058,9 +1797,33 @@ func validateEtcdMemberSpec(spec kops.EtcdMemberSpec, fieldPath *field.Path) fie
 		allErrs = append(allErrs, field.Required(fieldPath.Child("instanceGroup"), "etcdMember did not have instanceGroup"))
 	}
 
+	if spec.VolumeSize != nil && *spec.VolumeSize < minEtcdVolumeSize {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("volumeSize"), *spec.VolumeSize, fmt.Sprintf("etcd volumeSize must be at least %dGiB", minEtcdVolumeSize)))
+	}
+
+	if spec.VolumeType != nil {
+		if valid, found := etcdVolumeTypes[kops.CloudProviderID(c.Spec.CloudProvider)]; found {
+			allErrs = append(allErrs, IsValidValue(fieldPath.Child("volumeType"), spec.VolumeType, valid)...)
+		}
+	}
+
 	return allErrs
 }
 
+// etcdVolumeSizeWarnings returns warnings for etcd members with volumes smaller than recommended, which etcd may fill.
+func etcdVolumeSizeWarnings(c *kops.Cluster) []string {
+	var warnings []string
+	for _, etcdCluster := range c.Spec.EtcdClusters {
+		for _, m := range etcdCluster.Members {
+			if m.VolumeSize == nil || *m.VolumeSize < minEtcdVolumeSize || *m.VolumeSize >= recommendedEtcdVolumeSize {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("volumeSize %dGiB of etcd member %q in etcd cluster %q is below the recommended %dGiB", *m.VolumeSize, m.Name, etcdCluster.Name, recommendedEtcdVolumeSize))
+		}
+	}
+	return warnings
+}
+
 func validateNetworkingCalico(v *kops.CalicoNetworkingSpec, e kops.EtcdClusterSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -1085,13 +1848,29 @@ func validateNetworkingCalico(v *kops.CalicoNetworkingSpec, e kops.EtcdClusterSp
 	}
 
 	if v.EncapsulationMode != "" {
-		// Don't tolerate "None" for now, which would disable encapsulation in the default IPPool
-		// object. Note that with no encapsulation, we'd need to select the "bird" networking
-		// backend in order to allow use of BGP to distribute routes for pod traffic.
-		valid := []string{"ipip", "vxlan"}
+		valid := []string{"ipip", "vxlan", "none"}
 		allErrs = append(allErrs, IsValidValue(fldPath.Child("encapsulationMode"), &v.EncapsulationMode, valid)...)
 	}
 
+	if v.NetworkingBackend != "" {
+		valid := []string{"bird", "vxlan", "none"}
+		allErrs = append(allErrs, IsValidValue(fldPath.Child("networkingBackend"), &v.NetworkingBackend, valid)...)
+	}
+
+	// With no encapsulation in the default IPPool object, we need the "bird" networking
+	// backend in order to allow use of BGP to distribute routes for pod traffic.
+	if v.EncapsulationMode == "none" && v.NetworkingBackend != "" && v.NetworkingBackend != "bird" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("encapsulationMode"), `Calico's "none" encapsulation mode requires the "bird" networking backend to distribute routes`))
+	}
+
+	// IP-in-IP encapsulation also relies on BGP to maintain routes, so it needs the "bird" networking backend too.
+	// The "ipip" encapsulation mode is the default.
+	if v.NetworkingBackend == "vxlan" || v.NetworkingBackend == "none" {
+		if (v.EncapsulationMode == "" || v.EncapsulationMode == "ipip") && v.IPIPMode != "Never" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("networkingBackend"), `Calico's "ipip" encapsulation mode requires the "bird" networking backend to distribute routes`))
+		}
+	}
+
 	if v.IPIPMode != "" {
 		child := fldPath.Child("ipipMode")
 		allErrs = append(allErrs, validateCalicoIPPoolEncapsulationMode(v.IPIPMode, child)...)
@@ -1204,17 +1983,9 @@ func validateContainerRuntime(runtime *string, fldPath *field.Path) field.ErrorL
 func validateContainerdConfig(config *kops.ContainerdConfig, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
-	if config.Version != nil {
-		sv, err := semver.ParseTolerant(*config.Version)
-		if err != nil {
-			allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), config.Version,
-				fmt.Sprintf("unable to parse version string: %s", err.Error())))
-		}
-		if sv.LT(semver.MustParse("1.3.4")) {
-			allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), config.Version,
-				"unsupported legacy version"))
-		}
-	}
+	allErrs = append(allErrs, validateContainerdVersion(config.Version, fldPath.Child("version"))...)
+	allErrs = append(allErrs, validateContainerdVersion(config.VersionAmd64, fldPath.Child("versionAmd64"))...)
+	allErrs = append(allErrs, validateContainerdVersion(config.VersionArm64, fldPath.Child("versionArm64"))...)
 
 	if config.Packages != nil {
 		if config.Packages.UrlAmd64 != nil && config.Packages.HashAmd64 != nil {
@@ -1224,11 +1995,7 @@ func validateContainerdConfig(config *kops.ContainerdConfig, fldPath *field.Path
 				allErrs = append(allErrs, field.Invalid(fldPath.Child("packageUrl"), config.Packages.UrlAmd64,
 					fmt.Sprintf("cannot parse package URL: %v", err)))
 			}
-			h := fi.StringValue(config.Packages.HashAmd64)
-			if len(h) > 64 {
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("packageHash"), config.Packages.HashAmd64,
-					"Package hash must be 64 characters long"))
-			}
+			allErrs = append(allErrs, validatePackageHash(config.Packages.HashAmd64, fldPath.Child("packageHash"))...)
 		} else if config.Packages.UrlAmd64 != nil {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("packageUrl"), config.Packages.HashAmd64,
 				"Package hash must also be set"))
@@ -1244,11 +2011,7 @@ func validateContainerdConfig(config *kops.ContainerdConfig, fldPath *field.Path
 				allErrs = append(allErrs, field.Invalid(fldPath.Child("packageUrlArm64"), config.Packages.UrlArm64,
 					fmt.Sprintf("cannot parse package URL: %v", err)))
 			}
-			h := fi.StringValue(config.Packages.HashArm64)
-			if len(h) > 64 {
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("packageHashArm64"), config.Packages.HashArm64,
-					"Package hash must be 64 characters long"))
-			}
+			allErrs = append(allErrs, validatePackageHash(config.Packages.HashArm64, fldPath.Child("packageHashArm64"))...)
 		} else if config.Packages.UrlArm64 != nil {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("packageUrlArm64"), config.Packages.HashArm64,
 				"Package hash must also be set"))
@@ -1261,6 +2024,24 @@ func validateContainerdConfig(config *kops.ContainerdConfig, fldPath *field.Path
 	return allErrs
 }
 
+func validateContainerdVersion(version *string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if version != nil {
+		sv, err := semver.ParseTolerant(*version)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, version,
+				fmt.Sprintf("unable to parse version string: %s", err.Error())))
+		}
+		if sv.LT(minimumVersions["containerd"]) {
+			allErrs = append(allErrs, field.Invalid(fldPath, version,
+				"unsupported legacy version"))
+		}
+	}
+
+	return allErrs
+}
+
 func validateDockerConfig(config *kops.DockerConfig, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -1270,10 +2051,10 @@ func validateDockerConfig(config *kops.DockerConfig, fldPath *field.Path) field.
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), config.Version,
 				fmt.Sprintf("unable to parse version string: %s", err.Error())))
 		}
-		if sv.LT(semver.MustParse("1.14.0")) {
+		if sv.LT(minimumVersions["dockerAvailable"]) {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), config.Version,
 				"version is no longer available: https://www.docker.com/blog/changes-dockerproject-org-apt-yum-repositories"))
-		} else if sv.LT(semver.MustParse("17.3.0")) {
+		} else if sv.LT(minimumVersions["docker"]) {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("version"), config.Version,
 				"unsupported legacy version"))
 		}
@@ -1287,11 +2068,7 @@ func validateDockerConfig(config *kops.DockerConfig, fldPath *field.Path) field.
 				allErrs = append(allErrs, field.Invalid(fldPath.Child("packageUrl"), config.Packages.UrlAmd64,
 					fmt.Sprintf("unable parse package URL string: %v", err)))
 			}
-			h := fi.StringValue(config.Packages.HashAmd64)
-			if len(h) > 64 {
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("packageHash"), config.Packages.HashAmd64,
-					"Package hash must be 64 characters long"))
-			}
+			allErrs = append(allErrs, validatePackageHash(config.Packages.HashAmd64, fldPath.Child("packageHash"))...)
 		} else if config.Packages.UrlAmd64 != nil {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("packageUrl"), config.Packages.HashAmd64,
 				"Package hash must also be set"))
@@ -1307,11 +2084,7 @@ func validateDockerConfig(config *kops.DockerConfig, fldPath *field.Path) field.
 				allErrs = append(allErrs, field.Invalid(fldPath.Child("packageUrlArm64"), config.Packages.UrlArm64,
 					fmt.Sprintf("unable parse package URL string: %v", err)))
 			}
-			h := fi.StringValue(config.Packages.HashArm64)
-			if len(h) > 64 {
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("packageHashArm64"), config.Packages.HashArm64,
-					"Package hash must be 64 characters long"))
-			}
+			allErrs = append(allErrs, validatePackageHash(config.Packages.HashArm64, fldPath.Child("packageHashArm64"))...)
 		} else if config.Packages.UrlArm64 != nil {
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("packageUrlArm64"), config.Packages.HashArm64,
 				"Package hash must also be set"))
@@ -1324,8 +2097,17 @@ func validateDockerConfig(config *kops.DockerConfig, fldPath *field.Path) field.
 	if config.Storage != nil {
 		valid := []string{"aufs", "btrfs", "devicemapper", "overlay", "overlay2", "zfs"}
 		values := strings.Split(*config.Storage, ",")
+		seen := sets.NewString()
 		for _, value := range values {
 			allErrs = append(allErrs, IsValidValue(fldPath.Child("storage"), &value, valid)...)
+			if seen.Has(value) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("storage"), *config.Storage, fmt.Sprintf("duplicate storage driver %q", value)))
+			}
+			seen.Insert(value)
+		}
+		// nodeup checks kernel support for overlay2 as overlay, so only the first of the two could ever be selected
+		if seen.Has("overlay") && seen.Has("overlay2") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storage"), *config.Storage, "overlay and overlay2 cannot both be listed"))
 		}
 	}
 
@@ -1364,6 +2146,75 @@ func validateRollingUpdate(rollingUpdate *kops.RollingUpdate, fldpath *field.Pat
 	return allErrs
 }
 
+// rollingUpdateMasterSurgeWarnings returns warnings for master instance groups which inherit a non-zero maxSurge from the
+// cluster's rollingUpdate. Masters cannot surge, so the setting is ignored for them.
+func rollingUpdateMasterSurgeWarnings(c *kops.Cluster, groups []*kops.InstanceGroup) []string {
+	if c.Spec.RollingUpdate == nil || c.Spec.RollingUpdate.MaxSurge == nil {
+		return nil
+	}
+	if surge, err := intstr.GetValueFromIntOrPercent(c.Spec.RollingUpdate.MaxSurge, 1000, true); err != nil || surge == 0 {
+		return nil
+	}
+
+	var warnings []string
+	for _, g := range groups {
+		if g.Spec.Role != kops.InstanceGroupRoleMaster {
+			continue
+		}
+		if g.Spec.RollingUpdate != nil && g.Spec.RollingUpdate.MaxSurge != nil {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("spec.rollingUpdate.maxSurge %q will be ignored for instance group %q, as instance groups with role \"Master\" cannot surge", c.Spec.RollingUpdate.MaxSurge.String(), g.ObjectMeta.Name))
+	}
+	return warnings
+}
+
+// iptablesBackendNFTPath returns the path of the Calico or Canal iptablesBackend field if it is set to NFT, or nil otherwise.
+func iptablesBackendNFTPath(spec *kops.ClusterSpec) *field.Path {
+	if spec.Networking == nil {
+		return nil
+	}
+	if spec.Networking.Calico != nil && spec.Networking.Calico.IptablesBackend == "NFT" {
+		return field.NewPath("spec", "networking", "calico", "iptablesBackend")
+	}
+	if spec.Networking.Canal != nil && spec.Networking.Canal.IptablesBackend == "NFT" {
+		return field.NewPath("spec", "networking", "canal", "iptablesBackend")
+	}
+	return nil
+}
+
+// iptablesBackendNFTWarnings returns a warning if the cluster uses the NFT iptables backend, which is not supported by older kernels.
+func iptablesBackendNFTWarnings(c *kops.Cluster) []string {
+	fldPath := iptablesBackendNFTPath(&c.Spec)
+	if fldPath == nil {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s is NFT, which requires a recent kernel with nftables support; networking will not work on older distributions", fldPath)}
+}
+
+// legacyIptablesImageSubstrings identifies images of distributions whose kernels and iptables do not support nftables.
+// Mapping images to kernels is fuzzy, so this only lists well-known names and anything unrecognised is allowed.
+var legacyIptablesImageSubstrings = []string{
+	"amzn-ami-",
+	"centos-7",
+	"debian-9-",
+	"debian-stretch",
+	"rhel-7",
+	"ubuntu-16.04",
+	"ubuntu-xenial",
+}
+
+// isLegacyIptablesImage returns true if the image is known to be for a distribution without nftables support.
+func isLegacyIptablesImage(image string) bool {
+	image = strings.ToLower(image)
+	for _, s := range legacyIptablesImageSubstrings {
+		if strings.Contains(image, s) {
+			return true
+		}
+	}
+	return false
+}
+
 func validateNodeLocalDNS(spec *kops.ClusterSpec, fldpath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -1372,6 +2223,18 @@ func validateNodeLocalDNS(spec *kops.ClusterSpec, fldpath *field.Path) field.Err
 		ip := net.ParseIP(address)
 		if ip == nil {
 			allErrs = append(allErrs, field.Invalid(fldpath.Child("kubeDNS", "nodeLocalDNS", "localIP"), address, "Cluster had an invalid kubeDNS.nodeLocalDNS.localIP"))
+		} else if !ip.IsLinkLocalUnicast() {
+			// Link-local addresses (such as the default 169.254.20.10) cannot collide with cluster addresses
+			localIPPath := fldpath.Child("kubeDNS", "nodeLocalDNS", "localIP")
+			if _, networkCIDR, err := net.ParseCIDR(spec.NetworkCIDR); err == nil && networkCIDR.Contains(ip) {
+				allErrs = append(allErrs, field.Forbidden(localIPPath, fmt.Sprintf("localIP %q must not be within the networkCIDR %q", address, spec.NetworkCIDR)))
+			}
+			if _, serviceCIDR, err := net.ParseCIDR(spec.ServiceClusterIPRange); err == nil && serviceCIDR.Contains(ip) {
+				allErrs = append(allErrs, field.Forbidden(localIPPath, fmt.Sprintf("localIP %q must not be within the serviceClusterIPRange %q", address, spec.ServiceClusterIPRange)))
+			}
+			if serverIP := net.ParseIP(spec.KubeDNS.ServerIP); serverIP != nil && serverIP.Equal(ip) {
+				allErrs = append(allErrs, field.Forbidden(localIPPath, fmt.Sprintf("localIP %q must not be the same as kubeDNS.serverIP", address)))
+			}
 		}
 	}
 
@@ -1389,7 +2252,11 @@ func validateNodeLocalDNS(spec *kops.ClusterSpec, fldpath *field.Path) field.Err
 }
 
 func validateClusterAutoscaler(cluster *kops.Cluster, spec *kops.ClusterAutoscalerConfig, fldPath *field.Path) (allErrs field.ErrorList) {
-	allErrs = append(allErrs, IsValidValue(fldPath.Child("expander"), spec.Expander, []string{"least-waste", "random", "most-pods"})...)
+	allErrs = append(allErrs, IsValidValue(fldPath.Child("expander"), spec.Expander, []string{"least-waste", "random", "most-pods", "priority", "price"})...)
+
+	if fi.StringValue(spec.Expander) == "price" && kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderGCE {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("expander"), "Cluster autoscaler price expander is supported only on GCE"))
+	}
 
 	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderOpenstack {
 		allErrs = append(allErrs, field.Forbidden(fldPath, "Cluster autoscaler is not supported on OpenStack"))
@@ -1420,11 +2287,24 @@ func validateAWSLoadBalancerController(cluster *kops.Cluster, spec *kops.AWSLoad
 		if !components.IsCertManagerEnabled(cluster) {
 			allErrs = append(allErrs, field.Forbidden(fldPath, "AWS Load Balancer Controller requires that cert manager is enabled"))
 		}
+
+		// The in-tree service controller would keep creating classic ELBs for the same Services
+		if kcm := cluster.Spec.KubeControllerManager; kcm != nil {
+			for i, controller := range kcm.Controllers {
+				if controller == "service" {
+					allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "kubeControllerManager", "controllers").Index(i),
+						"the in-tree service controller cannot be enabled alongside the AWS Load Balancer Controller, as both would manage load balancers for Services; remove \"service\" or disable it with \"-service\""))
+				}
+			}
+		}
 	}
 	return allErrs
 }
 
 func validateCloudConfiguration(cloudConfig *kops.CloudConfiguration, fldPath *field.Path) (allErrs field.ErrorList) {
+	if cloudConfig == nil {
+		return allErrs
+	}
 	if cloudConfig.ManageStorageClasses != nil && cloudConfig.Openstack != nil &&
 		cloudConfig.Openstack.BlockStorage != nil && cloudConfig.Openstack.BlockStorage.CreateStorageClass != nil {
 		if *cloudConfig.Openstack.BlockStorage.CreateStorageClass != *cloudConfig.ManageStorageClasses {
@@ -1446,20 +2326,39 @@ func validateWarmPool(warmPool *kops.WarmPoolSpec, fldPath *field.Path) (allErrs
 	if warmPool.MinSize < 0 {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("minSize"), warmPool.MinSize, "warm pool minSize cannot be negative"))
 	}
+	if warmPool.EnableLifecycleHook && !warmPool.IsEnabled() {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("enableLifecycleHook"), "warm pool lifecycle hook requires the warm pool to be enabled"))
+	}
+	allErrs = append(allErrs, validateLifecycleHookGracePeriod(warmPool, fldPath.Child("lifecycleHookGracePeriod"))...)
+	return allErrs
+}
+
+// validateLifecycleHookGracePeriod checks that nodeup can wait for the grace period and still complete the lifecycle hook in time.
+func validateLifecycleHookGracePeriod(warmPool *kops.WarmPoolSpec, fldPath *field.Path) (allErrs field.ErrorList) {
+	if warmPool.LifecycleHookGracePeriod == nil {
+		return allErrs
+	}
+	gracePeriod := warmPool.LifecycleHookGracePeriod.Duration
+	if gracePeriod < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, gracePeriod.String(), "warm pool lifecycleHookGracePeriod cannot be negative"))
+	} else if gracePeriod >= kops.WarmPoolLifecycleHookHeartbeatTimeout {
+		allErrs = append(allErrs, field.Invalid(fldPath, gracePeriod.String(), fmt.Sprintf("warm pool lifecycleHookGracePeriod must be less than the lifecycle hook heartbeat timeout of %v", kops.WarmPoolLifecycleHookHeartbeatTimeout)))
+	}
 	return allErrs
 }
 
 func validateSnapshotController(cluster *kops.Cluster, spec *kops.SnapshotControllerConfig, fldPath *field.Path) (allErrs field.ErrorList) {
 	if spec != nil && fi.BoolValue(spec.Enabled) {
+		if cluster.Spec.CloudConfig == nil || cluster.Spec.CloudConfig.AWSEBSCSIDriver == nil || !fi.BoolValue(cluster.Spec.CloudConfig.AWSEBSCSIDriver.Enabled) {
+			// Report the field the user has to set, rather than the snapshot controller which is correctly configured
+			allErrs = append(allErrs, field.Required(fldPath.Root().Child("cloudConfig", "awsEBSCSIDriver", "enabled"), "Snapshot controller requires the external CSI driver; set cloudConfig.awsEBSCSIDriver.enabled to true"))
+		}
 		if !cluster.IsKubernetesGTE("1.20") {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("enabled"), "Snapshot controller requires kubernetes 1.20+"))
 		}
 		if !components.IsCertManagerEnabled(cluster) {
 			allErrs = append(allErrs, field.Forbidden(fldPath.Child("enabled"), "Snapshot controller requires that cert manager is enabled"))
 		}
-		if cluster.Spec.CloudConfig == nil || cluster.Spec.CloudConfig.AWSEBSCSIDriver == nil || !fi.BoolValue(cluster.Spec.CloudConfig.AWSEBSCSIDriver.Enabled) {
-			allErrs = append(allErrs, field.Forbidden(fldPath.Child("enabled"), "Snapshot controller requires external CSI Driver"))
-		}
 	}
 	return allErrs
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package validation
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -198,6 +200,8 @@ func TestValidateSubnets(t *testing.T) {
 
 func TestValidateKubeAPIServer(t *testing.T) {
 	str := "foobar"
+	certFile := "/srv/kubernetes/proxy-client.crt"
+	keyFile := "/srv/kubernetes/proxy-client.key"
 	authzMode := "RBAC,Webhook"
 
 	grid := []struct {
@@ -208,7 +212,7 @@ func TestValidateKubeAPIServer(t *testing.T) {
 	}{
 		{
 			Input: kops.KubeAPIServerConfig{
-				ProxyClientCertFile: &str,
+				ProxyClientCertFile: &certFile,
 			},
 			ExpectedErrors: []string{
 				"Forbidden::KubeAPIServer",
@@ -217,13 +221,116 @@ func TestValidateKubeAPIServer(t *testing.T) {
 		},
 		{
 			Input: kops.KubeAPIServerConfig{
-				ProxyClientKeyFile: &str,
+				ProxyClientKeyFile: &keyFile,
 			},
 			ExpectedErrors: []string{
 				"Forbidden::KubeAPIServer",
 			},
 			ExpectedDetail: "proxyClientCertFile and proxyClientKeyFile must both be specified (or neither)",
 		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				ProxyClientCertFile: &certFile,
+				ProxyClientKeyFile:  &keyFile,
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				ProxyClientCertFile: &str,
+				ProxyClientKeyFile:  &keyFile,
+			},
+			ExpectedErrors: []string{
+				"Invalid value::KubeAPIServer.proxyClientCertFile",
+			},
+			ExpectedDetail: "proxyClientCertFile must be an absolute path",
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				ProxyClientCertFile: &certFile,
+				ProxyClientKeyFile:  &str,
+			},
+			ExpectedErrors: []string{
+				"Invalid value::KubeAPIServer.proxyClientKeyFile",
+			},
+			ExpectedDetail: "proxyClientKeyFile must be an absolute path",
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				AdmissionControl: []string{"NamespaceLifecycle", "LimitRanger,ServiceAccount"},
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				DisableAdmissionPlugins: []string{"PodSecurityPolicy"},
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				AdmissionControl: []string{"NamespaceLifecycle", "Bogus"},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::KubeAPIServer.admissionControl[1]",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				DisableAdmissionPlugins: []string{"PodPreset"},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::KubeAPIServer.disableAdmissionPlugins[0]",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				DisableAdmissionPlugins: []string{"PodSecurityPolicy", "DefaultStorageClass,NodeRestriction"},
+			},
+			ExpectedErrors: []string{
+				"Forbidden::KubeAPIServer.disableAdmissionPlugins[1]",
+			},
+			ExpectedDetail: "admission plugin NodeRestriction cannot be disabled: it limits the kubelet credentials issued to bootstrapping nodes to their own Node and Pod objects",
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				DisableAdmissionPlugins: []string{"ServiceAccount"},
+			},
+			ExpectedErrors: []string{
+				"Forbidden::KubeAPIServer.disableAdmissionPlugins[0]",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				DisableAdmissionPlugins: []string{"PodPreset"},
+			},
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					KubernetesVersion: "1.19.0",
+				},
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				AdmissionControl: []string{"DenyEscalatingExec,DenyExecOnPrivileged"},
+			},
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					KubernetesVersion: "1.20.0",
+				},
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				AdmissionControl: []string{"DenyEscalatingExec", "DenyExecOnPrivileged"},
+			},
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					KubernetesVersion: "1.21.0",
+				},
+			},
+			ExpectedErrors: []string{
+				"Invalid value::KubeAPIServer.admissionControl[0]",
+				"Invalid value::KubeAPIServer.admissionControl[1]",
+			},
+		},
 		{
 			Input: kops.KubeAPIServerConfig{
 				ServiceNodePortRange: str,
@@ -272,6 +379,68 @@ func TestValidateKubeAPIServer(t *testing.T) {
 				},
 			},
 		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				AuthorizationMode: fi.String("RBAC"),
+			},
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					Authorization: &kops.AuthorizationSpec{
+						RBAC: &kops.RBACAuthorizationSpec{},
+					},
+					KubernetesVersion: "1.19.0",
+					CloudProvider:     "gce",
+				},
+			},
+			ExpectedErrors: []string{
+				"Required value::KubeAPIServer.authorizationMode",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				AuthorizationMode: fi.String("RBAC"),
+			},
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					Authorization: &kops.AuthorizationSpec{
+						RBAC: &kops.RBACAuthorizationSpec{},
+					},
+					KubernetesVersion: "1.19.0",
+					CloudProvider:     "openstack",
+				},
+			},
+			ExpectedErrors: []string{
+				"Required value::KubeAPIServer.authorizationMode",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				AuthorizationMode: fi.String("RBAC,Node"),
+			},
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					Authorization: &kops.AuthorizationSpec{
+						RBAC: &kops.RBACAuthorizationSpec{},
+					},
+					KubernetesVersion: "1.19.0",
+					CloudProvider:     "gce",
+				},
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				AuthorizationMode: fi.String("RBAC"),
+			},
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					Authorization: &kops.AuthorizationSpec{
+						RBAC: &kops.RBACAuthorizationSpec{},
+					},
+					KubernetesVersion: "1.18.0",
+					CloudProvider:     "gce",
+				},
+			},
+		},
 		{
 			Input: kops.KubeAPIServerConfig{
 				AuthorizationMode: fi.String("RBAC,Node,Bogus"),
@@ -289,6 +458,53 @@ func TestValidateKubeAPIServer(t *testing.T) {
 				"Unsupported value::KubeAPIServer.authorizationMode",
 			},
 		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				OIDCIssuerURL:     fi.String("https://oidc.example.com/realms/kubernetes"),
+				OIDCClientID:      fi.String("kubernetes"),
+				OIDCUsernameClaim: fi.String("preferred_username"),
+				OIDCGroupsClaim:   fi.String("https://example.com/groups"),
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				OIDCIssuerURL: fi.String("http://oidc.example.com"),
+				OIDCClientID:  fi.String("kubernetes"),
+			},
+			ExpectedErrors: []string{
+				"Invalid value::KubeAPIServer.oidcIssuerURL",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				OIDCIssuerURL: fi.String("https://oidc.example.com?realm=kubernetes"),
+				OIDCClientID:  fi.String("kubernetes"),
+			},
+			ExpectedErrors: []string{
+				"Invalid value::KubeAPIServer.oidcIssuerURL",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				OIDCGroupsClaim: fi.String("groups"),
+			},
+			ExpectedErrors: []string{
+				"Required value::KubeAPIServer.oidcIssuerURL",
+				"Required value::KubeAPIServer.oidcClientID",
+			},
+		},
+		{
+			Input: kops.KubeAPIServerConfig{
+				OIDCIssuerURL:     fi.String("https://oidc.example.com"),
+				OIDCClientID:      fi.String("kubernetes"),
+				OIDCUsernameClaim: fi.String("user name"),
+				OIDCGroupsClaim:   fi.String("groups,roles"),
+			},
+			ExpectedErrors: []string{
+				"Invalid value::KubeAPIServer.oidcUsernameClaim",
+				"Invalid value::KubeAPIServer.oidcGroupsClaim",
+			},
+		},
 	}
 	for _, g := range grid {
 		if g.Cluster == nil {
@@ -320,8 +536,66 @@ func TestValidateKubeAPIServer(t *testing.T) {
 	}
 }
 
+func TestValidateFileAssetSpec(t *testing.T) {
+	grid := []struct {
+		Input          kops.FileAssetSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.FileAssetSpec{
+				Name:    "test",
+				Content: "foo",
+			},
+		},
+		{
+			Input: kops.FileAssetSpec{
+				Name:    "test",
+				Path:    "/etc/kubernetes/test",
+				Content: "foo",
+				Mode:    "0644",
+			},
+		},
+		{
+			Input: kops.FileAssetSpec{},
+			ExpectedErrors: []string{
+				"Required value::fileAssets[0].name",
+				"Required value::fileAssets[0].content",
+			},
+		},
+		{
+			Input: kops.FileAssetSpec{
+				Name:    "test",
+				Path:    "etc/kubernetes/test",
+				Content: "foo",
+			},
+			ExpectedErrors: []string{"Invalid value::fileAssets[0].path"},
+		},
+		{
+			Input: kops.FileAssetSpec{
+				Name:    "test",
+				Content: "foo",
+				Mode:    "rw-r--r--",
+			},
+			ExpectedErrors: []string{"Invalid value::fileAssets[0].mode"},
+		},
+		{
+			Input: kops.FileAssetSpec{
+				Name:    "test",
+				Content: "foo",
+				Mode:    "0988",
+			},
+			ExpectedErrors: []string{"Invalid value::fileAssets[0].mode"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateFileAssetSpec(&g.Input, field.NewPath("fileAssets").Index(0))
+
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
 func Test_Validate_DockerConfig_Storage(t *testing.T) {
-	for _, name := range []string{"aufs", "zfs", "overlay"} {
+	for _, name := range []string{"aufs", "zfs", "overlay", "overlay2,aufs"} {
 		config := &kops.DockerConfig{Storage: &name}
 		errs := validateDockerConfig(config, field.NewPath("docker"))
 		if len(errs) != 0 {
@@ -339,6 +613,129 @@ func Test_Validate_DockerConfig_Storage(t *testing.T) {
 			t.Fatalf("Not the expected error validating DockerConfig %q", errs)
 		}
 	}
+
+	for _, name := range []string{"overlay2,overlay2", "aufs,overlay2,aufs", "overlay,overlay2", "overlay2,overlay", "overlay2,overlay,aufs"} {
+		config := &kops.DockerConfig{Storage: &name}
+		errs := validateDockerConfig(config, field.NewPath("docker"))
+		testErrors(t, name, errs, []string{"Invalid value::docker.storage"})
+	}
+}
+
+func Test_Validate_ContainerdVersion(t *testing.T) {
+	grid := []struct {
+		Input          kops.ContainerdConfig
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ContainerdConfig{
+				Version:      fi.String("1.4.6"),
+				VersionAmd64: fi.String("1.4.4"),
+				VersionArm64: fi.String("1.3.10"),
+			},
+		},
+		{
+			Input: kops.ContainerdConfig{
+				Version:      fi.String("1.4.6"),
+				VersionArm64: fi.String("1.2.10"),
+			},
+			ExpectedErrors: []string{"Invalid value::containerd.versionArm64"},
+		},
+		{
+			Input: kops.ContainerdConfig{
+				VersionAmd64: fi.String("latest"),
+			},
+			ExpectedErrors: []string{"Invalid value::containerd.versionAmd64"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateContainerdConfig(&g.Input, field.NewPath("containerd"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_PackageHash(t *testing.T) {
+	grid := []struct {
+		Hash        string
+		ExpectError bool
+	}{
+		{
+			Hash: "5994471abb01112afcc18159f6cc74b4f511b99806da59b3caf5a9c173cacfc5",
+		},
+		{
+			Hash: "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e",
+		},
+		{
+			Hash: "5e37efab5fdf59c1c508780ce5e83a3219c981d9",
+		},
+		{
+			Hash:        "5994471abb01112afcc18159f6cc74b4f511b99806da59b3caf5a9c173cacfc5aa",
+			ExpectError: true,
+		},
+		{
+			Hash:        "5994471abb01112afcc18159f6cc74b4f511b99806da59b3caf5a9c173cacfc51",
+			ExpectError: true,
+		},
+		{
+			Hash:        "z994471abb01112afcc18159f6cc74b4f511b99806da59b3caf5a9c173cacfc5",
+			ExpectError: true,
+		},
+	}
+
+	for _, g := range grid {
+		u := "https://example.com/package.tar.gz"
+		hash := g.Hash
+		containerd := &kops.ContainerdConfig{
+			Packages: &kops.PackagesConfig{
+				UrlAmd64:  &u,
+				HashAmd64: &hash,
+			},
+		}
+		var expected []string
+		if g.ExpectError {
+			expected = []string{"Invalid value::containerd.packageHash"}
+		}
+		testErrors(t, g.Hash, validateContainerdConfig(containerd, field.NewPath("containerd")), expected)
+
+		docker := &kops.DockerConfig{
+			Packages: &kops.PackagesConfig{
+				UrlAmd64:  &u,
+				HashAmd64: &hash,
+			},
+		}
+		expected = nil
+		if g.ExpectError {
+			expected = []string{"Invalid value::docker.packageHash"}
+		}
+		testErrors(t, g.Hash, validateDockerConfig(docker, field.NewPath("docker")), expected)
+	}
+}
+
+func Test_PackageHashWarnings(t *testing.T) {
+	sha1 := "5e37efab5fdf59c1c508780ce5e83a3219c981d9"
+	sha256 := "5994471abb01112afcc18159f6cc74b4f511b99806da59b3caf5a9c173cacfc5"
+	cluster := &kops.Cluster{
+		Spec: kops.ClusterSpec{
+			Containerd: &kops.ContainerdConfig{
+				Packages: &kops.PackagesConfig{HashAmd64: &sha256, HashArm64: &sha1},
+			},
+			Docker: &kops.DockerConfig{
+				Packages: &kops.PackagesConfig{HashAmd64: &sha1},
+			},
+		},
+	}
+
+	expected := []string{
+		"spec.containerd.packages.hashArm64 is 40 characters long; use a 64 character SHA-256 or 128 character SHA-512 hash",
+		"spec.docker.packages.hashAmd64 is 40 characters long; use a 64 character SHA-256 or 128 character SHA-512 hash",
+	}
+	if actual := packageHashWarnings(cluster); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected warnings: expected %q, got %q", expected, actual)
+	}
+
+	if actual := packageHashWarnings(&kops.Cluster{}); actual != nil {
+		t.Errorf("expected no warnings, got %q", actual)
+	}
 }
 
 func Test_Validate_Networking_Flannel(t *testing.T) {
@@ -382,10 +779,126 @@ func Test_Validate_Networking_Flannel(t *testing.T) {
 	}
 }
 
-func Test_Validate_AdditionalPolicies(t *testing.T) {
+func Test_Validate_Networking_IPv6(t *testing.T) {
 	grid := []struct {
-		Input          map[string]string
-		ExpectedErrors []string
+		Description    string
+		Networking     kops.NetworkingSpec
+		Spec           kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "IPv6 subnets with kubenet",
+			Networking: kops.NetworkingSpec{
+				Kubenet: &kops.KubenetNetworkingSpec{},
+			},
+			Spec: kops.ClusterSpec{
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "a", IPv6CIDR: "2001:db8::/64"},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::networking.kubenet"},
+		},
+		{
+			Description: "IPv6-only cluster with kubenet",
+			Networking: kops.NetworkingSpec{
+				Kubenet: &kops.KubenetNetworkingSpec{},
+			},
+			Spec: kops.ClusterSpec{
+				NonMasqueradeCIDR: "fd00:10:96::/64",
+			},
+		},
+		{
+			Description: "IPv6-only cluster with flannel",
+			Networking: kops.NetworkingSpec{
+				Flannel: &kops.FlannelNetworkingSpec{
+					Backend: "vxlan",
+				},
+			},
+			Spec: kops.ClusterSpec{
+				NonMasqueradeCIDR: "fd00:10:96::/64",
+			},
+			ExpectedErrors: []string{"Forbidden::networking.flannel"},
+		},
+		{
+			Description: "IPv6 subnets with flannel",
+			Networking: kops.NetworkingSpec{
+				Flannel: &kops.FlannelNetworkingSpec{
+					Backend: "vxlan",
+				},
+			},
+			Spec: kops.ClusterSpec{
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "a", IPv6CIDR: "2001:db8::/64"},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::networking.flannel"},
+		},
+		{
+			Description: "IPv6 subnets with cilium",
+			Networking: kops.NetworkingSpec{
+				Cilium: &kops.CiliumNetworkingSpec{
+					Version: "v1.10.0",
+				},
+			},
+			Spec: kops.ClusterSpec{
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "a", IPv6CIDR: "2001:db8::/64"},
+				},
+			},
+		},
+		{
+			Description: "IPv6 subnets with old cilium",
+			Networking: kops.NetworkingSpec{
+				Cilium: &kops.CiliumNetworkingSpec{
+					Version: "v1.9.0",
+				},
+			},
+			Spec: kops.ClusterSpec{
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "a", IPv6CIDR: "2001:db8::/64"},
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::networking.cilium.version"},
+		},
+		{
+			Description: "IPv6 subnets with amazonvpc",
+			Networking: kops.NetworkingSpec{
+				AmazonVPC: &kops.AmazonVPCNetworkingSpec{},
+			},
+			Spec: kops.ClusterSpec{
+				CloudProvider: "aws",
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "a", IPv6CIDR: "2001:db8::/64"},
+				},
+			},
+		},
+		{
+			Description: "IPv4 subnets with kubenet",
+			Networking: kops.NetworkingSpec{
+				Kubenet: &kops.KubenetNetworkingSpec{},
+			},
+			Spec: kops.ClusterSpec{
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "a", CIDR: "10.0.0.0/24"},
+				},
+			},
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			Spec: g.Spec,
+		}
+		cluster.Spec.Networking = &g.Networking
+
+		errs := validateNetworking(cluster, cluster.Spec.Networking, field.NewPath("networking"))
+		testErrors(t, g.Description, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_AdditionalPolicies(t *testing.T) {
+	grid := []struct {
+		Input          map[string]string
+		ExpectedErrors []string
 	}{
 		{
 			Input: map[string]string{},
@@ -401,6 +914,25 @@ func Test_Validate_AdditionalPolicies(t *testing.T) {
 			},
 			ExpectedErrors: []string{"Unsupported value::spec.additionalPolicies"},
 		},
+		{
+			Input: map[string]string{
+				"Node": `[ { "Action": [ "s3:GetObject" ], "Resource": [ "*" ], "Effect": "Allow" } ]`,
+				"node": `[ { "Action": [ "s3:GetObject" ], "Resource": [ "*" ], "Effect": "Allow" } ]`,
+			},
+			ExpectedErrors: []string{"Unsupported value::spec.additionalPolicies"},
+		},
+		{
+			Input: map[string]string{
+				"master": ``,
+			},
+			ExpectedErrors: []string{"Required value::spec.additionalPolicies[master]"},
+		},
+		{
+			Input: map[string]string{
+				"node": ` `,
+			},
+			ExpectedErrors: []string{"Required value::spec.additionalPolicies[node]"},
+		},
 		{
 			Input: map[string]string{
 				"master": `badjson`,
@@ -419,6 +951,32 @@ func Test_Validate_AdditionalPolicies(t *testing.T) {
 			},
 			ExpectedErrors: []string{"Unsupported value::spec.additionalPolicies[master][0].Effect"},
 		},
+		{
+			Input: map[string]string{
+				"master": `[ { "NotAction": [ "s3:GetObject" ], "NotResource": [ "*" ], "Effect": "Deny" } ]`,
+			},
+		},
+		{
+			Input: map[string]string{
+				"master": `[ { "Resource": [ "*" ], "Effect": "Allow" } ]`,
+			},
+			ExpectedErrors: []string{"Required value::spec.additionalPolicies[master][0].Action"},
+		},
+		{
+			Input: map[string]string{
+				"master": `[ { "Action": [ "s3:GetObject" ], "Effect": "Allow" } ]`,
+			},
+			ExpectedErrors: []string{"Required value::spec.additionalPolicies[master][0].Resource"},
+		},
+		{
+			Input: map[string]string{
+				"master": `[ { "Action": [ "s3:GetObject" ], "Resource": [ "*" ], "Effect": "Allow" }, { "Effect": "Allow" } ]`,
+			},
+			ExpectedErrors: []string{
+				"Required value::spec.additionalPolicies[master][1].Action",
+				"Required value::spec.additionalPolicies[master][1].Resource",
+			},
+		},
 	}
 	for _, g := range grid {
 		clusterSpec := &kops.ClusterSpec{
@@ -445,11 +1003,216 @@ func Test_Validate_AdditionalPolicies(t *testing.T) {
 	}
 }
 
+func Test_Validate_KopsControllerAdditionalPolicy(t *testing.T) {
+	grid := []struct {
+		Input          string
+		ExpectedErrors []string
+	}{
+		{
+			Input: "",
+		},
+		{
+			Input: `[ { "Action": [ "ec2:DescribeInstances" ], "Resource": [ "*" ], "Effect": "Allow" } ]`,
+		},
+		{
+			Input:          `{ "Action": [ "ec2:DescribeInstances" ], "Resource": [ "*" ], "Effect": "Allow" }`,
+			ExpectedErrors: []string{"Invalid value::spec.iam.kopsControllerAdditionalPolicy"},
+		},
+		{
+			Input: `[ { "Action": [ "ec2:DescribeInstances" ], "Resource": [ "*" ], "Effect": "Allow" }, { "Action": [ "ec2:DescribeTags" ] } ]`,
+			ExpectedErrors: []string{
+				"Required value::spec.iam.kopsControllerAdditionalPolicy[1].Effect",
+				"Required value::spec.iam.kopsControllerAdditionalPolicy[1].Resource",
+			},
+		},
+		{
+			Input:          `[ { "Resource": [ "*" ], "Effect": "Allow" } ]`,
+			ExpectedErrors: []string{"Required value::spec.iam.kopsControllerAdditionalPolicy[0].Action"},
+		},
+	}
+	for _, g := range grid {
+		clusterSpec := &kops.ClusterSpec{
+			KubernetesVersion: "1.17.0",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "subnet1"},
+			},
+			EtcdClusters: []kops.EtcdClusterSpec{
+				{
+					Name: "main",
+					Members: []kops.EtcdMemberSpec{
+						{
+							Name:          "us-test-1a",
+							InstanceGroup: fi.String("master-us-test-1a"),
+						},
+					},
+				},
+			},
+			IAM: &kops.IAMSpec{
+				KopsControllerAdditionalPolicy: g.Input,
+			},
+		}
+		errs := validateClusterSpec(clusterSpec, &kops.Cluster{Spec: *clusterSpec}, field.NewPath("spec"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_CAKeyType(t *testing.T) {
+	grid := []struct {
+		Input          string
+		ExpectedErrors []string
+	}{
+		{
+			Input: "",
+		},
+		{
+			Input: "rsa-2048",
+		},
+		{
+			Input: "rsa-4096",
+		},
+		{
+			Input: "ecdsa-p256",
+		},
+		{
+			Input:          "ecdsa-p384",
+			ExpectedErrors: []string{"Unsupported value::spec.caKeyType"},
+		},
+	}
+	for _, g := range grid {
+		clusterSpec := &kops.ClusterSpec{
+			KubernetesVersion: "1.17.0",
+			CAKeyType:         g.Input,
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "subnet1"},
+			},
+			EtcdClusters: []kops.EtcdClusterSpec{
+				{
+					Name: "main",
+					Members: []kops.EtcdMemberSpec{
+						{
+							Name:          "us-test-1a",
+							InstanceGroup: fi.String("master-us-test-1a"),
+						},
+					},
+				},
+			},
+			IAM: &kops.IAMSpec{},
+		}
+		errs := validateClusterSpec(clusterSpec, &kops.Cluster{Spec: *clusterSpec}, field.NewPath("spec"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
 type caliInput struct {
 	Calico *kops.CalicoNetworkingSpec
 	Etcd   kops.EtcdClusterSpec
 }
 
+func Test_Validate_Networking_Kuberouter(t *testing.T) {
+	grid := []struct {
+		Description    string
+		KubeProxy      *kops.KubeProxyConfig
+		Input          kops.KuberouterNetworkingSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "defaults",
+		},
+		{
+			Description: "kube-proxy disabled",
+			KubeProxy:   &kops.KubeProxyConfig{Enabled: fi.Bool(false)},
+			Input:       kops.KuberouterNetworkingSpec{RunServiceProxy: fi.Bool(true)},
+		},
+		{
+			Description:    "kube-proxy enabled",
+			KubeProxy:      &kops.KubeProxyConfig{Enabled: fi.Bool(true)},
+			ExpectedErrors: []string{"Forbidden::networking.spec.kubeProxy.enabled"},
+		},
+		{
+			Description:    "kube-proxy disabled without service proxy",
+			KubeProxy:      &kops.KubeProxyConfig{Enabled: fi.Bool(false)},
+			Input:          kops.KuberouterNetworkingSpec{RunServiceProxy: fi.Bool(false)},
+			ExpectedErrors: []string{"Required value::networking.kuberouter.runServiceProxy"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			cluster := &kops.Cluster{}
+			cluster.Spec.KubeProxy = g.KubeProxy
+			cluster.Spec.Networking = &kops.NetworkingSpec{
+				Kuberouter: &g.Input,
+			}
+
+			errs := validateNetworking(cluster, cluster.Spec.Networking, field.NewPath("networking"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_Networking_OnlyOneOption(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          kops.NetworkingSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "kubenet",
+			Input: kops.NetworkingSpec{
+				Kubenet: &kops.KubenetNetworkingSpec{},
+			},
+		},
+		{
+			Description: "kubenet and calico",
+			Input: kops.NetworkingSpec{
+				Kubenet: &kops.KubenetNetworkingSpec{},
+				Calico:  &kops.CalicoNetworkingSpec{},
+			},
+			ExpectedErrors: []string{"Forbidden::networking.calico"},
+		},
+		{
+			Description: "cni",
+			Input: kops.NetworkingSpec{
+				CNI: &kops.CNINetworkingSpec{},
+			},
+		},
+		{
+			Description: "cni and weave",
+			Input: kops.NetworkingSpec{
+				CNI:   &kops.CNINetworkingSpec{},
+				Weave: &kops.WeaveNetworkingSpec{},
+			},
+			ExpectedErrors: []string{"Forbidden::networking.weave"},
+		},
+		{
+			Description: "cni and calico",
+			Input: kops.NetworkingSpec{
+				CNI:    &kops.CNINetworkingSpec{},
+				Calico: &kops.CalicoNetworkingSpec{},
+			},
+			ExpectedErrors: []string{"Forbidden::networking.calico"},
+		},
+		{
+			Description: "cni and cilium",
+			Input: kops.NetworkingSpec{
+				CNI:    &kops.CNINetworkingSpec{},
+				Cilium: &kops.CiliumNetworkingSpec{},
+			},
+			ExpectedErrors: []string{"Forbidden::networking.cilium"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			cluster := &kops.Cluster{}
+			cluster.Spec.Networking = &g.Input
+
+			errs := validateNetworking(cluster, cluster.Spec.Networking, field.NewPath("networking"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
 func Test_Validate_Calico(t *testing.T) {
 	grid := []struct {
 		Description    string
@@ -653,87 +1416,169 @@ func Test_Validate_Calico(t *testing.T) {
 			ExpectedErrors: []string{"Unsupported value::calico.encapsulationMode"},
 		},
 		{
-			Description: "unknown Calico IPIP mode",
+			Description: "Calico no encapsulation mode with implicit networking backend",
 			Input: caliInput{
 				Calico: &kops.CalicoNetworkingSpec{
-					IPIPMode: "unknown",
+					EncapsulationMode: "none",
 				},
 				Etcd: kops.EtcdClusterSpec{},
 			},
-			ExpectedErrors: []string{"Unsupported value::calico.ipipMode"},
 		},
-		// You can't use per-IPPool IP-in-IP encapsulation unless you're using the "ipip"
-		// encapsulation mode.
 		{
-			Description: "Calico IPIP encapsulation mode (implicit) with IPIP IPPool mode (always)",
+			Description: "Calico no encapsulation mode with BIRD networking backend",
 			Input: caliInput{
 				Calico: &kops.CalicoNetworkingSpec{
-					IPIPMode: "Always",
+					EncapsulationMode: "none",
+					NetworkingBackend: "bird",
 				},
 				Etcd: kops.EtcdClusterSpec{},
 			},
 		},
 		{
-			Description: "Calico IPIP encapsulation mode (implicit) with IPIP IPPool mode (cross-subnet)",
+			Description: "Calico no encapsulation mode with VXLAN networking backend",
 			Input: caliInput{
 				Calico: &kops.CalicoNetworkingSpec{
-					IPIPMode: "CrossSubnet",
+					EncapsulationMode: "none",
+					NetworkingBackend: "vxlan",
 				},
 				Etcd: kops.EtcdClusterSpec{},
 			},
+			ExpectedErrors: []string{"Forbidden::calico.encapsulationMode"},
 		},
 		{
-			Description: "Calico IPIP encapsulation mode (implicit) with IPIP IPPool mode (never)",
+			Description: "Calico no encapsulation mode without networking backend",
 			Input: caliInput{
 				Calico: &kops.CalicoNetworkingSpec{
-					IPIPMode: "Never",
+					EncapsulationMode: "none",
+					NetworkingBackend: "none",
 				},
 				Etcd: kops.EtcdClusterSpec{},
 			},
+			ExpectedErrors: []string{"Forbidden::calico.encapsulationMode"},
 		},
 		{
-			Description: "Calico IPIP encapsulation mode (explicit) with IPIP IPPool mode (always)",
+			Description: "Calico IPIP encapsulation mode with VXLAN networking backend",
 			Input: caliInput{
 				Calico: &kops.CalicoNetworkingSpec{
 					EncapsulationMode: "ipip",
-					IPIPMode:          "Always",
+					NetworkingBackend: "vxlan",
 				},
 				Etcd: kops.EtcdClusterSpec{},
 			},
+			ExpectedErrors: []string{"Forbidden::calico.networkingBackend"},
 		},
 		{
-			Description: "Calico IPIP encapsulation mode (explicit) with IPIP IPPool mode (cross-subnet)",
+			Description: "Calico implicit IPIP encapsulation mode without networking backend",
 			Input: caliInput{
 				Calico: &kops.CalicoNetworkingSpec{
-					EncapsulationMode: "ipip",
-					IPIPMode:          "CrossSubnet",
+					NetworkingBackend: "none",
 				},
 				Etcd: kops.EtcdClusterSpec{},
 			},
+			ExpectedErrors: []string{"Forbidden::calico.networkingBackend"},
 		},
 		{
-			Description: "Calico IPIP encapsulation mode (explicit) with IPIP IPPool mode (never)",
+			Description: "Calico VXLAN encapsulation mode with VXLAN networking backend",
 			Input: caliInput{
 				Calico: &kops.CalicoNetworkingSpec{
-					EncapsulationMode: "ipip",
-					IPIPMode:          "Never",
+					EncapsulationMode: "vxlan",
+					NetworkingBackend: "vxlan",
 				},
 				Etcd: kops.EtcdClusterSpec{},
 			},
 		},
 		{
-			Description: "Calico VXLAN encapsulation mode with IPIP IPPool mode",
+			Description: "unknown Calico networking backend",
 			Input: caliInput{
 				Calico: &kops.CalicoNetworkingSpec{
-					EncapsulationMode: "vxlan",
-					IPIPMode:          "Always",
+					NetworkingBackend: "unknown",
 				},
 				Etcd: kops.EtcdClusterSpec{},
 			},
-			ExpectedErrors: []string{`Forbidden::calico.ipipMode`},
+			ExpectedErrors: []string{"Unsupported value::calico.networkingBackend"},
 		},
 		{
-			Description: "Calico VXLAN encapsulation mode with IPIP IPPool mode (always)",
+			Description: "unknown Calico IPIP mode",
+			Input: caliInput{
+				Calico: &kops.CalicoNetworkingSpec{
+					IPIPMode: "unknown",
+				},
+				Etcd: kops.EtcdClusterSpec{},
+			},
+			ExpectedErrors: []string{"Unsupported value::calico.ipipMode"},
+		},
+		// You can't use per-IPPool IP-in-IP encapsulation unless you're using the "ipip"
+		// encapsulation mode.
+		{
+			Description: "Calico IPIP encapsulation mode (implicit) with IPIP IPPool mode (always)",
+			Input: caliInput{
+				Calico: &kops.CalicoNetworkingSpec{
+					IPIPMode: "Always",
+				},
+				Etcd: kops.EtcdClusterSpec{},
+			},
+		},
+		{
+			Description: "Calico IPIP encapsulation mode (implicit) with IPIP IPPool mode (cross-subnet)",
+			Input: caliInput{
+				Calico: &kops.CalicoNetworkingSpec{
+					IPIPMode: "CrossSubnet",
+				},
+				Etcd: kops.EtcdClusterSpec{},
+			},
+		},
+		{
+			Description: "Calico IPIP encapsulation mode (implicit) with IPIP IPPool mode (never)",
+			Input: caliInput{
+				Calico: &kops.CalicoNetworkingSpec{
+					IPIPMode: "Never",
+				},
+				Etcd: kops.EtcdClusterSpec{},
+			},
+		},
+		{
+			Description: "Calico IPIP encapsulation mode (explicit) with IPIP IPPool mode (always)",
+			Input: caliInput{
+				Calico: &kops.CalicoNetworkingSpec{
+					EncapsulationMode: "ipip",
+					IPIPMode:          "Always",
+				},
+				Etcd: kops.EtcdClusterSpec{},
+			},
+		},
+		{
+			Description: "Calico IPIP encapsulation mode (explicit) with IPIP IPPool mode (cross-subnet)",
+			Input: caliInput{
+				Calico: &kops.CalicoNetworkingSpec{
+					EncapsulationMode: "ipip",
+					IPIPMode:          "CrossSubnet",
+				},
+				Etcd: kops.EtcdClusterSpec{},
+			},
+		},
+		{
+			Description: "Calico IPIP encapsulation mode (explicit) with IPIP IPPool mode (never)",
+			Input: caliInput{
+				Calico: &kops.CalicoNetworkingSpec{
+					EncapsulationMode: "ipip",
+					IPIPMode:          "Never",
+				},
+				Etcd: kops.EtcdClusterSpec{},
+			},
+		},
+		{
+			Description: "Calico VXLAN encapsulation mode with IPIP IPPool mode",
+			Input: caliInput{
+				Calico: &kops.CalicoNetworkingSpec{
+					EncapsulationMode: "vxlan",
+					IPIPMode:          "Always",
+				},
+				Etcd: kops.EtcdClusterSpec{},
+			},
+			ExpectedErrors: []string{`Forbidden::calico.ipipMode`},
+		},
+		{
+			Description: "Calico VXLAN encapsulation mode with IPIP IPPool mode (always)",
 			Input: caliInput{
 				Calico: &kops.CalicoNetworkingSpec{
 					EncapsulationMode: "vxlan",
@@ -830,6 +1675,52 @@ func Test_Validate_Cilium(t *testing.T) {
 			},
 			ExpectedErrors: []string{"Forbidden::cilium.ipam"},
 		},
+		{
+			Cilium: kops.CiliumNetworkingSpec{
+				Ipam: "eni",
+			},
+			Spec: kops.ClusterSpec{
+				CloudProvider: "aws",
+				AdditionalPolicies: &map[string]string{
+					"node": `[{"Effect": "Deny", "Action": ["ec2:CreateNetworkInterface"], "Resource": ["arn:aws:ec2:*:*:network-interface/*"]}]`,
+				},
+			},
+		},
+		{
+			Cilium: kops.CiliumNetworkingSpec{
+				Ipam: "eni",
+			},
+			Spec: kops.ClusterSpec{
+				CloudProvider: "aws",
+				AdditionalPolicies: &map[string]string{
+					"node": `[{"Effect": "Deny", "Action": ["ec2:*NetworkInterface"], "Resource": "*"}]`,
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::cilium.spec.additionalPolicies[node]"},
+		},
+		{
+			Cilium: kops.CiliumNetworkingSpec{
+				Ipam: "eni",
+			},
+			Spec: kops.ClusterSpec{
+				CloudProvider: "aws",
+				AdditionalPolicies: &map[string]string{
+					"master": `[{"Effect": "Deny", "NotAction": ["s3:*"], "Resource": "*"}]`,
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::cilium.spec.additionalPolicies[master]"},
+		},
+		{
+			Cilium: kops.CiliumNetworkingSpec{
+				Ipam: "eni",
+			},
+			Spec: kops.ClusterSpec{
+				CloudProvider: "aws",
+				AdditionalPolicies: &map[string]string{
+					"node": `[{"Effect": "Deny", "Action": ["ec2:DeleteNetworkInterface"], "Resource": "*", "Condition": {"StringEquals": {"aws:RequestedRegion": "us-east-1"}}}]`,
+				},
+			},
+		},
 		{
 			Cilium: kops.CiliumNetworkingSpec{
 				Version: "v1.0.0",
@@ -867,6 +1758,37 @@ func Test_Validate_Cilium(t *testing.T) {
 				},
 			},
 		},
+		{
+			Cilium: kops.CiliumNetworkingSpec{
+				EtcdManaged: true,
+			},
+			Spec: kops.ClusterSpec{
+				EtcdClusters: []kops.EtcdClusterSpec{
+					{Name: "main", Version: "3.4.13"},
+					{Name: "events", Version: "3.4.13"},
+					{Name: "cilium", Version: "3.4.13"},
+				},
+			},
+		},
+		{
+			Cilium: kops.CiliumNetworkingSpec{
+				EtcdManaged: true,
+			},
+			Spec: kops.ClusterSpec{
+				EtcdClusters: []kops.EtcdClusterSpec{
+					{Name: "main", Version: "3.4.13"},
+					{Name: "events", Version: "3.4.13"},
+					{Name: "cilium", Version: "3.3.10"},
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::cilium.etcdClusters[2].version"},
+		},
+		{
+			Cilium: kops.CiliumNetworkingSpec{
+				EtcdManaged: true,
+			},
+			ExpectedErrors: []string{"Required value::cilium.etcdClusters"},
+		},
 	}
 	for _, g := range grid {
 		g.Spec.Networking = &kops.NetworkingSpec{
@@ -1019,10 +1941,232 @@ func Test_Validate_RollingUpdate(t *testing.T) {
 	}
 }
 
+func Test_RollingUpdateMasterSurgeWarnings(t *testing.T) {
+	group := func(name string, role kops.InstanceGroupRole, rollingUpdate *kops.RollingUpdate) *kops.InstanceGroup {
+		ig := &kops.InstanceGroup{}
+		ig.ObjectMeta.Name = name
+		ig.Spec.Role = role
+		ig.Spec.RollingUpdate = rollingUpdate
+		return ig
+	}
+	groups := []*kops.InstanceGroup{
+		group("master-a", kops.InstanceGroupRoleMaster, nil),
+		group("master-b", kops.InstanceGroupRoleMaster, &kops.RollingUpdate{MaxSurge: intStr(intstr.FromInt(0))}),
+		group("master-c", kops.InstanceGroupRoleMaster, &kops.RollingUpdate{MaxUnavailable: intStr(intstr.FromInt(1))}),
+		group("nodes", kops.InstanceGroupRoleNode, nil),
+	}
+
+	grid := []struct {
+		Input          *kops.RollingUpdate
+		ExpectedGroups []string
+	}{
+		{
+			Input: nil,
+		},
+		{
+			Input: &kops.RollingUpdate{MaxUnavailable: intStr(intstr.FromInt(1))},
+		},
+		{
+			Input: &kops.RollingUpdate{MaxSurge: intStr(intstr.FromInt(0))},
+		},
+		{
+			Input:          &kops.RollingUpdate{MaxSurge: intStr(intstr.FromInt(2))},
+			ExpectedGroups: []string{"master-a", "master-c"},
+		},
+		{
+			Input:          &kops.RollingUpdate{MaxSurge: intStr(intstr.FromString("10%"))},
+			ExpectedGroups: []string{"master-a", "master-c"},
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{}
+		cluster.Spec.RollingUpdate = g.Input
+
+		warnings := rollingUpdateMasterSurgeWarnings(cluster, groups)
+		if len(warnings) != len(g.ExpectedGroups) {
+			t.Errorf("expected %d warnings for %v, got %q", len(g.ExpectedGroups), g.Input, warnings)
+			continue
+		}
+		for i, name := range g.ExpectedGroups {
+			if !strings.Contains(warnings[i], fmt.Sprintf("%q", name)) {
+				t.Errorf("expected warning for instance group %q, got %q", name, warnings[i])
+			}
+		}
+	}
+}
+
+func TestIptablesBackendNFTWarnings(t *testing.T) {
+	grid := []struct {
+		Input           *kops.NetworkingSpec
+		ExpectedWarning string
+	}{
+		{
+			Input: nil,
+		},
+		{
+			Input: &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{IptablesBackend: "Auto"}},
+		},
+		{
+			Input:           &kops.NetworkingSpec{Calico: &kops.CalicoNetworkingSpec{IptablesBackend: "NFT"}},
+			ExpectedWarning: "spec.networking.calico.iptablesBackend",
+		},
+		{
+			Input:           &kops.NetworkingSpec{Canal: &kops.CanalNetworkingSpec{IptablesBackend: "NFT"}},
+			ExpectedWarning: "spec.networking.canal.iptablesBackend",
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{}
+		cluster.Spec.Networking = g.Input
+
+		warnings := iptablesBackendNFTWarnings(cluster)
+		if g.ExpectedWarning == "" {
+			if len(warnings) != 0 {
+				t.Errorf("unexpected warnings for %v: %q", g.Input, warnings)
+			}
+			continue
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0], g.ExpectedWarning) {
+			t.Errorf("expected a warning for %s, got %q", g.ExpectedWarning, warnings)
+		}
+	}
+}
+
+func TestHubbleMetricsWarnings(t *testing.T) {
+	hubble := &kops.HubbleSpec{
+		Enabled: fi.Bool(true),
+		Metrics: []string{"dns", "drop"},
+	}
+
+	grid := []struct {
+		Hubble          *kops.HubbleSpec
+		MetricsServer   *kops.MetricsServerConfig
+		Addons          []kops.AddonSpec
+		ExpectedWarning bool
+	}{
+		{
+			Hubble: nil,
+		},
+		{
+			Hubble: &kops.HubbleSpec{Enabled: fi.Bool(true)},
+		},
+		{
+			Hubble: &kops.HubbleSpec{Enabled: fi.Bool(false), Metrics: []string{"dns"}},
+		},
+		{
+			Hubble:          hubble,
+			ExpectedWarning: true,
+		},
+		{
+			Hubble:          hubble,
+			MetricsServer:   &kops.MetricsServerConfig{Enabled: fi.Bool(false)},
+			ExpectedWarning: true,
+		},
+		{
+			Hubble:        hubble,
+			MetricsServer: &kops.MetricsServerConfig{Enabled: fi.Bool(true)},
+		},
+		{
+			Hubble: hubble,
+			Addons: []kops.AddonSpec{{Manifest: "s3://bucket/addons/prometheus-operator/addon.yaml"}},
+		},
+	}
+	for _, g := range grid {
+		cluster := &kops.Cluster{}
+		cluster.Spec.Networking = &kops.NetworkingSpec{Cilium: &kops.CiliumNetworkingSpec{Hubble: g.Hubble}}
+		cluster.Spec.MetricsServer = g.MetricsServer
+		cluster.Spec.Addons = g.Addons
+
+		warnings := hubbleMetricsWarnings(cluster)
+		if g.ExpectedWarning && len(warnings) != 1 {
+			t.Errorf("expected a warning for %+v, got %q", g, warnings)
+		} else if !g.ExpectedWarning && len(warnings) != 0 {
+			t.Errorf("unexpected warnings for %+v: %q", g, warnings)
+		}
+	}
+}
+
 func intStr(i intstr.IntOrString) *intstr.IntOrString {
 	return &i
 }
 
+func Test_Validate_KubeProxy_IPVSExcludeCIDRs(t *testing.T) {
+	grid := []struct {
+		Input          kops.KubeProxyConfig
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.KubeProxyConfig{
+				ProxyMode:        "ipvs",
+				IPVSExcludeCIDRS: []string{"10.0.0.0/8"},
+			},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				ProxyMode:        "ipvs",
+				IPVSExcludeCIDRS: []string{"10.0.0.0"},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeProxy.ipvsExcludeCidrs[0]"},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				ProxyMode:        "iptables",
+				IPVSExcludeCIDRS: []string{"10.0.0.0/8"},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kubeProxy.ipvsExcludeCidrs"},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				IPVSExcludeCIDRS: []string{"10.0.0.0/8"},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kubeProxy.ipvsExcludeCidrs"},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				ProxyMode: "iptables",
+			},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				Master: "https://api.internal.example.com",
+			},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				Master: "https://127.0.0.1:443",
+			},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				Master: "http://api.internal.example.com",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeProxy.master"},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				Master: "ftp://api.internal.example.com",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeProxy.master"},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				Master: "api.internal.example.com",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeProxy.master"},
+		},
+		{
+			Input: kops.KubeProxyConfig{
+				Master: "https://%zz",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeProxy.master"},
+		},
+	}
+	for _, g := range grid {
+		errs := validateKubeProxy(&g.Input, field.NewPath("spec", "kubeProxy"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
 func Test_Validate_NodeLocalDNS(t *testing.T) {
 	grid := []struct {
 		Input          kops.ClusterSpec
@@ -1100,14 +2244,72 @@ func Test_Validate_NodeLocalDNS(t *testing.T) {
 			},
 			ExpectedErrors: []string{},
 		},
-	}
-
-	for _, g := range grid {
-		errs := validateNodeLocalDNS(&g.Input, field.NewPath("spec"))
-		testErrors(t, g.Input, errs, g.ExpectedErrors)
-	}
-}
-
+		{
+			Input: kops.ClusterSpec{
+				NetworkCIDR:           "172.20.0.0/16",
+				ServiceClusterIPRange: "100.64.0.0/13",
+				KubeDNS: &kops.KubeDNSConfig{
+					Provider: "CoreDNS",
+					ServerIP: "100.64.0.10",
+					NodeLocalDNS: &kops.NodeLocalDNSConfig{
+						Enabled: fi.Bool(true),
+						LocalIP: "169.254.20.10",
+					},
+				},
+			},
+			ExpectedErrors: []string{},
+		},
+		{
+			Input: kops.ClusterSpec{
+				NetworkCIDR:           "172.20.0.0/16",
+				ServiceClusterIPRange: "100.64.0.0/13",
+				KubeDNS: &kops.KubeDNSConfig{
+					Provider: "CoreDNS",
+					ServerIP: "100.64.0.10",
+					NodeLocalDNS: &kops.NodeLocalDNSConfig{
+						Enabled: fi.Bool(true),
+						LocalIP: "172.20.0.10",
+					},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kubeDNS.nodeLocalDNS.localIP"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				NetworkCIDR:           "172.20.0.0/16",
+				ServiceClusterIPRange: "100.64.0.0/13",
+				KubeDNS: &kops.KubeDNSConfig{
+					Provider: "CoreDNS",
+					ServerIP: "100.64.0.10",
+					NodeLocalDNS: &kops.NodeLocalDNSConfig{
+						Enabled: fi.Bool(true),
+						LocalIP: "100.64.0.20",
+					},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kubeDNS.nodeLocalDNS.localIP"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				KubeDNS: &kops.KubeDNSConfig{
+					Provider: "CoreDNS",
+					ServerIP: "10.0.0.10",
+					NodeLocalDNS: &kops.NodeLocalDNSConfig{
+						Enabled: fi.Bool(true),
+						LocalIP: "10.0.0.10",
+					},
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kubeDNS.nodeLocalDNS.localIP"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateNodeLocalDNS(&g.Input, field.NewPath("spec"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
 func Test_Validate_CloudConfiguration(t *testing.T) {
 	grid := []struct {
 		Description    string
@@ -1190,6 +2392,28 @@ func Test_Validate_CloudConfiguration(t *testing.T) {
 					},
 				}},
 		},
+		{
+			Description: "all true, os without block storage",
+			Input: kops.CloudConfiguration{
+				ManageStorageClasses: fi.Bool(true),
+				Openstack:            &kops.OpenstackConfiguration{},
+			},
+		},
+		{
+			Description: "all false, os block storage unset",
+			Input: kops.CloudConfiguration{
+				ManageStorageClasses: fi.Bool(false),
+				Openstack: &kops.OpenstackConfiguration{
+					BlockStorage: &kops.OpenstackBlockStorageConfig{},
+				}},
+		},
+		{
+			Description: "neither, os block storage unset",
+			Input: kops.CloudConfiguration{
+				Openstack: &kops.OpenstackConfiguration{
+					BlockStorage: &kops.OpenstackBlockStorageConfig{},
+				}},
+		},
 	}
 
 	for _, g := range grid {
@@ -1199,6 +2423,11 @@ func Test_Validate_CloudConfiguration(t *testing.T) {
 			testErrors(t, g.Input, errs, g.ExpectedErrors)
 		})
 	}
+
+	t.Run("nil", func(t *testing.T) {
+		errs := validateCloudConfiguration(nil, field.NewPath("cloudConfig"))
+		testErrors(t, nil, errs, nil)
+	})
 }
 
 func TestValidateSAExternalPermissions(t *testing.T) {
@@ -1289,3 +2518,1488 @@ func TestValidateSAExternalPermissions(t *testing.T) {
 	}
 
 }
+
+func Test_Validate_ClusterAutoscaler(t *testing.T) {
+	grid := []struct {
+		Description    string
+		CloudProvider  string
+		Expander       string
+		ExpectedErrors []string
+	}{
+		{
+			Description:   "least-waste",
+			CloudProvider: "aws",
+			Expander:      "least-waste",
+		},
+		{
+			Description:   "priority",
+			CloudProvider: "aws",
+			Expander:      "priority",
+		},
+		{
+			Description:   "price on gce",
+			CloudProvider: "gce",
+			Expander:      "price",
+		},
+		{
+			Description:    "price on aws",
+			CloudProvider:  "aws",
+			Expander:       "price",
+			ExpectedErrors: []string{"Forbidden::spec.clusterAutoscaler.expander"},
+		},
+		{
+			Description:    "invalid expander",
+			CloudProvider:  "aws",
+			Expander:       "bogus",
+			ExpectedErrors: []string{"Unsupported value::spec.clusterAutoscaler.expander"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			cluster := &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					CloudProvider: g.CloudProvider,
+				},
+			}
+			spec := &kops.ClusterAutoscalerConfig{
+				Expander: fi.String(g.Expander),
+			}
+			errs := validateClusterAutoscaler(cluster, spec, field.NewPath("spec", "clusterAutoscaler"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_ContainerRegistryMirrors(t *testing.T) {
+	grid := []struct {
+		Input          map[string]string
+		ExpectedErrors []string
+	}{
+		{
+			Input: map[string]string{
+				"docker.io": "dockerhub.example.com",
+				"quay.io":   "mirror.example.com:5000/quay",
+			},
+		},
+		{
+			Input: map[string]string{
+				"registry.example.com": "mirror.example.com",
+			},
+			ExpectedErrors: []string{"Unsupported value::spec.assets.containerRegistryMirrors"},
+		},
+		{
+			Input: map[string]string{
+				"docker.io": "mirror.example.com/debian:buster",
+			},
+			ExpectedErrors: []string{"Invalid value::spec.assets.containerRegistryMirrors[docker.io]"},
+		},
+		{
+			Input: map[string]string{
+				"quay.io": "mirror.example.com/quay/",
+			},
+		},
+		{
+			Input: map[string]string{
+				"docker.io": "https://mirror.example.com",
+			},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateContainerRegistryMirrors(g.Input, field.NewPath("spec", "assets", "containerRegistryMirrors"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_PreloadImages(t *testing.T) {
+	grid := []struct {
+		Role           string
+		Images         []string
+		ExpectedErrors []string
+	}{
+		{
+			Role:   "node",
+			Images: []string{"quay.io/cilium/cilium:v1.10.0", "busybox"},
+		},
+		{
+			Role:   "master",
+			Images: []string{"busybox@sha256:01ba4719c80b6fe911b091a7c05124b64eeece964e09c058ef8f9805daca546b"},
+		},
+		{
+			Role:           "nodes",
+			Images:         []string{"busybox"},
+			ExpectedErrors: []string{"Unsupported value::spec.preloadImages"},
+		},
+		{
+			Role:           "node",
+			Images:         []string{"busybox", "Quay.io/Cilium/Cilium"},
+			ExpectedErrors: []string{"Invalid value::spec.preloadImages[node][1]"},
+		},
+		{
+			Role:           "node",
+			Images:         []string{"busybox@sha256:zz"},
+			ExpectedErrors: []string{"Invalid value::spec.preloadImages[node][0]"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validatePreloadImages(g.Role, g.Images, field.NewPath("spec", "preloadImages"))
+		testErrors(t, g, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_KopsBinarySpecs(t *testing.T) {
+	const hash = "01ba4719c80b6fe911b091a7c05124b64eeece964e09c058ef8f9805daca546b"
+
+	binaries := []struct {
+		Field    string
+		Validate func(url, hash, urlArm64, hashArm64 string, fldPath *field.Path) field.ErrorList
+	}{
+		{
+			Field: "nodeUp",
+			Validate: func(url, hash, urlArm64, hashArm64 string, fldPath *field.Path) field.ErrorList {
+				return validateNodeUpSpec(&kops.NodeUpSpec{URL: url, Hash: hash, URLArm64: urlArm64, HashArm64: hashArm64}, fldPath)
+			},
+		},
+		{
+			Field: "protokube",
+			Validate: func(url, hash, urlArm64, hashArm64 string, fldPath *field.Path) field.ErrorList {
+				return validateProtokubeSpec(&kops.ProtokubeSpec{URL: url, Hash: hash, URLArm64: urlArm64, HashArm64: hashArm64}, fldPath)
+			},
+		},
+		{
+			Field: "channels",
+			Validate: func(url, hash, urlArm64, hashArm64 string, fldPath *field.Path) field.ErrorList {
+				return validateChannelsSpec(&kops.ChannelsSpec{URL: url, Hash: hash, URLArm64: urlArm64, HashArm64: hashArm64}, fldPath)
+			},
+		},
+	}
+
+	// The expected errors are relative to the field of the binary
+	grid := []struct {
+		URL            string
+		Hash           string
+		URLArm64       string
+		HashArm64      string
+		ExpectedErrors []string
+	}{
+		{},
+		{
+			URL:       "https://example.com/amd64/binary",
+			Hash:      hash,
+			URLArm64:  "https://example.com/arm64/binary",
+			HashArm64: hash,
+		},
+		{
+			URL:            "https://example.com/amd64/binary",
+			ExpectedErrors: []string{"Required value::hash"},
+		},
+		{
+			URLArm64:       "https://example.com/arm64/binary",
+			ExpectedErrors: []string{"Required value::hashArm64"},
+		},
+		{
+			HashArm64:      hash,
+			ExpectedErrors: []string{"Required value::urlArm64"},
+		},
+		{
+			URL:            "https://example.com/%zz/binary",
+			Hash:           hash,
+			ExpectedErrors: []string{"Invalid value::url"},
+		},
+		{
+			URL:            "/srv/binary",
+			Hash:           hash,
+			ExpectedErrors: []string{"Invalid value::url"},
+		},
+		{
+			URL:  "ftp://example.com/amd64/binary",
+			Hash: hash[:40],
+			ExpectedErrors: []string{
+				"Invalid value::url",
+				"Invalid value::hash",
+			},
+		},
+		{
+			URLArm64:       "https://example.com/arm64/binary",
+			HashArm64:      "zz" + hash[2:],
+			ExpectedErrors: []string{"Invalid value::hashArm64"},
+		},
+		{
+			URLArm64:  "binary",
+			HashArm64: "not-hex-" + hash[8:],
+			ExpectedErrors: []string{
+				"Invalid value::urlArm64",
+				"Invalid value::hashArm64",
+			},
+		},
+	}
+
+	for _, binary := range binaries {
+		for _, g := range grid {
+			var expectedErrors []string
+			for _, e := range g.ExpectedErrors {
+				expectedErrors = append(expectedErrors, strings.Replace(e, "::", "::spec."+binary.Field+".", 1))
+			}
+
+			errs := binary.Validate(g.URL, g.Hash, g.URLArm64, g.HashArm64, field.NewPath("spec", binary.Field))
+			testErrors(t, binary.Field+" "+fmt.Sprintf("%+v", g), errs, expectedErrors)
+		}
+	}
+}
+
+func Test_Validate_ClusterNameLength(t *testing.T) {
+	grid := []struct {
+		CloudProvider  kops.CloudProviderID
+		Name           string
+		NetworkID      string
+		ExpectedErrors []string
+	}{
+		{
+			CloudProvider: kops.CloudProviderAWS,
+			Name:          strings.Repeat("a", 44) + ".example.com",
+		},
+		{
+			CloudProvider:  kops.CloudProviderAWS,
+			Name:           strings.Repeat("a", 45) + ".example.com",
+			ExpectedErrors: []string{"Invalid value::objectMeta.name"},
+		},
+		{
+			CloudProvider: kops.CloudProviderGCE,
+			Name:          strings.Repeat("a", 25) + ".example.com",
+		},
+		{
+			CloudProvider:  kops.CloudProviderGCE,
+			Name:           strings.Repeat("a", 26) + ".example.com",
+			ExpectedErrors: []string{"Invalid value::objectMeta.name"},
+		},
+		{
+			CloudProvider: kops.CloudProviderAzure,
+			Name:          strings.Repeat("a", 52) + ".example.com",
+		},
+		{
+			CloudProvider:  kops.CloudProviderAzure,
+			Name:           strings.Repeat("a", 53) + ".example.com",
+			ExpectedErrors: []string{"Invalid value::objectMeta.name"},
+		},
+		{
+			CloudProvider: kops.CloudProviderAzure,
+			Name:          strings.Repeat("a", 64) + ".example.com",
+			NetworkID:     "vnet",
+		},
+		{
+			CloudProvider:  kops.CloudProviderAzure,
+			Name:           strings.Repeat("a", 65) + ".example.com",
+			NetworkID:      "vnet",
+			ExpectedErrors: []string{"Invalid value::objectMeta.name"},
+		},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{}
+		cluster.ObjectMeta.Name = g.Name
+		cluster.Spec.NetworkID = g.NetworkID
+
+		var errs field.ErrorList
+		switch g.CloudProvider {
+		case kops.CloudProviderAWS:
+			errs = awsValidateCluster(cluster)
+		case kops.CloudProviderAzure:
+			errs = azureValidateCluster(cluster)
+		case kops.CloudProviderGCE:
+			errs = gceValidateCluster(cluster)
+		}
+		testErrors(t, g.Name, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_SnapshotController(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				KubernetesVersion: "1.21.0",
+				CertManager: &kops.CertManagerConfig{
+					Enabled: fi.Bool(true),
+				},
+				CloudConfig: &kops.CloudConfiguration{
+					AWSEBSCSIDriver: &kops.AWSEBSCSIDriver{
+						Enabled: fi.Bool(true),
+					},
+				},
+				SnapshotController: &kops.SnapshotControllerConfig{
+					Enabled: fi.Bool(true),
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				KubernetesVersion: "1.21.0",
+				SnapshotController: &kops.SnapshotControllerConfig{
+					Enabled: fi.Bool(false),
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				KubernetesVersion: "1.21.0",
+				CertManager: &kops.CertManagerConfig{
+					Enabled: fi.Bool(true),
+				},
+				SnapshotController: &kops.SnapshotControllerConfig{
+					Enabled: fi.Bool(true),
+				},
+			},
+			ExpectedErrors: []string{"Required value::spec.cloudConfig.awsEBSCSIDriver.enabled"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				KubernetesVersion: "1.21.0",
+				CertManager: &kops.CertManagerConfig{
+					Enabled: fi.Bool(true),
+				},
+				CloudConfig: &kops.CloudConfiguration{
+					AWSEBSCSIDriver: &kops.AWSEBSCSIDriver{
+						Enabled: fi.Bool(false),
+					},
+				},
+				SnapshotController: &kops.SnapshotControllerConfig{
+					Enabled: fi.Bool(true),
+				},
+			},
+			ExpectedErrors: []string{"Required value::spec.cloudConfig.awsEBSCSIDriver.enabled"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				KubernetesVersion: "1.21.0",
+				CloudConfig: &kops.CloudConfiguration{
+					AWSEBSCSIDriver: &kops.AWSEBSCSIDriver{
+						Enabled: fi.Bool(true),
+					},
+				},
+				SnapshotController: &kops.SnapshotControllerConfig{
+					Enabled: fi.Bool(true),
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.snapshotController.enabled"},
+		},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			Spec: g.Input,
+		}
+		errs := validateSnapshotController(cluster, g.Input.SnapshotController, field.NewPath("spec", "snapshotController"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_AWSLoadBalancerController(t *testing.T) {
+	grid := []struct {
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Input: kops.ClusterSpec{
+				CertManager: &kops.CertManagerConfig{
+					Enabled: fi.Bool(true),
+				},
+				AWSLoadBalancerController: &kops.AWSLoadBalancerControllerConfig{
+					Enabled: fi.Bool(true),
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				AWSLoadBalancerController: &kops.AWSLoadBalancerControllerConfig{
+					Enabled: fi.Bool(true),
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.awsLoadBalancerController"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CertManager: &kops.CertManagerConfig{
+					Enabled: fi.Bool(true),
+				},
+				KubeControllerManager: &kops.KubeControllerManagerConfig{
+					Controllers: []string{"*", "-service"},
+				},
+				AWSLoadBalancerController: &kops.AWSLoadBalancerControllerConfig{
+					Enabled: fi.Bool(true),
+				},
+			},
+		},
+		{
+			Input: kops.ClusterSpec{
+				CertManager: &kops.CertManagerConfig{
+					Enabled: fi.Bool(true),
+				},
+				KubeControllerManager: &kops.KubeControllerManagerConfig{
+					Controllers: []string{"*", "service"},
+				},
+				AWSLoadBalancerController: &kops.AWSLoadBalancerControllerConfig{
+					Enabled: fi.Bool(true),
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kubeControllerManager.controllers[1]"},
+		},
+		{
+			Input: kops.ClusterSpec{
+				KubeControllerManager: &kops.KubeControllerManagerConfig{
+					Controllers: []string{"service"},
+				},
+				AWSLoadBalancerController: &kops.AWSLoadBalancerControllerConfig{
+					Enabled: fi.Bool(false),
+				},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			Spec: g.Input,
+		}
+		errs := validateAWSLoadBalancerController(cluster, g.Input.AWSLoadBalancerController, field.NewPath("spec", "awsLoadBalancerController"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_ServiceAccountIssuerDiscovery(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "s3 discovery store",
+			Input: kops.ClusterSpec{
+				ServiceAccountIssuerDiscovery: &kops.ServiceAccountIssuerDiscoveryConfig{
+					DiscoveryStore:        "s3://bucket/discovery",
+					EnableAWSOIDCProvider: true,
+				},
+			},
+		},
+		{
+			Description: "oidc provider without discovery store",
+			Input: kops.ClusterSpec{
+				ServiceAccountIssuerDiscovery: &kops.ServiceAccountIssuerDiscoveryConfig{
+					EnableAWSOIDCProvider: true,
+				},
+			},
+			ExpectedErrors: []string{"Required value::spec.serviceAccountIssuerDiscovery.discoveryStore"},
+		},
+		{
+			Description: "unsupported discovery store",
+			Input: kops.ClusterSpec{
+				ServiceAccountIssuerDiscovery: &kops.ServiceAccountIssuerDiscoveryConfig{
+					DiscoveryStore: "file:///tmp/discovery",
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.serviceAccountIssuerDiscovery.discoveryStore"},
+		},
+		{
+			Description: "invalid discovery store",
+			Input: kops.ClusterSpec{
+				ServiceAccountIssuerDiscovery: &kops.ServiceAccountIssuerDiscoveryConfig{
+					DiscoveryStore: "bucket/discovery",
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.serviceAccountIssuerDiscovery.discoveryStore"},
+		},
+		{
+			Description: "https issuer",
+			Input: kops.ClusterSpec{
+				KubeAPIServer: &kops.KubeAPIServerConfig{
+					ServiceAccountIssuer: fi.String("https://api.internal.minimal.example.com"),
+				},
+			},
+		},
+		{
+			Description: "http issuer",
+			Input: kops.ClusterSpec{
+				KubeAPIServer: &kops.KubeAPIServerConfig{
+					ServiceAccountIssuer: fi.String("http://api.internal.minimal.example.com"),
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeAPIServer.serviceAccountIssuer"},
+		},
+		{
+			Description: "issuer without host",
+			Input: kops.ClusterSpec{
+				KubeAPIServer: &kops.KubeAPIServerConfig{
+					ServiceAccountIssuer: fi.String("api.internal.minimal.example.com"),
+				},
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kubeAPIServer.serviceAccountIssuer"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateServiceAccountIssuerDiscovery(&g.Input, field.NewPath("spec"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_EtcdBackupStore(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          []kops.EtcdClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "default backup stores",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main"},
+				{Name: "events", Backups: &kops.EtcdBackupSpec{}},
+			},
+		},
+		{
+			Description: "distinct backup stores",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Backups: &kops.EtcdBackupSpec{BackupStore: "s3://bucket/backups/main"}},
+				{Name: "events", Backups: &kops.EtcdBackupSpec{BackupStore: "s3://bucket/backups/events"}},
+			},
+		},
+		{
+			Description: "identical backup stores",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Backups: &kops.EtcdBackupSpec{BackupStore: "s3://bucket/backups"}},
+				{Name: "events", Backups: &kops.EtcdBackupSpec{BackupStore: "s3://bucket/backups"}},
+			},
+			ExpectedErrors: []string{"Forbidden::etcdClusters[1].backups.backupStore"},
+		},
+		{
+			Description: "identical backup stores differing by trailing slash",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Backups: &kops.EtcdBackupSpec{BackupStore: "s3://bucket/backups"}},
+				{Name: "events", Backups: &kops.EtcdBackupSpec{BackupStore: "s3://bucket/backups/"}},
+			},
+			ExpectedErrors: []string{"Forbidden::etcdClusters[1].backups.backupStore"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateEtcdBackupStore(g.Input, field.NewPath("etcdClusters"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_Channel(t *testing.T) {
+	grid := []struct {
+		Input          string
+		ExpectedErrors []string
+	}{
+		{
+			Input: "stable",
+		},
+		{
+			Input: "alpha",
+		},
+		{
+			Input: "none",
+		},
+		{
+			Input: "https://example.com/channels/stable",
+		},
+		{
+			Input: "s3://bucket/channels/stable",
+		},
+		{
+			Input:          "stabel",
+			ExpectedErrors: []string{"Unsupported value::spec.channel"},
+		},
+		{
+			Input:          "https://example.com/%zz",
+			ExpectedErrors: []string{"Invalid value::spec.channel"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateChannel(g.Input, field.NewPath("spec", "channel"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_EtcdTLS(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          []kops.EtcdClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "manager with TLS unset",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main"},
+				{Name: "events", Provider: kops.EtcdProviderTypeManager},
+			},
+		},
+		{
+			Description: "manager with TLS enabled",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", EnableEtcdTLS: fi.Bool(true)},
+				{Name: "events", Provider: kops.EtcdProviderTypeManager, EnableEtcdTLS: fi.Bool(true)},
+			},
+		},
+		{
+			Description: "manager with TLS disabled",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", EnableEtcdTLS: fi.Bool(true)},
+				{Name: "events", Provider: kops.EtcdProviderTypeManager, EnableEtcdTLS: fi.Bool(false)},
+			},
+			ExpectedErrors: []string{"Forbidden::etcdClusters[1].enableEtcdTLS"},
+		},
+		{
+			Description: "legacy with TLS disabled",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Provider: kops.EtcdProviderTypeLegacy},
+				{Name: "events", Provider: kops.EtcdProviderTypeLegacy, EnableEtcdTLS: fi.Bool(false)},
+			},
+		},
+		{
+			Description: "legacy with TLS enabled",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Provider: kops.EtcdProviderTypeLegacy, EnableEtcdTLS: fi.Bool(true)},
+				{Name: "events", Provider: kops.EtcdProviderTypeLegacy, EnableEtcdTLS: fi.Bool(true)},
+			},
+		},
+		{
+			Description: "legacy with TLS partially enabled",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Provider: kops.EtcdProviderTypeLegacy, EnableEtcdTLS: fi.Bool(true)},
+				{Name: "events", Provider: kops.EtcdProviderTypeLegacy},
+			},
+			ExpectedErrors: []string{"Forbidden::etcdClusters[0].enableEtcdTLS"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateEtcdTLS(g.Input, field.NewPath("etcdClusters"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_HookSpec(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          kops.HookSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "service directives",
+			Input: kops.HookSpec{
+				Manifest: "Type=oneshot\n# stop the service\nExecStart=/usr/bin/systemctl \\\n  stop update-engine.service\n",
+			},
+		},
+		{
+			Description: "raw manifest",
+			Input: kops.HookSpec{
+				Manifest:       "[Unit]\nDescription=Restore iptables rules\n[Service]\nType=oneshot\n",
+				UseRawManifest: true,
+			},
+		},
+		{
+			Description: "unit without useRawManifest",
+			Input: kops.HookSpec{
+				Manifest: "[Unit]\nDescription=Restore iptables rules\n[Service]\nType=oneshot\n",
+			},
+			ExpectedErrors: []string{"Invalid value::hooks[0].manifest"},
+		},
+		{
+			Description: "service header",
+			Input: kops.HookSpec{
+				Manifest: "[Service]\nType=oneshot\nExecStart=/usr/bin/systemctl stop update-engine.service\n",
+			},
+			ExpectedErrors: []string{"Invalid value::hooks[0].manifest"},
+		},
+		{
+			Description: "install section",
+			Input: kops.HookSpec{
+				Manifest: "Type=oneshot\nExecStart=/usr/bin/systemctl stop update-engine.service\n\n[Install]\nWantedBy=multi-user.target\n",
+			},
+		},
+		{
+			Description: "not a directive in install section",
+			Input: kops.HookSpec{
+				Manifest: "Type=oneshot\n[Install]\nmulti-user.target\n",
+			},
+			ExpectedErrors: []string{"Invalid value::hooks[0].manifest"},
+		},
+		{
+			Description: "not a directive",
+			Input: kops.HookSpec{
+				Manifest: "Type=oneshot\n/usr/bin/systemctl stop update-engine.service\n",
+			},
+			ExpectedErrors: []string{"Invalid value::hooks[0].manifest"},
+		},
+		{
+			Description: "missing key",
+			Input: kops.HookSpec{
+				Manifest: "=oneshot\n",
+			},
+			ExpectedErrors: []string{"Invalid value::hooks[0].manifest"},
+		},
+		{
+			Description: "disabled",
+			Input: kops.HookSpec{
+				Manifest: "not a directive",
+				Disabled: true,
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateHookSpec(&g.Input, field.NewPath("hooks").Index(0))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_DisableSSHAccess(t *testing.T) {
+	grid := []struct {
+		Description    string
+		CloudProvider  kops.CloudProviderID
+		SSHKeyName     *string
+		Bastion        *kops.BastionSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description:   "aws",
+			CloudProvider: kops.CloudProviderAWS,
+		},
+		{
+			Description:   "aws with empty ssh key name",
+			CloudProvider: kops.CloudProviderAWS,
+			SSHKeyName:    fi.String(""),
+		},
+		{
+			Description:    "aws with ssh key name",
+			CloudProvider:  kops.CloudProviderAWS,
+			SSHKeyName:     fi.String("admin"),
+			ExpectedErrors: []string{"Forbidden::spec.sshKeyName"},
+		},
+		{
+			Description:    "aws with bastion",
+			CloudProvider:  kops.CloudProviderAWS,
+			Bastion:        &kops.BastionSpec{},
+			ExpectedErrors: []string{"Forbidden::spec.topology.bastion"},
+		},
+		{
+			Description:    "gce",
+			CloudProvider:  kops.CloudProviderGCE,
+			ExpectedErrors: []string{"Forbidden::spec.disableSSHAccess"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			spec := &kops.ClusterSpec{
+				CloudProvider:    string(g.CloudProvider),
+				DisableSSHAccess: true,
+				SSHKeyName:       g.SSHKeyName,
+				Topology: &kops.TopologySpec{
+					Bastion: g.Bastion,
+				},
+			}
+			errs := validateDisableSSHAccess(spec, field.NewPath("spec"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_EtcdMemberTopology(t *testing.T) {
+	members := func(instanceGroups ...string) []kops.EtcdMemberSpec {
+		var members []kops.EtcdMemberSpec
+		for _, ig := range instanceGroups {
+			members = append(members, kops.EtcdMemberSpec{Name: ig, InstanceGroup: fi.String("master-" + ig)})
+		}
+		return members
+	}
+
+	grid := []struct {
+		Description    string
+		Input          []kops.EtcdClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "single etcd cluster",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Members: members("a")},
+			},
+		},
+		{
+			Description: "matching topologies",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Members: members("a", "b", "c")},
+				{Name: "events", Members: members("c", "b", "a")},
+			},
+		},
+		{
+			Description: "different member counts",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Members: members("a", "b", "c")},
+				{Name: "events", Members: members("a")},
+			},
+			ExpectedErrors: []string{"Invalid value::etcdClusters[1].etcdMembers"},
+		},
+		{
+			Description: "different instance groups",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Members: members("a", "b", "c")},
+				{Name: "events", Members: members("a", "b", "c")},
+				{Name: "cilium", Members: members("a", "b", "d")},
+			},
+			ExpectedErrors: []string{"Invalid value::etcdClusters[2].etcdMembers"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateEtcdMemberTopology(g.Input, field.NewPath("etcdClusters"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_EtcdStorage(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          []kops.EtcdClusterSpec
+		Networking     *kops.NetworkingSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "same versions",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Version: "3.4.13"},
+				{Name: "events", Version: "3.4.13"},
+			},
+		},
+		{
+			Description: "events with a different version",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Version: "3.4.13"},
+				{Name: "events", Version: "3.4.3"},
+			},
+		},
+		{
+			Description: "events listed first with a different version",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "events", Version: "3.4.3"},
+				{Name: "main", Version: "3.4.13"},
+			},
+		},
+		{
+			Description: "other cluster with a different version",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Version: "3.4.13"},
+				{Name: "events", Version: "3.4.3"},
+				{Name: "cilium", Version: "3.4.3"},
+			},
+			ExpectedErrors: []string{"Forbidden::etcdClusters[2].version"},
+		},
+		{
+			// validateNetworkingCilium reports the cilium-specific error instead
+			Description: "cilium cluster managed by Cilium with a different version",
+			Input: []kops.EtcdClusterSpec{
+				{Name: "main", Version: "3.4.13"},
+				{Name: "events", Version: "3.4.13"},
+				{Name: "cilium", Version: "3.4.3"},
+			},
+			Networking: &kops.NetworkingSpec{
+				Cilium: &kops.CiliumNetworkingSpec{EtcdManaged: true},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			spec := &kops.ClusterSpec{
+				EtcdClusters: g.Input,
+				Networking:   g.Networking,
+			}
+			errs := validateEtcdStorage(spec, field.NewPath("etcdClusters"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_EtcdManagerImage(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          *kops.EtcdManagerSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "no manager",
+		},
+		{
+			Description: "default image",
+			Input:       &kops.EtcdManagerSpec{},
+		},
+		{
+			Description: "image with tag",
+			Input: &kops.EtcdManagerSpec{
+				Image: "registry.example.com/etcd-manager:v3.0.20210707",
+			},
+		},
+		{
+			Description: "image with digest",
+			Input: &kops.EtcdManagerSpec{
+				Image: "registry.example.com/etcd-manager@sha256:8d00d2d3ef2d3aa1e2c7c2c1ab5c1fe0e8ea4a7cf0acd7ef6f0ed1b0b35ba27f",
+			},
+		},
+		{
+			Description: "invalid image",
+			Input: &kops.EtcdManagerSpec{
+				Image: "registry.example.com/Etcd-Manager:v3.0.20210707",
+			},
+			ExpectedErrors: []string{"Invalid value::etcdClusters[0].manager.image"},
+		},
+		{
+			Description: "invalid tag",
+			Input: &kops.EtcdManagerSpec{
+				Image: "registry.example.com/etcd-manager:v3.0.20210707:latest",
+			},
+			ExpectedErrors: []string{"Invalid value::etcdClusters[0].manager.image"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			spec := kops.EtcdClusterSpec{
+				Name:    "main",
+				Manager: g.Input,
+				Members: []kops.EtcdMemberSpec{
+					{Name: "a", InstanceGroup: fi.String("master-a")},
+				},
+			}
+			errs := validateEtcdClusterSpec(spec, &kops.Cluster{}, field.NewPath("etcdClusters").Index(0))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_EtcdMemberVolume(t *testing.T) {
+	grid := []struct {
+		Description     string
+		CloudProvider   kops.CloudProviderID
+		VolumeSize      *int32
+		VolumeType      *string
+		ExpectedErrors  []string
+		ExpectedWarning bool
+	}{
+		{
+			Description:   "defaults",
+			CloudProvider: kops.CloudProviderAWS,
+		},
+		{
+			Description:   "recommended size",
+			CloudProvider: kops.CloudProviderAWS,
+			VolumeSize:    fi.Int32(20),
+		},
+		{
+			Description:     "below recommended size",
+			CloudProvider:   kops.CloudProviderAWS,
+			VolumeSize:      fi.Int32(10),
+			ExpectedWarning: true,
+		},
+		{
+			Description:    "below minimum size",
+			CloudProvider:  kops.CloudProviderAWS,
+			VolumeSize:     fi.Int32(1),
+			ExpectedErrors: []string{"Invalid value::etcdClusters[0].etcdMembers[0].volumeSize"},
+		},
+		{
+			Description:   "aws volume type",
+			CloudProvider: kops.CloudProviderAWS,
+			VolumeType:    fi.String("gp3"),
+		},
+		{
+			Description:    "gce volume type on aws",
+			CloudProvider:  kops.CloudProviderAWS,
+			VolumeType:     fi.String("pd-ssd"),
+			ExpectedErrors: []string{"Unsupported value::etcdClusters[0].etcdMembers[0].volumeType"},
+		},
+		{
+			Description:    "hdd volume type on aws",
+			CloudProvider:  kops.CloudProviderAWS,
+			VolumeType:     fi.String("st1"),
+			ExpectedErrors: []string{"Unsupported value::etcdClusters[0].etcdMembers[0].volumeType"},
+		},
+		{
+			Description:   "gce volume type",
+			CloudProvider: kops.CloudProviderGCE,
+			VolumeType:    fi.String("pd-ssd"),
+		},
+		{
+			Description:   "openstack volume type",
+			CloudProvider: kops.CloudProviderOpenstack,
+			VolumeType:    fi.String("test"),
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			spec := kops.EtcdClusterSpec{
+				Name: "main",
+				Members: []kops.EtcdMemberSpec{
+					{
+						Name:          "a",
+						InstanceGroup: fi.String("master-a"),
+						VolumeSize:    g.VolumeSize,
+						VolumeType:    g.VolumeType,
+					},
+				},
+			}
+			cluster := &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					CloudProvider: string(g.CloudProvider),
+					EtcdClusters:  []kops.EtcdClusterSpec{spec},
+				},
+			}
+			errs := validateEtcdClusterSpec(spec, cluster, field.NewPath("etcdClusters").Index(0))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+
+			warnings := etcdVolumeSizeWarnings(cluster)
+			if g.ExpectedWarning && len(warnings) != 1 {
+				t.Errorf("expected a warning, got %q", warnings)
+			} else if !g.ExpectedWarning && len(warnings) != 0 {
+				t.Errorf("unexpected warnings: %q", warnings)
+			}
+		})
+	}
+}
+
+func Test_Validate_NodeTerminationHandler(t *testing.T) {
+	grid := []struct {
+		Description    string
+		CloudProvider  string
+		IAM            *kops.IAMSpec
+		Input          kops.NodeTerminationHandlerConfig
+		ExpectedErrors []string
+	}{
+		{
+			Description: "IMDS mode",
+			Input: kops.NodeTerminationHandlerConfig{
+				Enabled: fi.Bool(true),
+			},
+		},
+		{
+			Description: "queue-processor mode",
+			Input: kops.NodeTerminationHandlerConfig{
+				Enabled:                      fi.Bool(true),
+				EnableSQSTerminationDraining: fi.Bool(true),
+			},
+		},
+		{
+			// The SQS permissions are only added to the policies kOps builds, and legacy IAM is rejected for every cluster
+			Description: "queue-processor mode with legacy IAM",
+			IAM:         &kops.IAMSpec{Legacy: true},
+			Input: kops.NodeTerminationHandlerConfig{
+				Enabled:                      fi.Bool(true),
+				EnableSQSTerminationDraining: fi.Bool(true),
+			},
+			ExpectedErrors: []string{"Forbidden::spec.iam.legacy"},
+		},
+		{
+			Description:   "not AWS",
+			CloudProvider: "gce",
+			Input: kops.NodeTerminationHandlerConfig{
+				Enabled: fi.Bool(true),
+			},
+			ExpectedErrors: []string{"Forbidden::spec.nodeTerminationHandler"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			clusterSpec := &kops.ClusterSpec{
+				CloudProvider:     "aws",
+				KubernetesVersion: "1.21.0",
+				Subnets: []kops.ClusterSubnetSpec{
+					{Name: "subnet1"},
+				},
+				EtcdClusters: []kops.EtcdClusterSpec{
+					{
+						Name: "main",
+						Members: []kops.EtcdMemberSpec{
+							{
+								Name:          "us-test-1a",
+								InstanceGroup: fi.String("master-us-test-1a"),
+							},
+						},
+					},
+				},
+				IAM:                    &kops.IAMSpec{},
+				NodeTerminationHandler: &g.Input,
+			}
+			if g.CloudProvider != "" {
+				clusterSpec.CloudProvider = g.CloudProvider
+			}
+			if g.IAM != nil {
+				clusterSpec.IAM = g.IAM
+			}
+			errs := validateClusterSpec(clusterSpec, &kops.Cluster{Spec: *clusterSpec}, field.NewPath("spec"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_KopsControllerPort(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          kops.ClusterSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "valid port",
+			Input: kops.ClusterSpec{
+				KopsControllerPort: 3000,
+			},
+		},
+		{
+			Description: "privileged port",
+			Input: kops.ClusterSpec{
+				KopsControllerPort: 443,
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kopsControllerPort"},
+		},
+		{
+			Description: "port out of range",
+			Input: kops.ClusterSpec{
+				KopsControllerPort: 65536,
+			},
+			ExpectedErrors: []string{"Invalid value::spec.kopsControllerPort"},
+		},
+		{
+			Description: "same as apiserver securePort",
+			Input: kops.ClusterSpec{
+				KopsControllerPort: 8443,
+				KubeAPIServer: &kops.KubeAPIServerConfig{
+					SecurePort: 8443,
+				},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kopsControllerPort"},
+		},
+		{
+			Description: "same as etcd client port",
+			Input: kops.ClusterSpec{
+				KopsControllerPort: 4001,
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kopsControllerPort"},
+		},
+		{
+			Description: "same as etcd-manager grpc port",
+			Input: kops.ClusterSpec{
+				KopsControllerPort: 3996,
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kopsControllerPort"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateKopsControllerPort(&g.Input, field.NewPath("spec", "kopsControllerPort"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_KubeletVolumePluginDirectory(t *testing.T) {
+	grid := []struct {
+		Input          string
+		ExpectedErrors []string
+	}{
+		{
+			Input: "",
+		},
+		{
+			// The default set for Flatcar
+			Input: "/var/lib/kubelet/volumeplugins/",
+		},
+		{
+			Input:          "var/lib/kubelet/volumeplugins",
+			ExpectedErrors: []string{"Invalid value::spec.kubelet.volumePluginDirectory"},
+		},
+		{
+			Input:          "./volumeplugins",
+			ExpectedErrors: []string{"Invalid value::spec.kubelet.volumePluginDirectory"},
+		},
+	}
+
+	for _, g := range grid {
+		cluster := &kops.Cluster{
+			Spec: kops.ClusterSpec{
+				KubernetesVersion: "1.20.0",
+			},
+		}
+		kubelet := &kops.KubeletConfigSpec{
+			VolumePluginDirectory: g.Input,
+		}
+		errs := validateKubelet(kubelet, cluster, field.NewPath("spec", "kubelet"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
+
+func Test_Validate_ExternalCloudControllerKubelet(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Input          *kops.KubeletConfigSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "no kubelet config",
+		},
+		{
+			Description: "cloud provider unset",
+			Input:       &kops.KubeletConfigSpec{},
+		},
+		{
+			Description: "external cloud provider",
+			Input: &kops.KubeletConfigSpec{
+				CloudProvider: "external",
+			},
+		},
+		{
+			Description: "in-tree cloud provider",
+			Input: &kops.KubeletConfigSpec{
+				CloudProvider: "openstack",
+			},
+			ExpectedErrors: []string{"Forbidden::spec.kubelet.cloudProvider"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateExternalCloudControllerKubelet(g.Input, field.NewPath("spec", "kubelet"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_MasterKubeletMatchesKubelet(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Kubelet        kops.KubeletConfigSpec
+		MasterKubelet  kops.KubeletConfigSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "unset",
+		},
+		{
+			Description:   "only set on masters",
+			MasterKubelet: kops.KubeletConfigSpec{CgroupDriver: "systemd"},
+		},
+		{
+			Description:   "matching",
+			Kubelet:       kops.KubeletConfigSpec{CgroupDriver: "systemd", ClusterDomain: "cluster.local"},
+			MasterKubelet: kops.KubeletConfigSpec{CgroupDriver: "systemd", ClusterDomain: "cluster.local"},
+		},
+		{
+			Description:    "conflicting cgroup driver",
+			Kubelet:        kops.KubeletConfigSpec{CgroupDriver: "cgroupfs"},
+			MasterKubelet:  kops.KubeletConfigSpec{CgroupDriver: "systemd"},
+			ExpectedErrors: []string{"Forbidden::spec.masterKubelet.cgroupDriver"},
+		},
+		{
+			Description:    "conflicting cluster domain and non-masquerade CIDR",
+			Kubelet:        kops.KubeletConfigSpec{ClusterDomain: "cluster.local", NonMasqueradeCIDR: "100.64.0.0/10"},
+			MasterKubelet:  kops.KubeletConfigSpec{ClusterDomain: "example.local", NonMasqueradeCIDR: "10.0.0.0/8"},
+			ExpectedErrors: []string{"Forbidden::spec.masterKubelet.clusterDomain", "Forbidden::spec.masterKubelet.nonMasqueradeCIDR"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validateMasterKubeletMatchesKubelet(&g.Kubelet, &g.MasterKubelet, field.NewPath("spec", "masterKubelet"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_Topology_Bastion(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Topology       kops.TopologySpec
+		Subnets        []kops.ClusterSubnetSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "private topology with utility subnet",
+			Topology: kops.TopologySpec{
+				Masters: kops.TopologyPrivate,
+				Nodes:   kops.TopologyPrivate,
+				Bastion: &kops.BastionSpec{},
+			},
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Type: kops.SubnetTypePrivate},
+				{Name: "utility-us-test-1a", Type: kops.SubnetTypeUtility},
+			},
+		},
+		{
+			Description: "private topology without utility subnet",
+			Topology: kops.TopologySpec{
+				Masters: kops.TopologyPrivate,
+				Nodes:   kops.TopologyPrivate,
+				Bastion: &kops.BastionSpec{},
+			},
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Type: kops.SubnetTypePrivate},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.topology.bastion"},
+		},
+		{
+			Description: "public topology",
+			Topology: kops.TopologySpec{
+				Masters: kops.TopologyPublic,
+				Nodes:   kops.TopologyPublic,
+				Bastion: &kops.BastionSpec{},
+			},
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Type: kops.SubnetTypePublic},
+				{Name: "utility-us-test-1a", Type: kops.SubnetTypeUtility},
+			},
+			ExpectedErrors: []string{"Forbidden::spec.topology.bastion"},
+		},
+		{
+			Description: "no bastion without utility subnet",
+			Topology: kops.TopologySpec{
+				Masters: kops.TopologyPrivate,
+				Nodes:   kops.TopologyPrivate,
+			},
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Type: kops.SubnetTypePrivate},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			spec := &kops.ClusterSpec{
+				Topology: &g.Topology,
+				Subnets:  g.Subnets,
+			}
+			errs := validateTopology(spec, spec.Topology, field.NewPath("spec", "topology"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+		})
+	}
+}
+
+func Test_Validate_PrivateSubnetEgress(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Subnets        []kops.ClusterSubnetSpec
+		ExpectedErrors []string
+	}{
+		{
+			Description: "NAT gateway created in utility subnet",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Zone: "us-test-1a", Type: kops.SubnetTypePrivate},
+				{Name: "utility-us-test-1a", Zone: "us-test-1a", Type: kops.SubnetTypeUtility},
+			},
+		},
+		{
+			Description: "explicit egress",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Zone: "us-test-1a", Type: kops.SubnetTypePrivate, Egress: "nat-123456"},
+				{Name: "us-test-1b", Zone: "us-test-1b", Type: kops.SubnetTypePrivate, Egress: "tgw-123456"},
+				{Name: "us-test-1c", Zone: "us-test-1c", Type: kops.SubnetTypePrivate, Egress: kops.EgressExternal},
+			},
+		},
+		{
+			Description: "no egress",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Zone: "us-test-1a", Type: kops.SubnetTypePrivate},
+			},
+			ExpectedErrors: []string{"Required value::spec.subnets[0].egress"},
+		},
+		{
+			Description: "utility subnet in another zone",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "utility-us-test-1a", Zone: "us-test-1a", Type: kops.SubnetTypeUtility},
+				{Name: "us-test-1a", Zone: "us-test-1a", Type: kops.SubnetTypePrivate},
+				{Name: "us-test-1b", Zone: "us-test-1b", Type: kops.SubnetTypePrivate},
+			},
+			ExpectedErrors: []string{"Required value::spec.subnets[2].egress"},
+		},
+		{
+			Description: "public subnet",
+			Subnets: []kops.ClusterSubnetSpec{
+				{Name: "us-test-1a", Zone: "us-test-1a", Type: kops.SubnetTypePublic},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			errs := validatePrivateSubnetEgress(g.Subnets, field.NewPath("spec", "subnets"))
+			testErrors(t, g.Description, errs, g.ExpectedErrors)
+			if len(g.ExpectedErrors) != len(errs) {
+				t.Errorf("expected errors %v, got %v", g.ExpectedErrors, errs)
+			}
+		})
+	}
+}
+
+func Test_Validate_ContainerRegistry(t *testing.T) {
+	grid := []struct {
+		Input          string
+		ExpectedErrors []string
+	}{
+		{
+			Input: "registry.example.com",
+		},
+		{
+			Input: "registry.example.com:5000",
+		},
+		{
+			Input: "registry.example.com:5000/kops/mirror",
+		},
+		{
+			Input: "localhost:5000",
+		},
+		{
+			Input:          "registry.example.com/kube-apiserver:v1.21.0",
+			ExpectedErrors: []string{"Invalid value::spec.assets.containerRegistry"},
+		},
+		{
+			Input:          "registry.example.com/kube-apiserver@sha256:01ba4719c80b6fe911b091a7c05124b64eeece964e09c058ef8f9805daca546b",
+			ExpectedErrors: []string{"Invalid value::spec.assets.containerRegistry"},
+		},
+		{
+			Input:          "registry.example.com:latest",
+			ExpectedErrors: []string{"Invalid value::spec.assets.containerRegistry"},
+		},
+		{
+			Input: "https://registry.example.com",
+		},
+		{
+			Input: "http://registry.example.com:5000/kops",
+		},
+		{
+			Input:          "ftp://registry.example.com",
+			ExpectedErrors: []string{"Invalid value::spec.assets.containerRegistry"},
+		},
+		{
+			Input:          "",
+			ExpectedErrors: []string{"Invalid value::spec.assets.containerRegistry"},
+		},
+	}
+
+	for _, g := range grid {
+		errs := validateContainerRegistry(g.Input, field.NewPath("spec", "assets", "containerRegistry"))
+		testErrors(t, g.Input, errs, g.ExpectedErrors)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"github.com/blang/semver/v4"
+)
+
+// minimumVersions records the minimum versions of components accepted by validation.
+var minimumVersions = map[string]semver.Version{
+	// Cilium is only supported from 1.8
+	"cilium": semver.MustParse("1.8.0"),
+	// Cilium only supports IPv6 from 1.10
+	"ciliumIPv6": semver.MustParse("1.10.0"),
+	// Older containerd versions are unsupported legacy versions
+	"containerd": semver.MustParse("1.3.4"),
+	// Older docker versions are unsupported legacy versions
+	"docker": semver.MustParse("17.3.0"),
+	// Older docker versions are no longer available: https://www.docker.com/blog/changes-dockerproject-org-apt-yum-repositories
+	"dockerAvailable": semver.MustParse("1.14.0"),
+	// Only etcd v3 is supported
+	"etcd": semver.MustParse("3.0.0"),
+}
+
+// MinimumVersions returns the minimum versions of components accepted by validation, keyed by component.
+func MinimumVersions() map[string]semver.Version {
+	versions := make(map[string]semver.Version, len(minimumVersions))
+	for k, v := range minimumVersions {
+		versions[k] = v
+	}
+	return versions
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestMinimumVersions(t *testing.T) {
+	expected := map[string]string{
+		"cilium":          "1.8.0",
+		"ciliumIPv6":      "1.10.0",
+		"containerd":      "1.3.4",
+		"docker":          "17.3.0",
+		"dockerAvailable": "1.14.0",
+		"etcd":            "3.0.0",
+	}
+
+	versions := MinimumVersions()
+	if len(versions) != len(expected) {
+		t.Errorf("unexpected number of minimum versions: expected %d, got %d", len(expected), len(versions))
+	}
+	for component, version := range expected {
+		if actual, found := versions[component]; !found {
+			t.Errorf("no minimum version for %s", component)
+		} else if actual.String() != version {
+			t.Errorf("unexpected minimum version for %s: expected %s, got %s", component, version, actual)
+		}
+	}
+
+	// The returned map must be a copy
+	versions["containerd"] = semver.MustParse("0.0.1")
+	if minimumVersions["containerd"].String() != expected["containerd"] {
+		t.Errorf("modifying the returned minimum versions changed the minimum containerd version")
+	}
+}
+
+func TestMinimumVersionsEnforced(t *testing.T) {
+	grid := []struct {
+		Errors         func(version string) field.ErrorList
+		Component      string
+		ExpectedErrors []string
+	}{
+		{
+			Component: "containerd",
+			Errors: func(version string) field.ErrorList {
+				return validateContainerdConfig(&kops.ContainerdConfig{Version: fi.String(version)}, field.NewPath("containerd"))
+			},
+			ExpectedErrors: []string{"Invalid value::containerd.version"},
+		},
+		{
+			Component: "docker",
+			Errors: func(version string) field.ErrorList {
+				return validateDockerConfig(&kops.DockerConfig{Version: fi.String(version)}, field.NewPath("docker"))
+			},
+			ExpectedErrors: []string{"Invalid value::docker.version"},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Component, func(t *testing.T) {
+			minimum := minimumVersions[g.Component]
+			testErrors(t, minimum.String(), g.Errors(minimum.String()), nil)
+
+			below := minimum
+			below.Patch = 0
+			below.Minor--
+			testErrors(t, below.String(), g.Errors(below.String()), g.ExpectedErrors)
+		})
+	}
+}
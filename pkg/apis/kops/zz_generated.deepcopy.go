@@ -201,6 +201,13 @@ func (in *Assets) DeepCopyInto(out *Assets) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ContainerRegistryMirrors != nil {
+		in, out := &in.ContainerRegistryMirrors, &out.ContainerRegistryMirrors
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -553,6 +560,22 @@ func (in *ChannelSpec) DeepCopy() *ChannelSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChannelsSpec) DeepCopyInto(out *ChannelsSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChannelsSpec.
+func (in *ChannelsSpec) DeepCopy() *ChannelsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ChannelsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CiliumNetworkingSpec) DeepCopyInto(out *CiliumNetworkingSpec) {
 	*out = *in
@@ -1173,6 +1196,36 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		*out = new(Assets)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeUp != nil {
+		in, out := &in.NodeUp, &out.NodeUp
+		*out = new(NodeUpSpec)
+		**out = **in
+	}
+	if in.Protokube != nil {
+		in, out := &in.Protokube, &out.Protokube
+		*out = new(ProtokubeSpec)
+		**out = **in
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = new(ChannelsSpec)
+		**out = **in
+	}
+	if in.PreloadImages != nil {
+		in, out := &in.PreloadImages, &out.PreloadImages
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.IAM != nil {
 		in, out := &in.IAM, &out.IAM
 		*out = new(IAMSpec)
@@ -1198,6 +1251,11 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.KernelModules != nil {
+		in, out := &in.KernelModules, &out.KernelModules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.RollingUpdate != nil {
 		in, out := &in.RollingUpdate, &out.RollingUpdate
 		*out = new(RollingUpdate)
@@ -1328,6 +1386,16 @@ func (in *ContainerdConfig) DeepCopyInto(out *ContainerdConfig) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VersionAmd64 != nil {
+		in, out := &in.VersionAmd64, &out.VersionAmd64
+		*out = new(string)
+		**out = **in
+	}
+	if in.VersionArm64 != nil {
+		in, out := &in.VersionArm64, &out.VersionArm64
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -1660,6 +1728,11 @@ func (in *EtcdClusterSpec) DeepCopyInto(out *EtcdClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.EnableEtcdTLS != nil {
+		in, out := &in.EnableEtcdTLS, &out.EnableEtcdTLS
+		*out = new(bool)
+		**out = **in
+	}
 	if in.LeaderElectionTimeout != nil {
 		in, out := &in.LeaderElectionTimeout, &out.LeaderElectionTimeout
 		*out = new(v1.Duration)
@@ -2397,6 +2470,11 @@ func (in *InstanceGroupSpec) DeepCopyInto(out *InstanceGroupSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.KernelModules != nil {
+		in, out := &in.KernelModules, &out.KernelModules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.RollingUpdate != nil {
 		in, out := &in.RollingUpdate, &out.RollingUpdate
 		*out = new(RollingUpdate)
@@ -3652,6 +3730,11 @@ func (in *KubernetesVersionSpec) DeepCopy() *KubernetesVersionSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KuberouterNetworkingSpec) DeepCopyInto(out *KuberouterNetworkingSpec) {
 	*out = *in
+	if in.RunServiceProxy != nil {
+		in, out := &in.RunServiceProxy, &out.RunServiceProxy
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -3988,7 +4071,7 @@ func (in *NetworkingSpec) DeepCopyInto(out *NetworkingSpec) {
 	if in.Kuberouter != nil {
 		in, out := &in.Kuberouter, &out.Kuberouter
 		*out = new(KuberouterNetworkingSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Romana != nil {
 		in, out := &in.Romana, &out.Romana
@@ -4177,6 +4260,22 @@ func (in *NodeTerminationHandlerConfig) DeepCopy() *NodeTerminationHandlerConfig
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeUpSpec) DeepCopyInto(out *NodeUpSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeUpSpec.
+func (in *NodeUpSpec) DeepCopy() *NodeUpSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeUpSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenstackBlockStorageConfig) DeepCopyInto(out *OpenstackBlockStorageConfig) {
 	*out = *in
@@ -4456,6 +4555,22 @@ func (in *PackagesConfig) DeepCopy() *PackagesConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProtokubeSpec) DeepCopyInto(out *ProtokubeSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProtokubeSpec.
+func (in *ProtokubeSpec) DeepCopy() *ProtokubeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProtokubeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RBACAuthorizationSpec) DeepCopyInto(out *RBACAuthorizationSpec) {
 	*out = *in
@@ -4818,6 +4933,11 @@ func (in *WarmPoolSpec) DeepCopyInto(out *WarmPoolSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.LifecycleHookGracePeriod != nil {
+		in, out := &in.LifecycleHookGracePeriod, &out.LifecycleHookGracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
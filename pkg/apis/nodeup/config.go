@@ -19,6 +19,7 @@ package nodeup
 import (
 	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/nodelabels"
 	"k8s.io/kops/upup/pkg/fi"
@@ -55,6 +56,8 @@ type Config struct {
 	DefaultMachineType *string `json:",omitempty"`
 	// EnableLifecycleHook defines whether we need to complete a lifecycle hook.
 	EnableLifecycleHook bool `json:",omitempty"`
+	// LifecycleHookGracePeriod is how long to wait before completing the lifecycle hook.
+	LifecycleHookGracePeriod *metav1.Duration `json:",omitempty"`
 	// StaticManifests describes generic static manifests
 	// Using this allows us to keep complex logic out of nodeup
 	StaticManifests []*StaticManifest `json:"staticManifests,omitempty"`
@@ -64,6 +67,8 @@ type Config struct {
 	// specified, each parameter must follow the form variable=value, the way
 	// it would appear in sysctl.conf.
 	SysctlParameters []string `json:",omitempty"`
+	// KernelModules are additional kernel modules to load, as well as those in the cluster spec.
+	KernelModules []string `json:",omitempty"`
 	// UpdatePolicy determines the policy for applying upgrades automatically.
 	UpdatePolicy string
 	// VolumeMounts are a collection of volume mounts.
@@ -86,18 +91,26 @@ type AuxConfig struct {
 type ConfigServerOptions struct {
 	// Server is the address of the configuration server to use (kops-controller)
 	Server string `json:"server,omitempty"`
-	// CA is the ca-certificate to require for the configuration server
+	// CA is the ca-certificate bundle to require for the configuration server; it may contain several concatenated PEM certificates
 	CA string `json:"ca,omitempty"`
+	// ClientCertFile is the path on the node to a PEM-encoded client certificate to present to the configuration server (for mutual TLS)
+	ClientCertFile string `json:"clientCertFile,omitempty"`
+	// ClientKeyFile is the path on the node to the PEM-encoded private key for ClientCertFile
+	ClientKeyFile string `json:"clientKeyFile,omitempty"`
 
 	// CloudProvider is the cloud provider in use (needed for authentication)
 	CloudProvider string `json:"cloudProvider,omitempty"`
+
+	// Timeout is how long nodeup keeps retrying the configuration server while it is unavailable; defaults to 10 minutes
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // Image is a docker image we should pre-load
 type Image struct {
 	// This is the name we would pass to "docker run", whereas source could be a URL from which we would download an image.
 	Name string `json:"name,omitempty"`
-	// Sources is a list of URLs from which we should download the image
+	// Sources is a list of URLs from which we should download the image.
+	// If empty, the image is pulled from its registry by Name.
 	Sources []string `json:"sources,omitempty"`
 	// Hash is the hash of the file, to verify image integrity (even over http)
 	Hash string `json:"hash,omitempty"`
@@ -118,6 +131,7 @@ func NewConfig(cluster *kops.Cluster, instanceGroup *kops.InstanceGroup) (*Confi
 	config := Config{
 		InstanceGroupRole: role,
 		SysctlParameters:  instanceGroup.Spec.SysctlParameters,
+		KernelModules:     instanceGroup.Spec.KernelModules,
 		VolumeMounts:      instanceGroup.Spec.VolumeMounts,
 	}
 
@@ -132,6 +146,7 @@ func NewConfig(cluster *kops.Cluster, instanceGroup *kops.InstanceGroup) (*Confi
 	warmPool := cluster.Spec.WarmPool.ResolveDefaults(instanceGroup)
 	if warmPool.IsEnabled() && warmPool.EnableLifecycleHook {
 		config.EnableLifecycleHook = true
+		config.LifecycleHookGracePeriod = warmPool.LifecycleHookGracePeriod
 	}
 
 	if isMaster {
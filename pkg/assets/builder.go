@@ -37,6 +37,9 @@ import (
 	"k8s.io/kops/util/pkg/vfs"
 )
 
+// UpstreamRegistries are the registry hosts which can be mirrored with ContainerRegistryMirrors.
+var UpstreamRegistries = []string{"docker.io", "gcr.io", "ghcr.io", "k8s.gcr.io", "public.ecr.aws", "quay.io", "registry.k8s.io"}
+
 // AssetBuilder discovers and remaps assets.
 type AssetBuilder struct {
 	ImageAssets    []*ImageAsset
@@ -49,6 +52,9 @@ type AssetBuilder struct {
 
 	// StaticManifests records static manifests
 	StaticManifests []*StaticManifest
+
+	// HashCache caches the hashes of file assets by URL; if nil, hashes are always fetched.
+	HashCache *HashCache
 }
 
 type StaticManifest struct {
@@ -86,6 +92,7 @@ func NewAssetBuilder(cluster *kops.Cluster, getAssets bool) *AssetBuilder {
 	a := &AssetBuilder{
 		AssetsLocation: cluster.Spec.Assets,
 		GetAssets:      getAssets,
+		HashCache:      DefaultHashCache(),
 	}
 
 	version, err := util.ParseKubernetesVersion(cluster.Spec.KubernetesVersion)
@@ -154,7 +161,7 @@ func (a *AssetBuilder) RemapImage(image string) (string, error) {
 	}
 
 	if a.AssetsLocation != nil && a.AssetsLocation.ContainerProxy != nil {
-		containerProxy := strings.TrimSuffix(*a.AssetsLocation.ContainerProxy, "/")
+		containerProxy := registryLocation(*a.AssetsLocation.ContainerProxy)
 		normalized := image
 
 		// If the image name contains only a single / we need to determine if the image is located on docker-hub or if it's using a convenient URL like k8s.gcr.io/<image-name>
@@ -172,8 +179,13 @@ func (a *AssetBuilder) RemapImage(image string) (string, error) {
 		image = asset.DownloadLocation
 	}
 
-	if a.AssetsLocation != nil && a.AssetsLocation.ContainerRegistry != nil {
-		registryMirror := *a.AssetsLocation.ContainerRegistry
+	if mirrored, found := a.remapToRegistryMirror(image); found {
+		asset.DownloadLocation = mirrored
+
+		// Run the new image
+		image = asset.DownloadLocation
+	} else if a.AssetsLocation != nil && a.AssetsLocation.ContainerRegistry != nil {
+		registryMirror := registryLocation(*a.AssetsLocation.ContainerRegistry)
 		normalized := image
 
 		// Remove the 'standard' kubernetes image prefix, just for sanity
@@ -199,6 +211,47 @@ func (a *AssetBuilder) RemapImage(image string) (string, error) {
 	return image, nil
 }
 
+// remapToRegistryMirror returns the location of the image in the mirror of its registry, from ContainerRegistryMirrors.
+// It returns false if the image's registry is not mirrored.
+func (a *AssetBuilder) remapToRegistryMirror(image string) (string, bool) {
+	if a.AssetsLocation == nil || len(a.AssetsLocation.ContainerRegistryMirrors) == 0 {
+		return image, false
+	}
+	registryMirrors := a.AssetsLocation.ContainerRegistryMirrors
+
+	// As with the ContainerRegistry, this may be called more than once on the same image,
+	// so we must not remap an image which is already in a mirror
+	for _, registryMirror := range registryMirrors {
+		if strings.HasPrefix(image, registryLocation(registryMirror)+"/") {
+			return image, true
+		}
+	}
+
+	registry, name := splitImageRegistry(image)
+	registryMirror, found := registryMirrors[registry]
+	if !found {
+		return image, false
+	}
+	return registryLocation(registryMirror) + "/" + name, true
+}
+
+// registryLocation returns the location of a registry as it is put in front of image names,
+// without any http or https scheme or trailing slash.
+func registryLocation(location string) string {
+	location = strings.TrimPrefix(strings.TrimPrefix(location, "https://"), "http://")
+	return strings.TrimSuffix(location, "/")
+}
+
+// splitImageRegistry splits an image into the host of its registry and the rest of its name.
+// Images without a registry host, such as weaveworks/weave-kube, are on docker hub.
+func splitImageRegistry(image string) (string, string) {
+	tokens := strings.SplitN(image, "/", 2)
+	if len(tokens) == 2 && (strings.ContainsAny(tokens[0], ".:") || tokens[0] == "localhost") {
+		return tokens[0], tokens[1]
+	}
+	return "docker.io", image
+}
+
 // RemapFileAndSHA returns a remapped URL for the file, if AssetsLocation is defined.
 // It also returns the SHA hash of the file.
 func (a *AssetBuilder) RemapFileAndSHA(fileURL *url.URL) (*url.URL, *hashing.Hash, error) {
@@ -287,6 +340,11 @@ func (a *AssetBuilder) findHash(file *FileAsset) (*hashing.Hash, error) {
 		return nil, fmt.Errorf("file url is not defined")
 	}
 
+	if h, found := a.HashCache.Get(u.String()); found {
+		klog.V(2).Infof("Found cached hash %q for %q", h.Hex(), u)
+		return h, nil
+	}
+
 	// We now prefer sha256 hashes
 	for backoffSteps := 1; backoffSteps <= 3; backoffSteps++ {
 		// We try first with a short backoff, so we don't
@@ -317,7 +375,14 @@ func (a *AssetBuilder) findHash(file *FileAsset) (*hashing.Hash, error) {
 					klog.Infof("Hash file was empty %q", hashURL)
 					continue
 				}
-				return hashing.FromString(fields[0])
+				h, err := hashing.FromString(fields[0])
+				if err != nil {
+					return nil, err
+				}
+				if err := a.HashCache.Put(u.String(), h); err != nil {
+					klog.Warningf("unable to cache hash for %q: %v", u, err)
+				}
+				return h, nil
 			}
 			if ext == ".sha256" {
 				klog.V(2).Infof("Unable to read new sha256 hash file (is this an older/unsupported kubernetes release?)")
@@ -159,6 +159,100 @@ func TestValidate_RemapImage_ContainerRegistry_MappingMultipleTimesConverges(t *
 
 }
 
+func TestValidate_RemapImage_ContainerRegistry_TrailingSlash(t *testing.T) {
+	builder := buildAssetBuilder(t)
+
+	registryURL := "registry.example.com/"
+	image := "k8s.gcr.io/kube-apiserver:1.2.3"
+	expected := "registry.example.com/kube-apiserver:1.2.3"
+
+	builder.AssetsLocation.ContainerRegistry = &registryURL
+
+	remapped, err := builder.RemapImage(image)
+	if err != nil {
+		t.Error("Error remapping image", err)
+	}
+
+	if remapped != expected {
+		t.Errorf("Error remapping image (Expecting: %s, got %s)", expected, remapped)
+	}
+}
+
+func TestValidate_RemapImage_ContainerRegistry_Scheme(t *testing.T) {
+	builder := buildAssetBuilder(t)
+
+	registryURL := "https://registry.example.com/"
+	image := "k8s.gcr.io/kube-apiserver:1.2.3"
+	expected := "registry.example.com/kube-apiserver:1.2.3"
+
+	builder.AssetsLocation.ContainerRegistry = &registryURL
+
+	remapped, err := builder.RemapImage(image)
+	if err != nil {
+		t.Error("Error remapping image", err)
+	}
+
+	if remapped != expected {
+		t.Errorf("Error remapping image (Expecting: %s, got %s)", expected, remapped)
+	}
+}
+
+func TestValidate_RemapImage_ContainerRegistryMirrors(t *testing.T) {
+	registry := "registry.example.com"
+
+	grid := []struct {
+		image    string
+		expected string
+	}{
+		{
+			image:    "weaveworks/weave-kube:2.8.1",
+			expected: "dockerhub.example.com/weaveworks/weave-kube:2.8.1",
+		},
+		{
+			image:    "docker.io/library/debian:buster",
+			expected: "dockerhub.example.com/library/debian:buster",
+		},
+		{
+			image:    "quay.io/cilium/cilium:v1.10.3",
+			expected: "quay.example.com/mirror/cilium/cilium:v1.10.3",
+		},
+		{
+			image:    "registry.k8s.io/kube-apiserver:v1.21.0",
+			expected: "k8s.example.com/kube-apiserver:v1.21.0",
+		},
+		{
+			// Registries without a mirror fall back to the ContainerRegistry
+			image:    "k8s.gcr.io/kube-proxy:v1.21.0",
+			expected: "registry.example.com/kube-proxy:v1.21.0",
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.image, func(t *testing.T) {
+			builder := buildAssetBuilder(t)
+			builder.AssetsLocation.ContainerRegistry = &registry
+			builder.AssetsLocation.ContainerRegistryMirrors = map[string]string{
+				"docker.io":       "dockerhub.example.com",
+				"quay.io":         "quay.example.com/mirror/",
+				"registry.k8s.io": "k8s.example.com",
+			}
+
+			// Remapping more than once must converge
+			remapped := g.image
+			for i := 0; i < 2; i++ {
+				var err error
+				remapped, err = builder.RemapImage(remapped)
+				if err != nil {
+					t.Fatalf("Error remapping image (iteration %d): %v", i, err)
+				}
+				if remapped != g.expected {
+					t.Errorf("Error remapping image (Expecting: %s, got %s, iteration: %d)", g.expected, remapped, i)
+				}
+			}
+		})
+	}
+}
+
 func TestRemapEmptySection(t *testing.T) {
 	builder := buildAssetBuilder(t)
 
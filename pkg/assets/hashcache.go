@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/featureflag"
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+// HashCache is a persistent on-disk cache of file asset hashes, keyed by URL.
+// Entries are never invalidated, so it must only be used for URLs whose content does not change.
+type HashCache struct {
+	// path is the file in which the cache is stored
+	path string
+
+	mutex   sync.Mutex
+	loaded  bool
+	entries map[string]string
+}
+
+// NewHashCache returns a HashCache stored in the file at path.
+func NewHashCache(path string) *HashCache {
+	return &HashCache{path: path}
+}
+
+// DefaultHashCache returns the HashCache in the user's cache directory,
+// or nil if the AssetHashCache feature flag is not set or the cache directory cannot be determined.
+func DefaultHashCache() *HashCache {
+	if !featureflag.AssetHashCache.Enabled() {
+		return nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		klog.V(2).Infof("unable to determine user cache directory, not caching asset hashes: %v", err)
+		return nil
+	}
+
+	return NewHashCache(filepath.Join(dir, "kops", "asset-hashes.json"))
+}
+
+// Get returns the cached hash for the URL, if there is one.
+func (c *HashCache) Get(u string) (*hashing.Hash, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.load()
+
+	s, found := c.entries[u]
+	if !found {
+		return nil, false
+	}
+
+	h, err := hashing.FromString(s)
+	if err != nil {
+		klog.Warningf("ignoring invalid cached hash %q for %q: %v", s, u, err)
+		return nil, false
+	}
+	return h, true
+}
+
+// Put records the hash for the URL, and writes the cache to disk.
+func (c *HashCache) Put(u string, h *hashing.Hash) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.load()
+
+	if c.entries[u] == h.String() {
+		return nil
+	}
+	c.entries[u] = h.String()
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing hash cache: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("error creating hash cache directory: %v", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("error creating hash cache file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing hash cache file %q: %v", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing hash cache file %q: %v", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("error writing hash cache file %q: %v", c.path, err)
+	}
+
+	return nil
+}
+
+// load reads the cache from disk, if it has not already been loaded. The mutex must be held.
+func (c *HashCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]string)
+
+	data, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.Warningf("unable to read hash cache %q: %v", c.path, err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		klog.Warningf("ignoring invalid hash cache %q: %v", c.path, err)
+		c.entries = make(map[string]string)
+	}
+}
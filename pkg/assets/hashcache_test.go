@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+const testHash = "sha256:01ba4719c80b6fe911b091a7c05124b64eeece964e09c058ef8f9805daca546b"
+
+func TestHashCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hashcache")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cachePath := filepath.Join(dir, "kops", "asset-hashes.json")
+	u := "https://example.com/kubelet"
+
+	h, err := hashing.FromString(testHash)
+	if err != nil {
+		t.Fatalf("error parsing hash: %v", err)
+	}
+
+	cache := NewHashCache(cachePath)
+	if _, found := cache.Get(u); found {
+		t.Fatalf("unexpected cache hit for %q in empty cache", u)
+	}
+
+	if err := cache.Put(u, h); err != nil {
+		t.Fatalf("error adding hash to cache: %v", err)
+	}
+
+	actual, found := cache.Get(u)
+	if !found {
+		t.Fatalf("expected cache hit for %q", u)
+	}
+	if actual.String() != testHash {
+		t.Errorf("unexpected cached hash: expected %q, got %q", testHash, actual.String())
+	}
+
+	if _, found := cache.Get("https://example.com/kubectl"); found {
+		t.Errorf("unexpected cache hit for uncached URL")
+	}
+
+	// The cache should persist across instances
+	actual, found = NewHashCache(cachePath).Get(u)
+	if !found {
+		t.Fatalf("expected cache hit for %q after reload", u)
+	}
+	if actual.String() != testHash {
+		t.Errorf("unexpected reloaded hash: expected %q, got %q", testHash, actual.String())
+	}
+}
+
+func TestHashCacheInvalidFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hashcache")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cachePath := filepath.Join(dir, "asset-hashes.json")
+	if err := ioutil.WriteFile(cachePath, []byte("not json"), 0644); err != nil {
+		t.Fatalf("error writing cache file: %v", err)
+	}
+
+	if _, found := NewHashCache(cachePath).Get("https://example.com/kubelet"); found {
+		t.Errorf("unexpected cache hit from invalid cache file")
+	}
+}
+
+func TestHashCacheNil(t *testing.T) {
+	var cache *HashCache
+
+	h, err := hashing.FromString(testHash)
+	if err != nil {
+		t.Fatalf("error parsing hash: %v", err)
+	}
+
+	if err := cache.Put("https://example.com/kubelet", h); err != nil {
+		t.Errorf("unexpected error adding to nil cache: %v", err)
+	}
+	if _, found := cache.Get("https://example.com/kubelet"); found {
+		t.Errorf("unexpected cache hit from nil cache")
+	}
+}
+
+func TestDefaultHashCacheDisabled(t *testing.T) {
+	if cache := DefaultHashCache(); cache != nil {
+		t.Errorf("expected no hash cache unless the AssetHashCache feature flag is set")
+	}
+}
+
+func TestRemapFileAndSHAUsesHashCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hashcache")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	h, err := hashing.FromString(testHash)
+	if err != nil {
+		t.Fatalf("error parsing hash: %v", err)
+	}
+
+	// The URL does not resolve, so the hash can only come from the cache
+	u, err := url.Parse("https://example.invalid/kubelet")
+	if err != nil {
+		t.Fatalf("error parsing url: %v", err)
+	}
+
+	builder := buildAssetBuilder(t)
+	builder.HashCache = NewHashCache(filepath.Join(dir, "asset-hashes.json"))
+	if err := builder.HashCache.Put(u.String(), h); err != nil {
+		t.Fatalf("error adding hash to cache: %v", err)
+	}
+
+	_, actual, err := builder.RemapFileAndSHA(u)
+	if err != nil {
+		t.Fatalf("unexpected error from RemapFileAndSHA: %v", err)
+	}
+	if actual.String() != testHash {
+		t.Errorf("unexpected hash: expected %q, got %q", testHash, actual.String())
+	}
+}
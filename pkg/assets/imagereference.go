@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The grammar of image references, as accepted by docker and containerd:
+//
+//	reference := name [ ":" tag ] [ "@" digest ]
+//	name      := [ domain "/" ] component [ "/" component ]*
+//	domain    := domain-component [ "." domain-component ]* [ ":" port ]
+const (
+	domainComponentPattern = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	domainPattern          = domainComponentPattern + `(?:\.` + domainComponentPattern + `)*(?::[0-9]+)?`
+	nameComponentPattern   = `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*`
+	repositoryPattern      = nameComponentPattern + `(?:/` + nameComponentPattern + `)*`
+	tagPattern             = `[\w][\w.-]{0,127}`
+	digestPattern          = `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[[:xdigit:]]{32,}`
+)
+
+var (
+	registryHostRegexp   = regexp.MustCompile(`^` + domainPattern + `$`)
+	repositoryPathRegexp = regexp.MustCompile(`^` + repositoryPattern + `$`)
+	imageRegexp          = regexp.MustCompile(`^(?:` + domainPattern + `/)?` + repositoryPattern + `(?::` + tagPattern + `)?(?:@` + digestPattern + `)?$`)
+)
+
+// IsValidRegistryHost returns true if host is a registry host, with an optional port.
+func IsValidRegistryHost(host string) bool {
+	return registryHostRegexp.MatchString(host)
+}
+
+// IsValidRepositoryPath returns true if path is a repository path within a registry, without a tag or digest.
+func IsValidRepositoryPath(path string) bool {
+	return repositoryPathRegexp.MatchString(path)
+}
+
+// NormalizeImage returns the fully-qualified form of an image reference, as docker would pull it.
+// Images without a registry host are on docker hub, and single-component docker hub images are in its library.
+func NormalizeImage(image string) (string, error) {
+	if !imageRegexp.MatchString(image) {
+		return "", fmt.Errorf("invalid image reference %q", image)
+	}
+
+	registry, name := splitImageRegistry(image)
+	if registry == "index.docker.io" {
+		registry = "docker.io"
+	}
+	if registry == "docker.io" && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	return registry + "/" + name, nil
+}
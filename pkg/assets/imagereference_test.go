@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assets
+
+import (
+	"testing"
+)
+
+func TestNormalizeImage(t *testing.T) {
+	grid := []struct {
+		Image    string
+		Expected string
+	}{
+		{
+			Image:    "busybox",
+			Expected: "docker.io/library/busybox",
+		},
+		{
+			Image:    "weaveworks/weave-kube:2.8.1",
+			Expected: "docker.io/weaveworks/weave-kube:2.8.1",
+		},
+		{
+			Image:    "index.docker.io/busybox",
+			Expected: "docker.io/library/busybox",
+		},
+		{
+			Image:    "quay.io/cilium/cilium:v1.10.0",
+			Expected: "quay.io/cilium/cilium:v1.10.0",
+		},
+		{
+			Image:    "localhost:5000/busybox@sha256:01ba4719c80b6fe911b091a7c05124b64eeece964e09c058ef8f9805daca546b",
+			Expected: "localhost:5000/busybox@sha256:01ba4719c80b6fe911b091a7c05124b64eeece964e09c058ef8f9805daca546b",
+		},
+		{
+			Image:    "localhost/busybox",
+			Expected: "localhost/busybox",
+		},
+	}
+
+	for _, g := range grid {
+		actual, err := NormalizeImage(g.Image)
+		if err != nil {
+			t.Errorf("unexpected error normalizing %q: %v", g.Image, err)
+			continue
+		}
+		if actual != g.Expected {
+			t.Errorf("unexpected result normalizing %q: expected %q, got %q", g.Image, g.Expected, actual)
+		}
+	}
+
+	for _, image := range []string{"", "BusyBox", "busybox:", "busybox@sha256:abc", "https://registry.example.com/busybox", "registry.example.com//busybox"} {
+		if _, err := NormalizeImage(image); err == nil {
+			t.Errorf("expected error normalizing %q", image)
+		}
+	}
+}
+
+func TestIsValidRegistryHost(t *testing.T) {
+	for _, host := range []string{"registry.example.com", "registry.example.com:5000", "localhost", "10.0.0.1:5000"} {
+		if !IsValidRegistryHost(host) {
+			t.Errorf("expected %q to be a valid registry host", host)
+		}
+	}
+	for _, host := range []string{"", "registry.example.com:latest", "-registry.example.com", "registry.example.com/kops"} {
+		if IsValidRegistryHost(host) {
+			t.Errorf("expected %q not to be a valid registry host", host)
+		}
+	}
+}
+
+func TestIsValidRepositoryPath(t *testing.T) {
+	for _, path := range []string{"kops", "kops/mirror", "kops_mirror/k8s-images"} {
+		if !IsValidRepositoryPath(path) {
+			t.Errorf("expected %q to be a valid repository path", path)
+		}
+	}
+	for _, path := range []string{"", "Kops", "kube-apiserver:v1.21.0", "kops//mirror"} {
+		if IsValidRepositoryPath(path) {
+			t.Errorf("expected %q not to be a valid repository path", path)
+		}
+	}
+}
@@ -136,16 +136,16 @@ func TestSetClusterFields(t *testing.T) {
 			Input: kops.Cluster{
 				Spec: kops.ClusterSpec{
 					EtcdClusters: []kops.EtcdClusterSpec{
-						{Name: "one", EnableEtcdTLS: true},
-						{Name: "two", EnableEtcdTLS: false},
+						{Name: "one", EnableEtcdTLS: fi.Bool(true)},
+						{Name: "two", EnableEtcdTLS: fi.Bool(false)},
 					},
 				},
 			},
 			Output: kops.Cluster{
 				Spec: kops.ClusterSpec{
 					EtcdClusters: []kops.EtcdClusterSpec{
-						{Name: "one", EnableEtcdTLS: true},
-						{Name: "two", EnableEtcdTLS: true},
+						{Name: "one", EnableEtcdTLS: fi.Bool(true)},
+						{Name: "two", EnableEtcdTLS: fi.Bool(true)},
 					},
 				},
 			},
@@ -164,8 +164,8 @@ func TestUnsetClusterFields(t *testing.T) {
 			Input: kops.Cluster{
 				Spec: kops.ClusterSpec{
 					EtcdClusters: []kops.EtcdClusterSpec{
-						{Name: "one", EnableEtcdTLS: true},
-						{Name: "two", EnableEtcdTLS: true},
+						{Name: "one", EnableEtcdTLS: fi.Bool(true)},
+						{Name: "two", EnableEtcdTLS: fi.Bool(true)},
 					},
 				},
 			},
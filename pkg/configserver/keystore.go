@@ -109,7 +109,27 @@ func (s *configserverKeyStore) ListKeysets() ([]*kops.Keyset, error) {
 	return nil, fmt.Errorf("ListKeysets not supported by configserverKeyStore")
 }
 
+// ListKeysetsByType implements fi.CAStore
+func (s *configserverKeyStore) ListKeysetsByType(t kops.KeysetType) ([]*kops.Keyset, error) {
+	return nil, fmt.Errorf("ListKeysetsByType not supported by configserverKeyStore")
+}
+
 // DeleteKeysetItem implements fi.CAStore
 func (s *configserverKeyStore) DeleteKeysetItem(item *kops.Keyset, id string) error {
 	return fmt.Errorf("DeleteKeysetItem not supported by configserverKeyStore")
 }
+
+// PromoteToPrimary implements fi.CAStore
+func (s *configserverKeyStore) PromoteToPrimary(name string, id string) error {
+	return fmt.Errorf("PromoteToPrimary not supported by configserverKeyStore")
+}
+
+// ExportClientBundle implements fi.CAStore
+func (s *configserverKeyStore) ExportClientBundle(caName, clientName string) ([]byte, []byte, []byte, error) {
+	return nil, nil, nil, fmt.Errorf("ExportClientBundle not supported by configserverKeyStore")
+}
+
+// StoreKeysets implements fi.CAStore
+func (s *configserverKeyStore) StoreKeysets(keysets map[string]*fi.Keyset) error {
+	return fmt.Errorf("StoreKeysets not supported by configserverKeyStore")
+}
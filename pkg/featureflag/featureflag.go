@@ -101,6 +101,8 @@ var (
 	UseAddonOperators = New("UseAddonOperators", Bool(false))
 	// AWSIPv6 activates experimental AWS IPv6 support.
 	AWSIPv6 = New("AWSIPv6", Bool(false))
+	// AssetHashCache caches the hashes of file assets by URL in the user's cache directory.
+	AssetHashCache = New("AssetHashCache", Bool(false))
 )
 
 // FeatureFlag defines a feature flag
@@ -111,7 +111,7 @@ func (b *AutoscalingGroupModelBuilder) Build(c *fi.ModelBuilderContext) error {
 						DefaultResult:    aws.String("ABANDON"),
 						// We let nodeup have 10 min to complete. Normally this should happen much faster,
 						// but CP nodes need 5 min or so to start on new clusters, and we need to wait for that.
-						HeartbeatTimeout:    aws.Int64(600),
+						HeartbeatTimeout:    aws.Int64(int64(kops.WarmPoolLifecycleHookHeartbeatTimeout.Seconds())),
 						LifecycleTransition: aws.String("autoscaling:EC2_INSTANCE_LAUNCHING"),
 					}
 
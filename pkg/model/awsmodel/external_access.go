@@ -40,7 +40,7 @@ func (b *ExternalAccessModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		klog.Warningf("KubernetesAPIAccess is empty")
 	}
 
-	if len(b.Cluster.Spec.SSHAccess) == 0 {
+	if len(b.Cluster.Spec.SSHAccess) == 0 && !b.Cluster.Spec.DisableSSHAccess {
 		klog.Warningf("SSHAccess is empty")
 	}
 
@@ -54,7 +54,9 @@ func (b *ExternalAccessModelBuilder) Build(c *fi.ModelBuilderContext) error {
 	}
 
 	// SSH is open to AdminCIDR set
-	if b.UsesSSHBastion() {
+	if b.Cluster.Spec.DisableSSHAccess {
+		klog.V(2).Infof("SSH access is disabled; won't configure SSH access to master / node instances")
+	} else if b.UsesSSHBastion() {
 		// If we are using a bastion, we only access through the bastion
 		// This is admittedly a little odd... adding a bastion shuts down direct access to the masters/nodes
 		// But I think we can always add more permissions in this case later, but we can't easily take them away
@@ -36,6 +36,7 @@ import (
 	"k8s.io/kops/pkg/apis/nodeup"
 	"k8s.io/kops/pkg/model/resources"
 	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
 	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
 	"k8s.io/kops/upup/pkg/fi/fitasks"
 	"k8s.io/kops/util/pkg/architectures"
@@ -272,6 +273,14 @@ func (b *BootstrapScript) Run(c *fi.Context) error {
 		return err
 	}
 
+	warning, err := nodeUpConfigSizeWarning(kops.CloudProviderID(c.Cluster.Spec.CloudProvider), b.ig, config)
+	if err != nil {
+		return fmt.Errorf("error compressing nodeup config: %v", err)
+	}
+	if warning != "" {
+		klog.Warning(warning)
+	}
+
 	functions := template.FuncMap{
 		"NodeUpSourceAmd64": func() string {
 			if b.builder.NodeUpAssets[architectures.ArchitectureAmd64] != nil {
@@ -457,6 +466,46 @@ func (b *BootstrapScript) createProxyEnv(ps *kops.EgressProxySpec) string {
 	return buffer.String()
 }
 
+// userDataSizeLimits records the maximum size of the userdata, in bytes, accepted by each cloud provider.
+var userDataSizeLimits = map[kops.CloudProviderID]int{
+	kops.CloudProviderAWS:       awstasks.MaxUserDataSize,
+	kops.CloudProviderAzure:     65536,
+	kops.CloudProviderGCE:       262144,
+	kops.CloudProviderOpenstack: 65535,
+}
+
+// userDataSizeWarningRatio is the proportion of the userdata size limit above which we warn about the size of the nodeup config.
+const userDataSizeWarningRatio = 0.8
+
+// nodeUpConfigSizeWarning returns a warning if the nodeup config, as it will be embedded in the userdata,
+// approaches the cloud provider's userdata size limit, or an empty string otherwise.
+func nodeUpConfigSizeWarning(cloudProvider kops.CloudProviderID, ig *kops.InstanceGroup, config string) (string, error) {
+	limit, found := userDataSizeLimits[cloudProvider]
+	if !found {
+		return "", nil
+	}
+
+	compressed := fi.BoolValue(ig.Spec.CompressUserData)
+	size := len(config)
+	if compressed {
+		encoded, err := gzipBase64(config)
+		if err != nil {
+			return "", err
+		}
+		size = len(encoded)
+	}
+
+	if float64(size) < float64(limit)*userDataSizeWarningRatio {
+		return "", nil
+	}
+
+	warning := fmt.Sprintf("nodeup config for instance group %q is %d bytes, approaching the %s userdata size limit of %d bytes", ig.ObjectMeta.Name, size, cloudProvider, limit)
+	if !compressed {
+		warning += "; consider setting spec.compressUserData"
+	}
+	return warning, nil
+}
+
 func gzipBase64(data string) (string, error) {
 	var b bytes.Buffer
 	gz := gzip.NewWriter(&b)
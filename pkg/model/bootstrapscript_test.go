@@ -69,6 +69,69 @@ func (n *nodeupConfigBuilder) BuildConfig(ig *kops.InstanceGroup, apiserverAddit
 	return config, auxConfig, nil
 }
 
+func Test_NodeUpConfigSizeWarning(t *testing.T) {
+	large := strings.Repeat("a", 14000)
+
+	grid := []struct {
+		Description      string
+		CloudProvider    kops.CloudProviderID
+		CompressUserData bool
+		Config           string
+		ExpectWarning    bool
+		ExpectSuggestion bool
+	}{
+		{
+			Description:   "small config",
+			CloudProvider: kops.CloudProviderAWS,
+			Config:        "small",
+		},
+		{
+			Description:      "large config",
+			CloudProvider:    kops.CloudProviderAWS,
+			Config:           large,
+			ExpectWarning:    true,
+			ExpectSuggestion: true,
+		},
+		{
+			Description:      "large config compressed",
+			CloudProvider:    kops.CloudProviderAWS,
+			CompressUserData: true,
+			Config:           large,
+		},
+		{
+			Description:   "large config within provider limit",
+			CloudProvider: kops.CloudProviderGCE,
+			Config:        large,
+		},
+		{
+			Description:   "provider without known limit",
+			CloudProvider: kops.CloudProviderDO,
+			Config:        large,
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			ig := &kops.InstanceGroup{
+				ObjectMeta: v1.ObjectMeta{Name: "nodes"},
+				Spec: kops.InstanceGroupSpec{
+					CompressUserData: fi.Bool(g.CompressUserData),
+				},
+			}
+
+			warning, err := nodeUpConfigSizeWarning(g.CloudProvider, ig, g.Config)
+			require.NoError(t, err)
+
+			if g.ExpectWarning != (warning != "") {
+				t.Errorf("expected warning %v, got %q", g.ExpectWarning, warning)
+			}
+			if g.ExpectSuggestion != strings.Contains(warning, "compressUserData") {
+				t.Errorf("expected compressUserData suggestion %v, got %q", g.ExpectSuggestion, warning)
+			}
+		})
+	}
+}
+
 func TestBootstrapUserData(t *testing.T) {
 	cs := []struct {
 		Role               kops.InstanceGroupRole
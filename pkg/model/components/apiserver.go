@@ -142,7 +142,7 @@ func (b *KubeAPIServerOptionsBuilder) BuildOptions(o interface{}) error {
 
 	for _, etcdCluster := range clusterSpec.EtcdClusters {
 		protocol := "http"
-		if etcdCluster.EnableEtcdTLS {
+		if fi.BoolValue(etcdCluster.EnableEtcdTLS) {
 			protocol = "https"
 		}
 		switch etcdCluster.Name {
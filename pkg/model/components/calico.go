@@ -53,9 +53,15 @@ func (b *CalicoOptionsBuilder) BuildOptions(o interface{}) error {
 		fallthrough
 	case "ipip":
 		rebindIfEmpty(&c.IPIPMode, activeMode)
-	case "vxlan":
+	case "vxlan", "none":
 		rebindIfEmpty(&c.IPIPMode, "Never")
 	}
 
+	if c.EncapsulationMode == "vxlan" {
+		rebindIfEmpty(&c.NetworkingBackend, "vxlan")
+	} else {
+		rebindIfEmpty(&c.NetworkingBackend, "bird")
+	}
+
 	return nil
 }
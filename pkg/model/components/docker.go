@@ -66,10 +66,11 @@ func (b *DockerOptionsBuilder) BuildOptions(o interface{}) error {
 	docker.IPTables = fi.Bool(false)
 	docker.IPMasq = fi.Bool(false)
 
-	// Note the alternative syntax... with a comma nodeup will try each of the filesystems in turn
+	// Note the alternative syntax... with a comma nodeup will try each of the filesystems in turn.
+	// overlay is not listed: nodeup checks overlay2 support as overlay, so it could never be selected after overlay2.
 	// TODO(justinsb): The ContainerOS image now has docker configured to use overlay2 out-of-the-box
 	// and it is an error to specify the flag twice.
-	docker.Storage = fi.String("overlay2,overlay,aufs")
+	docker.Storage = fi.String("overlay2,aufs")
 
 	// Set systemd as the default cgroup driver in docker from k8s 1.20.
 	if b.IsKubernetesGTE("1.20") && getDockerCgroupDriver(docker.ExecOpt) == "" {
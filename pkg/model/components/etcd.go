@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/loader"
 )
 
@@ -43,20 +44,26 @@ const (
 func (b *EtcdOptionsBuilder) BuildOptions(o interface{}) error {
 	spec := o.(*kops.ClusterSpec)
 
+	// We run the k8s-recommended versions of etcd, unless the main cluster specifies a version
+	defaultVersion := DefaultEtcd3Version_1_17
+	if b.IsKubernetesGTE("1.19") {
+		defaultVersion = DefaultEtcd3Version_1_19
+	}
+	for _, c := range spec.EtcdClusters {
+		if c.Name == "main" && c.Version != "" {
+			defaultVersion = c.Version
+		}
+	}
+
 	for i := range spec.EtcdClusters {
 		c := &spec.EtcdClusters[i]
 		if c.Provider == "" {
 			c.Provider = kops.EtcdProviderTypeManager
 		}
 
-		// Ensure the version is set
+		// Ensure the version is set; each cluster keeps its own version if specified
 		if c.Version == "" {
-			// We run the k8s-recommended versions of etcd
-			if b.IsKubernetesGTE("1.19") {
-				c.Version = DefaultEtcd3Version_1_19
-			} else {
-				c.Version = DefaultEtcd3Version_1_17
-			}
+			c.Version = defaultVersion
 		}
 
 		// We make sure that etcd v3 is used
@@ -67,7 +74,7 @@ func (b *EtcdOptionsBuilder) BuildOptions(o interface{}) error {
 
 		// We enable TLS if we're running EtcdManager
 		if c.Provider == kops.EtcdProviderTypeManager {
-			c.EnableEtcdTLS = true
+			c.EnableEtcdTLS = fi.Bool(true)
 			c.EnableTLSAuth = true
 		}
 
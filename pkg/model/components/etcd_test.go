@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"testing"
+
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/util"
+)
+
+func Test_Build_Etcd_Versions(t *testing.T) {
+	grid := []struct {
+		Description    string
+		Main           string
+		Events         string
+		ExpectedMain   string
+		ExpectedEvents string
+	}{
+		{
+			Description:    "defaults",
+			ExpectedMain:   DefaultEtcd3Version_1_19,
+			ExpectedEvents: DefaultEtcd3Version_1_19,
+		},
+		{
+			Description:    "events inherits main version",
+			Main:           "3.4.3",
+			ExpectedMain:   "3.4.3",
+			ExpectedEvents: "3.4.3",
+		},
+		{
+			Description:    "distinct versions",
+			Main:           "3.4.13",
+			Events:         "3.4.3",
+			ExpectedMain:   "3.4.13",
+			ExpectedEvents: "3.4.3",
+		},
+		{
+			Description:    "only events specified",
+			Events:         "3.4.3",
+			ExpectedMain:   DefaultEtcd3Version_1_19,
+			ExpectedEvents: "3.4.3",
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			spec := &api.ClusterSpec{
+				KubernetesVersion: "1.20.0",
+				EtcdClusters: []api.EtcdClusterSpec{
+					{Name: "main", Version: g.Main},
+					{Name: "events", Version: g.Events},
+				},
+			}
+
+			version, err := util.ParseKubernetesVersion(spec.KubernetesVersion)
+			if err != nil {
+				t.Fatalf("unexpected error from ParseKubernetesVersion: %v", err)
+			}
+
+			b := &EtcdOptionsBuilder{
+				&OptionsContext{
+					KubernetesVersion: *version,
+				},
+			}
+			if err := b.BuildOptions(spec); err != nil {
+				t.Fatalf("unexpected error from BuildOptions: %v", err)
+			}
+
+			if spec.EtcdClusters[0].Version != g.ExpectedMain {
+				t.Errorf("unexpected main version: expected %q, got %q", g.ExpectedMain, spec.EtcdClusters[0].Version)
+			}
+			if spec.EtcdClusters[1].Version != g.ExpectedEvents {
+				t.Errorf("unexpected events version: expected %q, got %q", g.ExpectedEvents, spec.EtcdClusters[1].Version)
+			}
+		})
+	}
+}
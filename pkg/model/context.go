@@ -325,7 +325,7 @@ func (b *KopsModelContext) UseEtcdManager() bool {
 // UseEtcdTLS checks to see if etcd tls is enabled
 func (b *KopsModelContext) UseEtcdTLS() bool {
 	for _, x := range b.Cluster.Spec.EtcdClusters {
-		if x.EnableEtcdTLS {
+		if fi.BoolValue(x.EnableEtcdTLS) {
 			return true
 		}
 	}
@@ -333,9 +333,12 @@ func (b *KopsModelContext) UseEtcdTLS() bool {
 	return false
 }
 
-// UseSSHKey returns true if SSHKeyName from the cluster spec is not set to an empty string (""). Setting SSHKeyName
-// to an empty string indicates that an SSH key should not be set on instances.
+// UseSSHKey returns true if SSHKeyName from the cluster spec is not set to an empty string (""), and SSH access is not disabled.
+// Setting SSHKeyName to an empty string indicates that an SSH key should not be set on instances.
 func (b *KopsModelContext) UseSSHKey() bool {
+	if b.Cluster.Spec.DisableSSHAccess {
+		return false
+	}
 	sshKeyName := b.Cluster.Spec.SSHKeyName
 	return sshKeyName == nil || *sshKeyName != ""
 }
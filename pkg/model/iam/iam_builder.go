@@ -30,6 +30,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"path"
 	"sort"
 	"strings"
 
@@ -77,11 +78,13 @@ type Condition map[string]interface{}
 // Statement is an AWS IAM Policy Statement Object:
 // http://docs.aws.amazon.com/IAM/latest/UserGuide/reference_policies_elements.html#Statement
 type Statement struct {
-	Effect    StatementEffect
-	Principal Principal
-	Action    stringorslice.StringOrSlice
-	Resource  stringorslice.StringOrSlice
-	Condition Condition
+	Effect      StatementEffect
+	Principal   Principal
+	Action      stringorslice.StringOrSlice
+	NotAction   stringorslice.StringOrSlice
+	Resource    stringorslice.StringOrSlice
+	NotResource stringorslice.StringOrSlice
+	Condition   Condition
 }
 
 type jsonWriter struct {
@@ -163,6 +166,18 @@ func (s *Statement) MarshalJSON() ([]byte, error) {
 	jw.Field("Effect")
 	jw.Marshal(s.Effect)
 
+	if !s.NotAction.IsEmpty() {
+		jw.Comma()
+		jw.Field("NotAction")
+		jw.Marshal(s.NotAction)
+	}
+
+	if !s.NotResource.IsEmpty() {
+		jw.Comma()
+		jw.Field("NotResource")
+		jw.Marshal(s.NotResource)
+	}
+
 	if !s.Principal.IsEmpty() {
 		jw.Comma()
 		jw.Field("Principal")
@@ -198,12 +213,50 @@ func (l *Statement) Equal(r *Statement) bool {
 	if !l.Action.Equal(r.Action) {
 		return false
 	}
+	if !l.NotAction.Equal(r.NotAction) {
+		return false
+	}
 	if !l.Resource.Equal(r.Resource) {
 		return false
 	}
+	if !l.NotResource.Equal(r.NotResource) {
+		return false
+	}
 	return true
 }
 
+// DeniesAction returns true if the statement unconditionally denies the action on all resources.
+func (s *Statement) DeniesAction(action string) bool {
+	if s.Effect != StatementEffectDeny || len(s.Condition) != 0 || !s.NotResource.IsEmpty() {
+		return false
+	}
+	allResources := false
+	for _, resource := range s.Resource.Value() {
+		if resource == "*" {
+			allResources = true
+		}
+	}
+	if !allResources {
+		return false
+	}
+
+	if !s.NotAction.IsEmpty() {
+		return !actionMatches(s.NotAction.Value(), action)
+	}
+	return actionMatches(s.Action.Value(), action)
+}
+
+// actionMatches returns true if any of the (possibly wildcarded) IAM action patterns match the action.
+func actionMatches(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		// IAM actions are case-insensitive and cannot contain a '/', so path.Match implements the wildcards
+		if match, _ := path.Match(strings.ToLower(pattern), strings.ToLower(action)); match {
+			return true
+		}
+	}
+	return false
+}
+
 // PolicyBuilder struct defines all valid fields to be used when building the
 // AWS IAM policy document for a given instance group role.
 type PolicyBuilder struct {
@@ -340,6 +393,15 @@ func (r *NodeRoleMaster) BuildAWSPolicy(b *PolicyBuilder) (*Policy, error) {
 	if b.Cluster.Spec.SnapshotController != nil && fi.BoolValue(b.Cluster.Spec.SnapshotController.Enabled) {
 		addSnapshotPersmissions(p, b.Cluster.GetName())
 	}
+
+	// kops-controller runs with the role of the masters
+	if b.Cluster.Spec.IAM != nil && b.Cluster.Spec.IAM.KopsControllerAdditionalPolicy != "" {
+		statements, err := ParseStatements(b.Cluster.Spec.IAM.KopsControllerAdditionalPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing kops-controller additional policy: %w", err)
+		}
+		p.Statement = append(p.Statement, statements...)
+	}
 	return p, nil
 }
 
@@ -1091,24 +1153,32 @@ func addLyftVPCPermissions(p *Policy, resource stringorslice.StringOrSlice, clus
 	)
 }
 
+// ciliumENIActions are the EC2 actions needed by Cilium when using ENI IPAM
+var ciliumENIActions = []string{
+	"ec2:DescribeSubnets",
+	"ec2:AttachNetworkInterface",
+	"ec2:AssignPrivateIpAddresses",
+	"ec2:UnassignPrivateIpAddresses",
+	"ec2:CreateNetworkInterface",
+	"ec2:DescribeNetworkInterfaces",
+	"ec2:DescribeVpcPeeringConnections",
+	"ec2:DescribeSecurityGroups",
+	"ec2:DetachNetworkInterface",
+	"ec2:DeleteNetworkInterface",
+	"ec2:ModifyNetworkInterfaceAttribute",
+	"ec2:DescribeVpcs",
+}
+
+// CiliumENIActions returns the EC2 actions granted to instances when Cilium uses ENI IPAM.
+func CiliumENIActions() []string {
+	return append([]string(nil), ciliumENIActions...)
+}
+
 func addCiliumEniPermissions(p *Policy, resource stringorslice.StringOrSlice) {
 	p.Statement = append(p.Statement,
 		&Statement{
-			Effect: StatementEffectAllow,
-			Action: stringorslice.Slice([]string{
-				"ec2:DescribeSubnets",
-				"ec2:AttachNetworkInterface",
-				"ec2:AssignPrivateIpAddresses",
-				"ec2:UnassignPrivateIpAddresses",
-				"ec2:CreateNetworkInterface",
-				"ec2:DescribeNetworkInterfaces",
-				"ec2:DescribeVpcPeeringConnections",
-				"ec2:DescribeSecurityGroups",
-				"ec2:DetachNetworkInterface",
-				"ec2:DeleteNetworkInterface",
-				"ec2:ModifyNetworkInterfaceAttribute",
-				"ec2:DescribeVpcs",
-			}),
+			Effect:   StatementEffectAllow,
+			Action:   stringorslice.Slice(CiliumENIActions()),
 			Resource: resource,
 		},
 	)
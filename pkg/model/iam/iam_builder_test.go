@@ -80,6 +80,14 @@ func TestRoundTrip(t *testing.T) {
 			},
 			JSON: "{\"Condition\":{\"foo\":1},\"Effect\":\"Deny\",\"Principal\":{\"Federated\":\"federated\"}}",
 		},
+		{
+			IAM: &Statement{
+				Effect:      StatementEffectDeny,
+				NotAction:   stringorslice.Of("s3:GetObject"),
+				NotResource: stringorslice.Of("a", "b"),
+			},
+			JSON: "{\"Effect\":\"Deny\",\"NotAction\":\"s3:GetObject\",\"NotResource\":[\"a\",\"b\"]}",
+		},
 		{
 			IAM: &Statement{
 				Effect:    StatementEffectDeny,
@@ -114,6 +122,90 @@ func TestRoundTrip(t *testing.T) {
 	}
 }
 
+func TestStatementDeniesAction(t *testing.T) {
+	grid := []struct {
+		IAM      *Statement
+		Action   string
+		Expected bool
+	}{
+		{
+			IAM: &Statement{
+				Effect:   StatementEffectAllow,
+				Action:   stringorslice.Of("ec2:CreateNetworkInterface"),
+				Resource: stringorslice.Of("*"),
+			},
+			Action: "ec2:CreateNetworkInterface",
+		},
+		{
+			IAM: &Statement{
+				Effect:   StatementEffectDeny,
+				Action:   stringorslice.Of("EC2:*NetworkInterface*"),
+				Resource: stringorslice.Of("*"),
+			},
+			Action:   "ec2:CreateNetworkInterface",
+			Expected: true,
+		},
+		{
+			IAM: &Statement{
+				Effect:   StatementEffectDeny,
+				Action:   stringorslice.Of("ec2:Describe?pcs"),
+				Resource: stringorslice.Of("*"),
+			},
+			Action:   "ec2:DescribeVpcs",
+			Expected: true,
+		},
+		{
+			IAM: &Statement{
+				Effect:   StatementEffectDeny,
+				Action:   stringorslice.Of("ec2:DeleteNetworkInterface"),
+				Resource: stringorslice.Of("*"),
+			},
+			Action: "ec2:CreateNetworkInterface",
+		},
+		{
+			IAM: &Statement{
+				Effect:   StatementEffectDeny,
+				Action:   stringorslice.Of("ec2:*"),
+				Resource: stringorslice.Of("arn:aws:ec2:*:*:network-interface/*"),
+			},
+			Action: "ec2:CreateNetworkInterface",
+		},
+		{
+			IAM: &Statement{
+				Effect:    StatementEffectDeny,
+				NotAction: stringorslice.Of("s3:*"),
+				Resource:  stringorslice.Of("*"),
+			},
+			Action:   "ec2:CreateNetworkInterface",
+			Expected: true,
+		},
+		{
+			IAM: &Statement{
+				Effect:    StatementEffectDeny,
+				NotAction: stringorslice.Of("ec2:*"),
+				Resource:  stringorslice.Of("*"),
+			},
+			Action: "ec2:CreateNetworkInterface",
+		},
+		{
+			IAM: &Statement{
+				Effect:   StatementEffectDeny,
+				Action:   stringorslice.Of("ec2:*"),
+				Resource: stringorslice.Of("*"),
+				Condition: map[string]interface{}{
+					"Bool": map[string]interface{}{"aws:SecureTransport": "false"},
+				},
+			},
+			Action: "ec2:CreateNetworkInterface",
+		},
+	}
+	for _, g := range grid {
+		if actual := g.IAM.DeniesAction(g.Action); actual != g.Expected {
+			t.Errorf("unexpected result for %v denying %s: expected %v, got %v", g.IAM, g.Action, g.Expected, actual)
+		}
+	}
+}
+
 func TestPolicyGeneration(t *testing.T) {
 	grid := []struct {
 		Role                   Subject
@@ -204,6 +296,42 @@ func TestPolicyGeneration(t *testing.T) {
 	}
 }
 
+func TestKopsControllerAdditionalPolicy(t *testing.T) {
+	cluster := testutils.BuildMinimalCluster("iam-builder-test.k8s.local")
+	cluster.Spec.IAM = &kops.IAMSpec{
+		KopsControllerAdditionalPolicy: `[ { "Effect": "Allow", "Action": [ "ec2:DescribeTags" ], "Resource": [ "*" ] } ]`,
+	}
+
+	expected := &Statement{
+		Effect:   StatementEffectAllow,
+		Action:   stringorslice.Of("ec2:DescribeTags"),
+		Resource: stringorslice.Of("*"),
+	}
+
+	for _, role := range []Subject{&NodeRoleMaster{}, &NodeRoleNode{}} {
+		b := &PolicyBuilder{
+			Cluster: cluster,
+			Role:    role,
+		}
+
+		p, err := b.BuildAWSPolicy()
+		if err != nil {
+			t.Fatalf("failed to build an AWS IAM policy: %v", err)
+		}
+
+		found := false
+		for _, statement := range p.Statement {
+			if statement.Equal(expected) {
+				found = true
+			}
+		}
+		_, isMaster := role.(*NodeRoleMaster)
+		if found != isMaster {
+			t.Errorf("unexpected presence of the kops-controller statement in the %T policy: expected %v, got %v", role, isMaster, found)
+		}
+	}
+}
+
 func TestEmptyPolicy(t *testing.T) {
 
 	role := &GenericServiceAccount{
@@ -44,6 +44,7 @@ func (b *PKIModelBuilder) Build(c *fi.ModelBuilderContext) error {
 		Lifecycle: b.Lifecycle,
 		Subject:   "cn=kubernetes",
 		Type:      "ca",
+		KeyType:   b.Cluster.Spec.CAKeyType,
 	}
 	c.AddTask(defaultCA)
 
@@ -146,6 +147,7 @@ func (b *PKIModelBuilder) Build(c *fi.ModelBuilderContext) error {
 			Lifecycle: b.Lifecycle,
 			Subject:   "cn=apiserver-aggregator-ca",
 			Type:      "ca",
+			KeyType:   b.Cluster.Spec.CAKeyType,
 		}
 		c.AddTask(aggregatorCA)
 	}
@@ -91,6 +91,39 @@ func ParsePEMCertificate(pemData []byte) (*Certificate, error) {
 	return c, nil
 }
 
+// ParsePEMCertificateBundle parses all the certificates in pemData, which may be a concatenation of PEM certificates.
+func ParsePEMCertificateBundle(pemData []byte) ([]*Certificate, error) {
+	var certs []*Certificate
+	for {
+		block, rest := pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+		pemData = rest
+
+		if block.Type != "CERTIFICATE" {
+			klog.Infof("Ignoring unexpected PEM block: %q", block.Type)
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing certificate %d in bundle: %v", len(certs)+1, err)
+		}
+		certs = append(certs, &Certificate{
+			Subject:     cert.Subject,
+			Certificate: cert,
+			PublicKey:   cert.PublicKey,
+			IsCA:        cert.IsCA,
+		})
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("could not parse certificate")
+	}
+	return certs, nil
+}
+
 var _ io.WriterTo = &Certificate{}
 
 func parsePEMCertificate(pemData []byte) (*x509.Certificate, error) {
@@ -165,3 +165,39 @@ func TestCertificateRoundTrip(t *testing.T) {
 		t.Fatalf("unexpected output from Certificate WriteTo: %q", b.String())
 	}
 }
+
+func TestParsePEMCertificateBundle(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	require.NoError(t, err, "GeneratePrivateKey")
+
+	var bundle bytes.Buffer
+	for _, name := range []string{"old-ca", "new-ca"} {
+		cert, err := signNewCertificate(key, &x509.Certificate{
+			Subject:               pkix.Name{CommonName: name},
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}, nil, nil)
+		require.NoError(t, err, "signNewCertificate")
+
+		_, err = cert.WriteTo(&bundle)
+		require.NoError(t, err, "Certificate WriteTo")
+	}
+
+	certs, err := ParsePEMCertificateBundle(bundle.Bytes())
+	require.NoError(t, err, "ParsePEMCertificateBundle")
+
+	var names []string
+	for _, cert := range certs {
+		names = append(names, cert.Subject.CommonName)
+		assert.True(t, cert.IsCA, "IsCA")
+	}
+	assert.Equal(t, []string{"old-ca", "new-ca"}, names)
+
+	_, err = ParsePEMCertificateBundle(nil)
+	assert.Error(t, err, "ParsePEMCertificateBundle of empty bundle")
+
+	corrupt := append(bundle.Bytes(), []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n")...)
+	_, err = ParsePEMCertificateBundle(corrupt)
+	assert.Error(t, err, "ParsePEMCertificateBundle of corrupt bundle")
+}
@@ -17,8 +17,8 @@ limitations under the License.
 package pki
 
 import (
+	"crypto"
 	crypto_rand "crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
 	"math/big"
@@ -47,9 +47,9 @@ func BuildPKISerial(timestamp int64) *big.Int {
 
 func signNewCertificate(privateKey *PrivateKey, template *x509.Certificate, signer *x509.Certificate, signerPrivateKey *PrivateKey) (*Certificate, error) {
 	if template.PublicKey == nil {
-		rsaPrivateKey, ok := privateKey.Key.(*rsa.PrivateKey)
+		keySigner, ok := privateKey.Key.(crypto.Signer)
 		if ok {
-			template.PublicKey = rsaPrivateKey.Public()
+			template.PublicKey = keySigner.Public()
 		}
 	}
 
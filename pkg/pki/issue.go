@@ -48,6 +48,8 @@ type IssueCertRequest struct {
 	PublicKey crypto.PublicKey
 	// PrivateKey is the private key for this certificate. If both this and PublicKey are nil, a new private key will be generated.
 	PrivateKey *PrivateKey
+	// KeyType is the type of private key to generate, if one is generated. The default is an RSA key.
+	KeyType string
 	// Validity is the certificate validity. The default is 10 years.
 	Validity time.Duration
 
@@ -134,7 +136,7 @@ func IssueCert(request *IssueCertRequest, keystore Keystore) (issuedCertificate
 		template.PublicKey = request.PublicKey
 	} else if privateKey == nil {
 		var err error
-		privateKey, err = GeneratePrivateKey()
+		privateKey, err = GeneratePrivateKeyOfType(request.KeyType)
 		if err != nil {
 			return nil, nil, nil, err
 		}
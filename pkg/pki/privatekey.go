@@ -19,6 +19,8 @@ package pki
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	crypto_rand "crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -33,6 +35,18 @@ import (
 	"k8s.io/klog/v2"
 )
 
+const (
+	// PrivateKeyTypeRSA2048 is a 2048-bit RSA private key
+	PrivateKeyTypeRSA2048 = "rsa-2048"
+	// PrivateKeyTypeRSA4096 is a 4096-bit RSA private key
+	PrivateKeyTypeRSA4096 = "rsa-4096"
+	// PrivateKeyTypeECDSAP256 is an ECDSA private key using the P-256 curve
+	PrivateKeyTypeECDSAP256 = "ecdsa-p256"
+)
+
+// SupportedPrivateKeyTypes is the list of private key types that can be generated
+var SupportedPrivateKeyTypes = []string{PrivateKeyTypeRSA2048, PrivateKeyTypeRSA4096, PrivateKeyTypeECDSAP256}
+
 // DefaultPrivateKeySize is the key size to use when generating private keys
 // It can be overridden by the KOPS_RSA_PRIVATE_KEY_SIZE env var, or by tests
 // (as generating RSA keys can be a bottleneck for testing)
@@ -71,6 +85,33 @@ func GeneratePrivateKey() (*PrivateKey, error) {
 	return privateKey, nil
 }
 
+// GeneratePrivateKeyOfType generates a private key using the specified algorithm.
+// An empty keyType generates an RSA key of the default size, as GeneratePrivateKey does.
+func GeneratePrivateKeyOfType(keyType string) (*PrivateKey, error) {
+	switch keyType {
+	case "":
+		return GeneratePrivateKey()
+	case PrivateKeyTypeRSA2048, PrivateKeyTypeRSA4096:
+		rsaKeySize := 2048
+		if keyType == PrivateKeyTypeRSA4096 {
+			rsaKeySize = 4096
+		}
+		rsaKey, err := rsa.GenerateKey(crypto_rand.Reader, rsaKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("error generating RSA private key: %v", err)
+		}
+		return &PrivateKey{Key: rsaKey}, nil
+	case PrivateKeyTypeECDSAP256:
+		ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), crypto_rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("error generating ECDSA private key: %v", err)
+		}
+		return &PrivateKey{Key: ecdsaKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown private key type %q", keyType)
+	}
+}
+
 type PrivateKey struct {
 	Key crypto.PrivateKey
 }
@@ -154,6 +195,12 @@ func (k *PrivateKey) WriteTo(w io.Writer) (int64, error) {
 	switch pk := k.Key.(type) {
 	case *rsa.PrivateKey:
 		err = pem.Encode(w, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(pk)})
+	case *ecdsa.PrivateKey:
+		der, marshalErr := x509.MarshalECPrivateKey(pk)
+		if marshalErr != nil {
+			return 0, fmt.Errorf("error marshalling ECDSA private key: %v", marshalErr)
+		}
+		err = pem.Encode(w, &pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
 	default:
 		return 0, fmt.Errorf("unknown private key type: %T", k.Key)
 	}
@@ -187,6 +234,9 @@ func parsePEMPrivateKey(pemData []byte) (crypto.PrivateKey, error) {
 		if block.Type == "RSA PRIVATE KEY" {
 			klog.V(10).Infof("Parsing pem block: %q", block.Type)
 			return x509.ParsePKCS1PrivateKey(block.Bytes)
+		} else if block.Type == "EC PRIVATE KEY" {
+			klog.V(10).Infof("Parsing pem block: %q", block.Type)
+			return x509.ParseECPrivateKey(block.Bytes)
 		} else if block.Type == "PRIVATE KEY" {
 			klog.V(10).Infof("Parsing pem block: %q", block.Type)
 			k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
@@ -19,6 +19,7 @@ package pki
 import (
 	"bytes"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -47,3 +48,52 @@ func TestPrivateKeyRoundTrip(t *testing.T) {
 		t.Fatalf("unexpected output from PrivateKey WriteTo: %q", b.String())
 	}
 }
+
+func TestGeneratePrivateKeyOfType(t *testing.T) {
+	grid := []struct {
+		KeyType  string
+		Expected string
+	}{
+		{
+			KeyType:  PrivateKeyTypeRSA2048,
+			Expected: "RSA PRIVATE KEY",
+		},
+		{
+			KeyType:  PrivateKeyTypeECDSAP256,
+			Expected: "EC PRIVATE KEY",
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.KeyType, func(t *testing.T) {
+			key, err := GeneratePrivateKeyOfType(g.KeyType)
+			if err != nil {
+				t.Fatalf("error from GeneratePrivateKeyOfType: %v", err)
+			}
+
+			var b bytes.Buffer
+			if _, err := key.WriteTo(&b); err != nil {
+				t.Fatalf("error from PrivateKey WriteTo: %v", err)
+			}
+			if !strings.Contains(b.String(), "-----BEGIN "+g.Expected+"-----") {
+				t.Fatalf("unexpected output from PrivateKey WriteTo: %q", b.String())
+			}
+
+			parsed, err := ParsePEMPrivateKey(b.Bytes())
+			if err != nil {
+				t.Fatalf("error from ParsePEMPrivateKey: %v", err)
+			}
+
+			var roundTrip bytes.Buffer
+			if _, err := parsed.WriteTo(&roundTrip); err != nil {
+				t.Fatalf("error from PrivateKey WriteTo: %v", err)
+			}
+			if roundTrip.String() != b.String() {
+				t.Fatalf("private key did not round-trip: %q", roundTrip.String())
+			}
+		})
+	}
+
+	if _, err := GeneratePrivateKeyOfType("dsa-1024"); err == nil {
+		t.Fatalf("expected error generating unknown key type")
+	}
+}
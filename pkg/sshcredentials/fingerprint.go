@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"crypto/md5"
 	"fmt"
+	"strings"
 
 	"golang.org/x/crypto/ssh"
 )
@@ -52,3 +53,9 @@ func formatFingerprint(data []byte) string {
 	}
 	return buf.String()
 }
+
+// NormalizeFingerprint returns the fingerprint in the form used to identify stored keys,
+// which is lower-case and without colon separators.
+func NormalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.Replace(fingerprint, ":", "", -1))
+}
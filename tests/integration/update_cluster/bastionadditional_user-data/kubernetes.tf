@@ -354,7 +354,7 @@ resource "aws_elb" "bastion-bastionuserdata-example-com" {
     lb_protocol       = "TCP"
   }
   name            = "bastion-bastionuserdata-e-4grhsv"
-  security_groups = [aws_security_group.bastion-elb-bastionuserdata-example-com.id, "sg-exampleid"]
+  security_groups = [aws_security_group.bastion-elb-bastionuserdata-example-com.id, "sg-0123456789abcdef"]
   subnets         = [aws_subnet.utility-us-test-1a-bastionuserdata-example-com.id]
   tags = {
     "KubernetesCluster"                                 = "bastionuserdata.example.com"
@@ -1,11 +1,11 @@
 locals {
   cluster_name                      = "complex.example.com"
   master_autoscaling_group_ids      = [aws_autoscaling_group.master-us-test-1a-masters-complex-example-com.id]
-  master_security_group_ids         = [aws_security_group.masters-complex-example-com.id, "sg-exampleid5", "sg-exampleid6"]
+  master_security_group_ids         = [aws_security_group.masters-complex-example-com.id, "sg-0123456789abcdef5", "sg-0123456789abcdef6"]
   masters_role_arn                  = aws_iam_role.masters-complex-example-com.arn
   masters_role_name                 = aws_iam_role.masters-complex-example-com.name
   node_autoscaling_group_ids        = [aws_autoscaling_group.nodes-complex-example-com.id]
-  node_security_group_ids           = [aws_security_group.nodes-complex-example-com.id, "sg-exampleid3", "sg-exampleid4"]
+  node_security_group_ids           = [aws_security_group.nodes-complex-example-com.id, "sg-0123456789abcdef3", "sg-0123456789abcdef4"]
   node_subnet_ids                   = [aws_subnet.us-test-1a-complex-example-com.id]
   nodes_role_arn                    = aws_iam_role.nodes-complex-example-com.arn
   nodes_role_name                   = aws_iam_role.nodes-complex-example-com.name
@@ -28,7 +28,7 @@ output "master_autoscaling_group_ids" {
 }
 
 output "master_security_group_ids" {
-  value = [aws_security_group.masters-complex-example-com.id, "sg-exampleid5", "sg-exampleid6"]
+  value = [aws_security_group.masters-complex-example-com.id, "sg-0123456789abcdef5", "sg-0123456789abcdef6"]
 }
 
 output "masters_role_arn" {
@@ -44,7 +44,7 @@ output "node_autoscaling_group_ids" {
 }
 
 output "node_security_group_ids" {
-  value = [aws_security_group.nodes-complex-example-com.id, "sg-exampleid3", "sg-exampleid4"]
+  value = [aws_security_group.nodes-complex-example-com.id, "sg-0123456789abcdef3", "sg-0123456789abcdef4"]
 }
 
 output "node_subnet_ids" {
@@ -374,7 +374,7 @@ resource "aws_launch_template" "master-us-test-1a-masters-complex-example-com" {
     associate_public_ip_address = true
     delete_on_termination       = true
     ipv6_address_count          = 0
-    security_groups             = [aws_security_group.masters-complex-example-com.id, "sg-exampleid5", "sg-exampleid6"]
+    security_groups             = [aws_security_group.masters-complex-example-com.id, "sg-0123456789abcdef5", "sg-0123456789abcdef6"]
   }
   tag_specifications {
     resource_type = "instance"
@@ -473,7 +473,7 @@ resource "aws_launch_template" "nodes-complex-example-com" {
     associate_public_ip_address = true
     delete_on_termination       = true
     ipv6_address_count          = 0
-    security_groups             = [aws_security_group.nodes-complex-example-com.id, "sg-exampleid3", "sg-exampleid4"]
+    security_groups             = [aws_security_group.nodes-complex-example-com.id, "sg-0123456789abcdef3", "sg-0123456789abcdef4"]
   }
   tag_specifications {
     resource_type = "instance"
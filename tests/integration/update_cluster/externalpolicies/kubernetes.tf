@@ -5,7 +5,7 @@ locals {
   masters_role_arn             = aws_iam_role.masters-externalpolicies-example-com.arn
   masters_role_name            = aws_iam_role.masters-externalpolicies-example-com.name
   node_autoscaling_group_ids   = [aws_autoscaling_group.nodes-externalpolicies-example-com.id]
-  node_security_group_ids      = [aws_security_group.nodes-externalpolicies-example-com.id, "sg-exampleid3", "sg-exampleid4"]
+  node_security_group_ids      = [aws_security_group.nodes-externalpolicies-example-com.id, "sg-0123456789abcdef3", "sg-0123456789abcdef4"]
   node_subnet_ids              = [aws_subnet.us-test-1a-externalpolicies-example-com.id]
   nodes_role_arn               = aws_iam_role.nodes-externalpolicies-example-com.arn
   nodes_role_name              = aws_iam_role.nodes-externalpolicies-example-com.name
@@ -41,7 +41,7 @@ output "node_autoscaling_group_ids" {
 }
 
 output "node_security_group_ids" {
-  value = [aws_security_group.nodes-externalpolicies-example-com.id, "sg-exampleid3", "sg-exampleid4"]
+  value = [aws_security_group.nodes-externalpolicies-example-com.id, "sg-0123456789abcdef3", "sg-0123456789abcdef4"]
 }
 
 output "node_subnet_ids" {
@@ -266,7 +266,7 @@ resource "aws_elb" "api-externalpolicies-example-com" {
     lb_protocol       = "TCP"
   }
   name            = "api-externalpolicies-exam-5cse45"
-  security_groups = [aws_security_group.api-elb-externalpolicies-example-com.id, "sg-exampleid3", "sg-exampleid4"]
+  security_groups = [aws_security_group.api-elb-externalpolicies-example-com.id, "sg-0123456789abcdef3", "sg-0123456789abcdef4"]
   subnets         = [aws_subnet.us-test-1a-externalpolicies-example-com.id]
   tags = {
     "KubernetesCluster"                                  = "externalpolicies.example.com"
@@ -492,7 +492,7 @@ resource "aws_launch_template" "nodes-externalpolicies-example-com" {
     associate_public_ip_address = true
     delete_on_termination       = true
     ipv6_address_count          = 0
-    security_groups             = [aws_security_group.nodes-externalpolicies-example-com.id, "sg-exampleid3", "sg-exampleid4"]
+    security_groups             = [aws_security_group.nodes-externalpolicies-example-com.id, "sg-0123456789abcdef3", "sg-0123456789abcdef4"]
   }
   tag_specifications {
     resource_type = "instance"
@@ -91,6 +91,10 @@ func (r *assetResource) GetSource() *Source {
 type AssetStore struct {
 	cacheDir string
 	assets   []*asset
+
+	// StrictVerification causes an asset whose downloaded content does not match its hash
+	// to fail immediately, rather than falling back to the asset's other URLs.
+	StrictVerification bool
 }
 
 func NewAssetStore(cacheDir string) *AssetStore {
@@ -245,6 +249,9 @@ func (a *AssetStore) addURLs(urls []string, hash *hashing.Hash) error {
 	for _, url := range urls {
 		_, err = DownloadURL(url, localFile, hash)
 		if err != nil {
+			if _, ok := err.(*HashMismatchError); ok && a.StrictVerification {
+				return fmt.Errorf("strict verification of asset %q failed: %v", primaryURL, err)
+			}
 			klog.Warningf("error downloading url %q: %v", url, err)
 			continue
 		} else {
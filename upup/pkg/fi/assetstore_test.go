@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func TestAssetStoreStrictVerification(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good/kubelet":
+			w.Write([]byte("kubelet"))
+		case "/bad/kubelet":
+			w.Write([]byte("tampered"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	hash, err := hashing.HashAlgorithmSHA256.Hash(strings.NewReader("kubelet"))
+	if err != nil {
+		t.Fatalf("error hashing asset: %v", err)
+	}
+	asset := hash.String() + "@" + server.URL + "/bad/kubelet," + server.URL + "/good/kubelet"
+
+	for _, strict := range []bool{false, true} {
+		cacheDir, err := ioutil.TempDir("", "assetstore")
+		if err != nil {
+			t.Fatalf("error creating temp dir: %v", err)
+		}
+		defer os.RemoveAll(cacheDir)
+
+		store := NewAssetStore(cacheDir)
+		store.StrictVerification = strict
+
+		err = store.Add(asset)
+		if strict {
+			if err == nil {
+				t.Errorf("expected error adding asset with mismatched hash under strict verification")
+			} else if !strings.Contains(err.Error(), server.URL+"/bad/kubelet") {
+				t.Errorf("expected error to identify the asset, got %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error adding asset: %v", err)
+		}
+	}
+}
@@ -24,9 +24,12 @@ import (
 	"math/big"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/sshcredentials"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -104,8 +107,45 @@ type CAStore interface {
 	// The key material is not guaranteed to be populated - metadata like the name will be.
 	ListKeysets() ([]*kops.Keyset, error)
 
+	// ListKeysetsByType will return the KeySets of the specified type
+	// As with ListKeysets, the key material is not guaranteed to be populated.
+	ListKeysetsByType(t kops.KeysetType) ([]*kops.Keyset, error)
+
 	// DeleteKeysetItem will delete the specified item from the Keyset
 	DeleteKeysetItem(item *kops.Keyset, id string) error
+
+	// PromoteToPrimary makes the item with the specified id the primary item of the named Keyset
+	PromoteToPrimary(name string, id string) error
+
+	// ExportClientBundle returns the PEM-encoded primary certificate of the caName Keyset,
+	// and the PEM-encoded primary certificate and private key of the clientName Keyset, e.g. for embedding in a kubeconfig.
+	ExportClientBundle(caName, clientName string) (caCert []byte, clientCert []byte, clientKey []byte, err error)
+
+	// StoreKeysets writes the Keysets, keyed by name, to the store.
+	// Each Keyset is stored independently, so a failure to store one does not prevent storing the others.
+	// If any fail, the error is a *StoreKeysetsError naming them; storing those Keysets again is safe.
+	StoreKeysets(keysets map[string]*Keyset) error
+}
+
+// StoreKeysetsError is returned by StoreKeysets when some of the Keysets could not be stored.
+// The Keysets that are not in Errors were stored successfully.
+type StoreKeysetsError struct {
+	// Errors holds the error storing each failed Keyset, keyed by name.
+	Errors map[string]error
+}
+
+func (e *StoreKeysetsError) Error() string {
+	var names []string
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var messages []string
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("keyset %q: %v", name, e.Errors[name]))
+	}
+	return fmt.Sprintf("error storing %d keysets: %s", len(names), strings.Join(messages, "; "))
 }
 
 // SSHCredentialStore holds SSHCredential objects
@@ -113,6 +153,10 @@ type SSHCredentialStore interface {
 	// DeleteSSHCredential deletes the specified SSH credential
 	DeleteSSHCredential(item *kops.SSHCredential) error
 
+	// DeleteSSHCredentialByFingerprint deletes the SSH public key with the specific name and fingerprint.
+	// It is not an error if there is no such key.
+	DeleteSSHCredentialByFingerprint(name string, fingerprint string) error
+
 	// ListSSHCredentials will list all the SSH credentials
 	ListSSHCredentials() ([]*kops.SSHCredential, error)
 
@@ -121,6 +165,9 @@ type SSHCredentialStore interface {
 
 	// FindSSHPublicKeys retrieves the SSH public keys with the specific name
 	FindSSHPublicKeys(name string) ([]*kops.SSHCredential, error)
+
+	// FindSSHPublicKeyByFingerprint retrieves the SSH public key with the specific name and fingerprint, or nil if there is none
+	FindSSHPublicKeyByFingerprint(name string, fingerprint string) (*kops.SSHCredential, error)
 }
 
 type CertificatePool struct {
@@ -173,6 +220,130 @@ func FindPrimaryKeypair(c Keystore, name string) (*pki.Certificate, *pki.Private
 	return keyset.Primary.Certificate, keyset.Primary.PrivateKey, nil
 }
 
+// promoteToPrimary loads the named Keyset, makes the item with the specified id its primary item, and stores it.
+func promoteToPrimary(c Keystore, name string, id string) error {
+	keyset, err := c.FindKeyset(name)
+	if err != nil {
+		return err
+	}
+	if keyset == nil {
+		return fmt.Errorf("keyset %q not found", name)
+	}
+
+	item := keyset.Items[id]
+	if item == nil {
+		return fmt.Errorf("keyset %q has no item with id %q", name, id)
+	}
+	if item.PrivateKey == nil {
+		return fmt.Errorf("keyset %q item %q has no private key", name, id)
+	}
+
+	keyset.Primary = item
+	return c.StoreKeyset(name, keyset)
+}
+
+// maxConcurrentKeysetWrites is the maximum number of Keysets that storeKeysets writes at the same time.
+const maxConcurrentKeysetWrites = 8
+
+// storeKeysets is a common implementation of CAStore::StoreKeysets.
+// It calls StoreKeyset for each Keyset, with bounded concurrency.
+func storeKeysets(c Keystore, keysets map[string]*Keyset) error {
+	var mutex sync.Mutex
+	errs := make(map[string]error)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentKeysetWrites)
+	for name, keyset := range keysets {
+		name, keyset := name, keyset
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := c.StoreKeyset(name, keyset); err != nil {
+				mutex.Lock()
+				errs[name] = err
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) != 0 {
+		return &StoreKeysetsError{Errors: errs}
+	}
+	return nil
+}
+
+// exportClientBundle is a common implementation of CAStore::ExportClientBundle.
+func exportClientBundle(c Keystore, caName, clientName string) ([]byte, []byte, []byte, error) {
+	caKeyset, err := c.FindKeyset(caName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading keyset %q: %v", caName, err)
+	}
+	if caKeyset == nil {
+		return nil, nil, nil, fmt.Errorf("keyset %q not found", caName)
+	}
+	if caKeyset.Primary == nil || caKeyset.Primary.Certificate == nil {
+		return nil, nil, nil, fmt.Errorf("keyset %q has no primary certificate", caName)
+	}
+
+	clientKeyset, err := c.FindKeyset(clientName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading keyset %q: %v", clientName, err)
+	}
+	if clientKeyset == nil {
+		return nil, nil, nil, fmt.Errorf("keyset %q not found", clientName)
+	}
+	if clientKeyset.Primary == nil || clientKeyset.Primary.Certificate == nil {
+		return nil, nil, nil, fmt.Errorf("keyset %q has no primary certificate", clientName)
+	}
+	if clientKeyset.Primary.PrivateKey == nil {
+		return nil, nil, nil, fmt.Errorf("keyset %q has no private key for its primary certificate", clientName)
+	}
+
+	caCert, err := caKeyset.Primary.Certificate.AsBytes()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error encoding certificate %q: %v", caName, err)
+	}
+	clientCert, err := clientKeyset.Primary.Certificate.AsBytes()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error encoding certificate %q: %v", clientName, err)
+	}
+	clientKey, err := clientKeyset.Primary.PrivateKey.AsBytes()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error encoding private key %q: %v", clientName, err)
+	}
+
+	return caCert, clientCert, clientKey, nil
+}
+
+// findSSHPublicKeyByFingerprint returns the named SSH public key with the specified fingerprint, or nil if there is none.
+// Fingerprints are compared without their colon separators, so they may be given in either form.
+func findSSHPublicKeyByFingerprint(c SSHCredentialStore, name string, fingerprint string) (*kops.SSHCredential, error) {
+	sshCredentials, err := c.FindSSHPublicKeys(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint = sshcredentials.NormalizeFingerprint(fingerprint)
+	for _, sshCredential := range sshCredentials {
+		id, err := sshcredentials.Fingerprint(sshCredential.Spec.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("error fingerprinting SSH public key %q: %v", sshCredential.Name, err)
+		}
+		if sshcredentials.NormalizeFingerprint(id) == fingerprint {
+			return sshCredential, nil
+		}
+	}
+
+	return nil, nil
+}
+
 // AddCert adds an alternative certificate to the keyset (primarily useful for CAs)
 func AddCert(keyset *Keyset, cert *pki.Certificate) {
 	serial := 0
@@ -204,6 +375,20 @@ func KeysetItemIdOlder(a, b string) bool {
 	}
 }
 
+// ItemIds returns the ids of the keyset's items in rotation order.
+// The order is stable: items are sorted from oldest to newest according to KeysetItemIdOlder,
+// so the most recently added item is last.
+func (k *Keyset) ItemIds() []string {
+	ids := make([]string, 0, len(k.Items))
+	for _, item := range k.Items {
+		ids = append(ids, item.Id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return KeysetItemIdOlder(ids[i], ids[j])
+	})
+	return ids
+}
+
 func (k *Keyset) ToPublicKeyBytes() ([]byte, error) {
 	keys := make([]string, 0, len(k.Items))
 	for k := range k.Items {
@@ -0,0 +1,329 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/clientset_generated/clientset/fake"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestKeysetItemIds(t *testing.T) {
+	ids := []string{
+		"6979948095012838434932339817",
+		"6979948095012838434932339816",
+		"2",
+		"37",
+		"100000000000000000000000000000000000",
+		"legacy",
+	}
+
+	keyset := &Keyset{
+		Items: map[string]*KeysetItem{},
+	}
+	for _, id := range ids {
+		keyset.Items[id] = &KeysetItem{Id: id}
+	}
+
+	expected := []string{
+		"legacy",
+		"2",
+		"37",
+		"6979948095012838434932339816",
+		"6979948095012838434932339817",
+		"100000000000000000000000000000000000",
+	}
+
+	// The order must not depend on map iteration order
+	for i := 0; i < 10; i++ {
+		actual := keyset.ItemIds()
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("unexpected item ids, expected %v, got %v", expected, actual)
+		}
+	}
+}
+
+// memKeystore is a Keystore that holds Keysets in memory.
+type memKeystore struct {
+	keysets map[string]*Keyset
+}
+
+var _ Keystore = &memKeystore{}
+
+func (k *memKeystore) FindPrimaryKeypair(name string) (*pki.Certificate, *pki.PrivateKey, error) {
+	return FindPrimaryKeypair(k, name)
+}
+
+func (k *memKeystore) FindKeyset(name string) (*Keyset, error) {
+	keyset := k.keysets[name]
+	if keyset == nil {
+		return nil, nil
+	}
+	// Return a copy, as a real store would
+	c := &Keyset{
+		Items: map[string]*KeysetItem{},
+	}
+	for id, item := range keyset.Items {
+		c.Items[id] = item
+	}
+	if keyset.Primary != nil {
+		c.Primary = c.Items[keyset.Primary.Id]
+	}
+	return c, nil
+}
+
+func (k *memKeystore) StoreKeyset(name string, keyset *Keyset) error {
+	k.keysets[name] = keyset
+	return nil
+}
+
+func (k *memKeystore) MirrorTo(basedir vfs.Path) error {
+	panic("memKeystore does not implement MirrorTo")
+}
+
+// promotingKeystore is a Keystore which can change the primary item of a keyset.
+type promotingKeystore interface {
+	Keystore
+	PromoteToPrimary(name string, id string) error
+}
+
+func (k *memKeystore) PromoteToPrimary(name string, id string) error {
+	return promoteToPrimary(k, name, id)
+}
+
+func TestPromoteToPrimary(t *testing.T) {
+	privateKey, err := pki.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("error generating private key: %v", err)
+	}
+
+	keyset := &Keyset{
+		Items: map[string]*KeysetItem{},
+	}
+	for _, id := range []string{"1", "2", "3", "4"} {
+		serial, _ := big.NewInt(0).SetString(id, 10)
+		cert, _, _, err := pki.IssueCert(&pki.IssueCertRequest{
+			Type:       "ca",
+			Subject:    pkix.Name{CommonName: "kubernetes-ca"},
+			PrivateKey: privateKey,
+			Serial:     serial,
+		}, nil)
+		if err != nil {
+			t.Fatalf("error issuing certificate: %v", err)
+		}
+		keyset.Items[id] = &KeysetItem{Id: id, Certificate: cert, PrivateKey: privateKey}
+	}
+	// Item 4 has only a certificate, so cannot be the primary
+	keyset.Items["4"].PrivateKey = nil
+	keyset.Primary = keyset.Items["1"]
+
+	for _, g := range []struct {
+		name     string
+		newStore func(t *testing.T) promotingKeystore
+	}{
+		{
+			name: "memory",
+			newStore: func(t *testing.T) promotingKeystore {
+				return &memKeystore{keysets: map[string]*Keyset{}}
+			},
+		},
+		{
+			name: "vfs",
+			newStore: func(t *testing.T) promotingKeystore {
+				vfs.Context.ResetMemfsContext(true)
+				basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+				if err != nil {
+					t.Fatalf("error building vfspath: %v", err)
+				}
+				return NewVFSCAStore(&kops.Cluster{}, basePath)
+			},
+		},
+		{
+			name: "clientset",
+			newStore: func(t *testing.T) promotingKeystore {
+				return NewClientsetCAStore(&kops.Cluster{}, fake.NewSimpleClientset().Kops(), "default").(*ClientsetCAStore)
+			},
+		},
+	} {
+		t.Run(g.name, func(t *testing.T) {
+			store := g.newStore(t)
+			if err := store.StoreKeyset("ca", keyset); err != nil {
+				t.Fatalf("error from StoreKeyset: %v", err)
+			}
+
+			if err := store.PromoteToPrimary("ca", "3"); err != nil {
+				t.Fatalf("unexpected error from PromoteToPrimary: %v", err)
+			}
+
+			actual, err := store.FindKeyset("ca")
+			if err != nil {
+				t.Fatalf("unexpected error from FindKeyset: %v", err)
+			}
+			if actual.Primary == nil || actual.Primary.Id != "3" {
+				t.Errorf("expected primary item 3, got %v", actual.Primary)
+			}
+			expectedIds := []string{"1", "2", "3", "4"}
+			if ids := actual.ItemIds(); !reflect.DeepEqual(ids, expectedIds) {
+				t.Errorf("expected items %v to remain, got %v", expectedIds, ids)
+			}
+
+			for _, promotion := range []struct {
+				name string
+				id   string
+			}{
+				{name: "ca", id: "5"},
+				{name: "ca", id: "4"},
+				{name: "other", id: "1"},
+			} {
+				if err := store.PromoteToPrimary(promotion.name, promotion.id); err == nil {
+					t.Errorf("expected error promoting %q in keyset %q", promotion.id, promotion.name)
+				}
+			}
+
+			actual, err = store.FindKeyset("ca")
+			if err != nil {
+				t.Fatalf("unexpected error from FindKeyset: %v", err)
+			}
+			if actual.Primary == nil || actual.Primary.Id != "3" {
+				t.Errorf("expected primary item to remain 3 after failed promotions, got %v", actual.Primary)
+			}
+		})
+	}
+}
+
+func TestExportClientBundle(t *testing.T) {
+	store := &memKeystore{
+		keysets: map[string]*Keyset{},
+	}
+
+	for _, req := range []*pki.IssueCertRequest{
+		{Type: "ca", Subject: pkix.Name{CommonName: "kubernetes-ca"}},
+		{Type: "client", Signer: "ca", Subject: pkix.Name{CommonName: "admin"}},
+	} {
+		cert, key, _, err := pki.IssueCert(req, store)
+		if err != nil {
+			t.Fatalf("error issuing %s certificate: %v", req.Type, err)
+		}
+		item := &KeysetItem{Id: cert.Certificate.SerialNumber.String(), Certificate: cert, PrivateKey: key}
+		store.keysets[req.Type] = &Keyset{
+			Items:   map[string]*KeysetItem{item.Id: item},
+			Primary: item,
+		}
+	}
+	nokey := &KeysetItem{Id: "1", Certificate: store.keysets["client"].Primary.Certificate}
+	store.keysets["nokey"] = &Keyset{
+		Items:   map[string]*KeysetItem{nokey.Id: nokey},
+		Primary: nokey,
+	}
+	store.keysets["noprimary"] = &Keyset{
+		Items: map[string]*KeysetItem{},
+	}
+
+	caCert, clientCert, clientKey, err := exportClientBundle(store, "ca", "client")
+	if err != nil {
+		t.Fatalf("unexpected error from exportClientBundle: %v", err)
+	}
+
+	for _, g := range []struct {
+		name     string
+		actual   []byte
+		expected interface{ AsBytes() ([]byte, error) }
+	}{
+		{name: "CA certificate", actual: caCert, expected: store.keysets["ca"].Primary.Certificate},
+		{name: "client certificate", actual: clientCert, expected: store.keysets["client"].Primary.Certificate},
+		{name: "client key", actual: clientKey, expected: store.keysets["client"].Primary.PrivateKey},
+	} {
+		expected, err := g.expected.AsBytes()
+		if err != nil {
+			t.Fatalf("error encoding %s: %v", g.name, err)
+		}
+		if !reflect.DeepEqual(g.actual, expected) {
+			t.Errorf("unexpected %s: expected %q, got %q", g.name, expected, g.actual)
+		}
+	}
+
+	for _, g := range []struct {
+		caName     string
+		clientName string
+	}{
+		{caName: "missing", clientName: "client"},
+		{caName: "ca", clientName: "missing"},
+		{caName: "noprimary", clientName: "client"},
+		{caName: "ca", clientName: "noprimary"},
+		{caName: "ca", clientName: "nokey"},
+	} {
+		if _, _, _, err := exportClientBundle(store, g.caName, g.clientName); err == nil {
+			t.Errorf("expected error exporting bundle for CA %q and client %q", g.caName, g.clientName)
+		}
+	}
+}
+
+// concurrencyLimitKeystore is a memKeystore which records the most concurrent calls to StoreKeyset.
+type concurrencyLimitKeystore struct {
+	memKeystore
+
+	mutex         sync.Mutex
+	current       int
+	maxConcurrent int
+}
+
+func (k *concurrencyLimitKeystore) StoreKeyset(name string, keyset *Keyset) error {
+	k.mutex.Lock()
+	k.current++
+	if k.current > k.maxConcurrent {
+		k.maxConcurrent = k.current
+	}
+	k.mutex.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.current--
+	return k.memKeystore.StoreKeyset(name, keyset)
+}
+
+func TestStoreKeysets(t *testing.T) {
+	store := &concurrencyLimitKeystore{
+		memKeystore: memKeystore{keysets: map[string]*Keyset{}},
+	}
+
+	keysets := map[string]*Keyset{}
+	for i := 0; i < 3*maxConcurrentKeysetWrites; i++ {
+		item := &KeysetItem{Id: "1", PrivateKey: &pki.PrivateKey{}}
+		keysets[fmt.Sprintf("keyset-%d", i)] = &Keyset{Items: map[string]*KeysetItem{"1": item}, Primary: item}
+	}
+
+	if err := storeKeysets(store, keysets); err != nil {
+		t.Fatalf("unexpected error from storeKeysets: %v", err)
+	}
+	if !reflect.DeepEqual(store.keysets, keysets) {
+		t.Errorf("expected all keysets to be stored, got %d of %d", len(store.keysets), len(keysets))
+	}
+	if store.maxConcurrent > maxConcurrentKeysetWrites {
+		t.Errorf("expected at most %d concurrent writes, got %d", maxConcurrentKeysetWrites, store.maxConcurrent)
+	}
+}
@@ -238,6 +238,67 @@ func (c *ClientsetCAStore) ListKeysets() ([]*kops.Keyset, error) {
 	return items, nil
 }
 
+// KeysetMetadata identifies a Keyset, without any of its key material
+type KeysetMetadata struct {
+	Name      string
+	Type      kops.KeysetType
+	PrimaryID string
+}
+
+// keysetListPageSize is the number of Keysets requested from the API server at a time by ListKeysetMetadata
+const keysetListPageSize = 100
+
+// ListKeysetMetadata returns the names, types and primary ids of all the Keysets.
+// It does not include any certificate or key material, which is discarded as each page of Keysets is listed,
+// so callers needing only to enumerate Keysets do not hold the material for all of them in memory.
+func (c *ClientsetCAStore) ListKeysetMetadata() ([]*KeysetMetadata, error) {
+	ctx := context.TODO()
+
+	var items []*KeysetMetadata
+	options := metav1.ListOptions{Limit: keysetListPageSize}
+	for {
+		list, err := c.clientset.Keysets(c.namespace).List(ctx, options)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Keysets: %v", err)
+		}
+
+		for i := range list.Items {
+			keyset := &list.Items[i]
+			items = append(items, &KeysetMetadata{
+				Name:      keyset.Name,
+				Type:      keyset.Spec.Type,
+				PrimaryID: keyset.Spec.PrimaryId,
+			})
+		}
+
+		if list.Continue == "" {
+			break
+		}
+		options.Continue = list.Continue
+	}
+
+	return items, nil
+}
+
+// ListKeysetsByType implements CAStore::ListKeysetsByType
+func (c *ClientsetCAStore) ListKeysetsByType(t kops.KeysetType) ([]*kops.Keyset, error) {
+	ctx := context.TODO()
+
+	list, err := c.clientset.Keysets(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing Keysets: %v", err)
+	}
+
+	var items []*kops.Keyset
+	for i := range list.Items {
+		if list.Items[i].Spec.Type == t {
+			items = append(items, &list.Items[i])
+		}
+	}
+
+	return items, nil
+}
+
 // ListSSHCredentials implements SSHCredentialStore::ListSSHCredentials
 func (c *ClientsetCAStore) ListSSHCredentials() ([]*kops.SSHCredential, error) {
 	ctx := context.TODO()
@@ -264,6 +325,12 @@ func (c *ClientsetCAStore) StoreKeyset(name string, keyset *Keyset) error {
 	return c.storeKeyset(ctx, name, keyset, kops.SecretTypeKeypair)
 }
 
+// StoreKeysets implements CAStore::StoreKeysets
+// Each Keyset is a separate object, so they are written with concurrent requests.
+func (c *ClientsetCAStore) StoreKeysets(keysets map[string]*Keyset) error {
+	return storeKeysets(c, keysets)
+}
+
 // FindPrivateKey implements CAStore::FindPrivateKey
 func (c *ClientsetCAStore) FindPrivateKey(name string) (*pki.PrivateKey, error) {
 	ctx := context.TODO()
@@ -323,22 +390,11 @@ func (c *ClientsetCAStore) storeKeyset(ctx context.Context, name string, keyset
 	})
 
 	for _, key := range keys {
-		item := keyset.Items[key]
-		var publicMaterial bytes.Buffer
-		if _, err := item.Certificate.WriteTo(&publicMaterial); err != nil {
-			return err
-		}
-
-		var privateMaterial bytes.Buffer
-		if _, err := item.PrivateKey.WriteTo(&privateMaterial); err != nil {
+		kopsItem, err := keysetItemToAPI(keyset.Items[key])
+		if err != nil {
 			return err
 		}
-
-		kopsKeyset.Spec.Keys = append(kopsKeyset.Spec.Keys, kops.KeysetItem{
-			Id:              item.Id,
-			PublicMaterial:  publicMaterial.Bytes(),
-			PrivateMaterial: privateMaterial.Bytes(),
-		})
+		kopsKeyset.Spec.Keys = append(kopsKeyset.Spec.Keys, *kopsItem)
 	}
 
 	if create {
@@ -353,6 +409,80 @@ func (c *ClientsetCAStore) storeKeyset(ctx context.Context, name string, keyset
 	return nil
 }
 
+// keysetItemToAPI serializes the KeysetItem for storage in a Keyset.
+func keysetItemToAPI(item *KeysetItem) (*kops.KeysetItem, error) {
+	var publicMaterial bytes.Buffer
+	if _, err := item.Certificate.WriteTo(&publicMaterial); err != nil {
+		return nil, err
+	}
+
+	var privateMaterial bytes.Buffer
+	if item.PrivateKey != nil {
+		if _, err := item.PrivateKey.WriteTo(&privateMaterial); err != nil {
+			return nil, err
+		}
+	}
+
+	return &kops.KeysetItem{
+		Id:              item.Id,
+		PublicMaterial:  publicMaterial.Bytes(),
+		PrivateMaterial: privateMaterial.Bytes(),
+	}, nil
+}
+
+// AddKeysetItem adds the item to the named keyset, leaving the existing items and the primary unchanged.
+// If the keyset does not exist it is created, with the item as its primary.
+// If the keyset is modified concurrently a conflict error (see errors.IsConflict) is returned, and the caller should retry.
+func (c *ClientsetCAStore) AddKeysetItem(name string, item *KeysetItem) error {
+	ctx := context.TODO()
+	client := c.clientset.Keysets(c.namespace)
+
+	kopsItem, err := keysetItemToAPI(item)
+	if err != nil {
+		return err
+	}
+
+	kopsKeyset, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("error reading keyset %q: %v", name, err)
+		}
+
+		kopsKeyset = &kops.Keyset{}
+		kopsKeyset.Name = name
+		kopsKeyset.Spec.Type = kops.SecretTypeKeypair
+		kopsKeyset.Spec.PrimaryId = item.Id
+		kopsKeyset.Spec.Keys = []kops.KeysetItem{*kopsItem}
+
+		if _, err := client.Create(ctx, kopsKeyset, metav1.CreateOptions{}); err != nil {
+			if errors.IsAlreadyExists(err) {
+				return errors.NewConflict(kops.Resource("keysets"), name, err)
+			}
+			return fmt.Errorf("error creating keyset %q: %v", name, err)
+		}
+		return nil
+	}
+
+	if kopsKeyset.Spec.Type != kops.SecretTypeKeypair {
+		return fmt.Errorf("mismatch on Keyset type on %q", name)
+	}
+	for _, ki := range kopsKeyset.Spec.Keys {
+		if ki.Id == item.Id {
+			return fmt.Errorf("KeysetItem %q already exists in Keyset %q", item.Id, name)
+		}
+	}
+
+	// The update is rejected by the API server if the keyset has changed since we read it
+	kopsKeyset.Spec.Keys = append(kopsKeyset.Spec.Keys, *kopsItem)
+	if _, err := client.Update(ctx, kopsKeyset, metav1.UpdateOptions{}); err != nil {
+		if errors.IsConflict(err) {
+			return err
+		}
+		return fmt.Errorf("error updating keyset %q: %v", name, err)
+	}
+	return nil
+}
+
 // deleteKeysetItem deletes the specified key from the registry; deleting the whole Keyset if it was the last one.
 func deleteKeysetItem(client kopsinternalversion.KeysetInterface, name string, keysetType kops.KeysetType, id string) error {
 	ctx := context.TODO()
@@ -471,6 +601,21 @@ func (c *ClientsetCAStore) FindSSHPublicKeys(name string) ([]*kops.SSHCredential
 	return items, nil
 }
 
+// FindSSHPublicKeyByFingerprint implements SSHCredentialStore::FindSSHPublicKeyByFingerprint
+func (c *ClientsetCAStore) FindSSHPublicKeyByFingerprint(name string, fingerprint string) (*kops.SSHCredential, error) {
+	return findSSHPublicKeyByFingerprint(c, name, fingerprint)
+}
+
+// ExportClientBundle implements CAStore::ExportClientBundle
+func (c *ClientsetCAStore) ExportClientBundle(caName, clientName string) ([]byte, []byte, []byte, error) {
+	return exportClientBundle(c, caName, clientName)
+}
+
+// PromoteToPrimary implements CAStore::PromoteToPrimary
+func (c *ClientsetCAStore) PromoteToPrimary(name string, id string) error {
+	return promoteToPrimary(c, name, id)
+}
+
 // DeleteKeysetItem implements CAStore::DeleteKeysetItem
 func (c *ClientsetCAStore) DeleteKeysetItem(item *kops.Keyset, id string) error {
 	switch item.Spec.Type {
@@ -490,6 +635,22 @@ func (c *ClientsetCAStore) DeleteSSHCredential(item *kops.SSHCredential) error {
 	return c.deleteSSHCredential(ctx, item.Name)
 }
 
+// DeleteSSHCredentialByFingerprint implements SSHCredentialStore::DeleteSSHCredentialByFingerprint
+// The named SSHCredential holds a single key, so it is deleted if that key has the specified fingerprint.
+func (c *ClientsetCAStore) DeleteSSHCredentialByFingerprint(name string, fingerprint string) error {
+	ctx := context.TODO()
+
+	sshCredential, err := c.FindSSHPublicKeyByFingerprint(name, fingerprint)
+	if err != nil {
+		return err
+	}
+	if sshCredential == nil {
+		return nil
+	}
+
+	return c.deleteSSHCredential(ctx, name)
+}
+
 func (c *ClientsetCAStore) MirrorTo(basedir vfs.Path) error {
 	keysets, err := c.ListKeysets()
 	if err != nil {
@@ -497,7 +658,7 @@ func (c *ClientsetCAStore) MirrorTo(basedir vfs.Path) error {
 	}
 
 	for _, keyset := range keysets {
-		if err := mirrorKeyset(c.cluster, basedir, keyset); err != nil {
+		if err := mirrorKeyset(c.cluster, nil, basedir, keyset); err != nil {
 			return err
 		}
 	}
@@ -508,7 +669,7 @@ func (c *ClientsetCAStore) MirrorTo(basedir vfs.Path) error {
 	}
 
 	for _, sshCredential := range sshCredentials {
-		if err := mirrorSSHCredential(c.cluster, basedir, sshCredential); err != nil {
+		if err := mirrorSSHCredential(c.cluster, nil, basedir, sshCredential); err != nil {
 			return err
 		}
 	}
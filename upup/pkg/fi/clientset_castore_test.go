@@ -0,0 +1,390 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/clientset_generated/clientset/fake"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/sshcredentials"
+)
+
+var keysetsResource = schema.GroupVersionResource{Group: "kops.k8s.io", Resource: "keysets"}
+
+// newConflictCheckingClientset returns a fake clientset which, like the API server,
+// rejects keyset updates that are based on a stale resourceVersion.
+// If beforeUpdate is non-nil, it is called before each update is applied.
+func newConflictCheckingClientset(t *testing.T, beforeUpdate func(tracker k8stesting.ObjectTracker)) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	tracker := clientset.Tracker()
+
+	clientset.PrependReactor("update", "keysets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if beforeUpdate != nil {
+			beforeUpdate(tracker)
+		}
+
+		keyset := action.(k8stesting.UpdateAction).GetObject().(*kops.Keyset).DeepCopy()
+		existing, err := tracker.Get(keysetsResource, keyset.Namespace, keyset.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		if existing.(*kops.Keyset).ResourceVersion != keyset.ResourceVersion {
+			return true, nil, errors.NewConflict(kops.Resource("keysets"), keyset.Name, nil)
+		}
+
+		bumpResourceVersion(t, keyset)
+		return true, keyset, tracker.Update(keysetsResource, keyset, keyset.Namespace)
+	})
+
+	return clientset
+}
+
+func bumpResourceVersion(t *testing.T, keyset *kops.Keyset) {
+	version := 0
+	if keyset.ResourceVersion != "" {
+		v, err := strconv.Atoi(keyset.ResourceVersion)
+		if err != nil {
+			t.Fatalf("unexpected resourceVersion %q: %v", keyset.ResourceVersion, err)
+		}
+		version = v
+	}
+	keyset.ResourceVersion = strconv.Itoa(version + 1)
+}
+
+func newTestKeysetItem(id string) *KeysetItem {
+	return &KeysetItem{
+		Id:          id,
+		Certificate: &pki.Certificate{},
+		PrivateKey:  &pki.PrivateKey{},
+	}
+}
+
+func keysetItemIds(t *testing.T, clientset *fake.Clientset, name string) (ids []string, primaryId string) {
+	keyset, err := clientset.Kops().Keysets("default").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error reading keyset %q: %v", name, err)
+	}
+	for _, item := range keyset.Spec.Keys {
+		ids = append(ids, item.Id)
+	}
+	sort.Strings(ids)
+	return ids, keyset.Spec.PrimaryId
+}
+
+func TestAddKeysetItem(t *testing.T) {
+	clientset := newConflictCheckingClientset(t, nil)
+	store := NewClientsetCAStore(&kops.Cluster{}, clientset.Kops(), "default").(*ClientsetCAStore)
+
+	if err := store.AddKeysetItem("ca", newTestKeysetItem("1")); err != nil {
+		t.Fatalf("unexpected error adding first item: %v", err)
+	}
+	if err := store.AddKeysetItem("ca", newTestKeysetItem("2")); err != nil {
+		t.Fatalf("unexpected error adding second item: %v", err)
+	}
+	if err := store.AddKeysetItem("ca", newTestKeysetItem("2")); err == nil {
+		t.Errorf("expected error adding duplicate item")
+	}
+
+	ids, primaryId := keysetItemIds(t, clientset, "ca")
+	if expected := []string{"1", "2"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("unexpected items: expected %v, got %v", expected, ids)
+	}
+	if primaryId != "1" {
+		t.Errorf("expected primary to remain %q, got %q", "1", primaryId)
+	}
+}
+
+func TestListKeysetsByType(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	store := NewClientsetCAStore(&kops.Cluster{}, clientset.Kops(), "default").(*ClientsetCAStore)
+
+	for name, keysetType := range map[string]kops.KeysetType{
+		"ca":               kops.SecretTypeKeypair,
+		"kubelet":          kops.SecretTypeKeypair,
+		"admin":            kops.SecretTypeSecret,
+		"encryptionconfig": kops.SecretTypeSecret,
+	} {
+		keyset := &kops.Keyset{}
+		keyset.Name = name
+		keyset.Spec.Type = keysetType
+		if _, err := clientset.Kops().Keysets("default").Create(context.TODO(), keyset, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("error creating keyset %q: %v", name, err)
+		}
+	}
+
+	for keysetType, expected := range map[kops.KeysetType][]string{
+		kops.SecretTypeKeypair: {"ca", "kubelet"},
+		kops.SecretTypeSecret:  {"admin", "encryptionconfig"},
+		"unknown":              nil,
+	} {
+		keysets, err := store.ListKeysetsByType(keysetType)
+		if err != nil {
+			t.Fatalf("unexpected error listing %s keysets: %v", keysetType, err)
+		}
+
+		var names []string
+		for _, keyset := range keysets {
+			names = append(names, keyset.Name)
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("unexpected %s keysets: expected %v, got %v", keysetType, expected, names)
+		}
+	}
+}
+
+func TestListKeysetMetadata(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	store := NewClientsetCAStore(&kops.Cluster{}, clientset.Kops(), "default").(*ClientsetCAStore)
+
+	for _, name := range []string{"ca", "kubelet"} {
+		keyset := &kops.Keyset{}
+		keyset.Name = name
+		keyset.Spec.Type = kops.SecretTypeKeypair
+		keyset.Spec.PrimaryId = "2"
+		keyset.Spec.Keys = []kops.KeysetItem{
+			{Id: "1", PublicMaterial: []byte("public1"), PrivateMaterial: []byte("private1")},
+			{Id: "2", PublicMaterial: []byte("public2"), PrivateMaterial: []byte("private2")},
+		}
+		if _, err := clientset.Kops().Keysets("default").Create(context.TODO(), keyset, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("error creating keyset %q: %v", name, err)
+		}
+	}
+
+	metadata, err := store.ListKeysetMetadata()
+	if err != nil {
+		t.Fatalf("unexpected error listing keyset metadata: %v", err)
+	}
+	sort.Slice(metadata, func(i, j int) bool {
+		return metadata[i].Name < metadata[j].Name
+	})
+
+	expected := []*KeysetMetadata{
+		{Name: "ca", Type: kops.SecretTypeKeypair, PrimaryID: "2"},
+		{Name: "kubelet", Type: kops.SecretTypeKeypair, PrimaryID: "2"},
+	}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("unexpected keyset metadata: expected %+v, got %+v", expected, metadata)
+	}
+}
+
+func TestClientsetFindSSHPublicKeyByFingerprint(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	store := NewClientsetCAStore(&kops.Cluster{}, clientset.Kops(), "default").(*ClientsetCAStore)
+
+	pubkey := "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test"
+	if err := store.AddSSHPublicKey("admin", []byte(pubkey)); err != nil {
+		t.Fatalf("error adding SSH public key: %v", err)
+	}
+
+	fingerprint, err := sshcredentials.Fingerprint(pubkey)
+	if err != nil {
+		t.Fatalf("error fingerprinting SSH public key: %v", err)
+	}
+
+	sshCredential, err := store.FindSSHPublicKeyByFingerprint("admin", fingerprint)
+	if err != nil {
+		t.Fatalf("unexpected error finding SSH public key: %v", err)
+	}
+	if sshCredential == nil || sshCredential.Spec.PublicKey != pubkey {
+		t.Errorf("unexpected SSH public key for %q: %v", fingerprint, sshCredential)
+	}
+
+	sshCredential, err = store.FindSSHPublicKeyByFingerprint("admin", "00:11:22:33:44:55:66:77:88:99:aa:bb:cc:dd:ee:ff")
+	if err != nil {
+		t.Fatalf("unexpected error finding SSH public key: %v", err)
+	}
+	if sshCredential != nil {
+		t.Errorf("unexpected SSH public key found for unknown fingerprint: %v", sshCredential)
+	}
+}
+
+func TestClientsetSSHPublicKeyDedup(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	store := NewClientsetCAStore(&kops.Cluster{}, clientset.Kops(), "default").(*ClientsetCAStore)
+
+	pubkey := "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x"
+	for _, comment := range []string{"justin@test", "justin@other"} {
+		if err := store.AddSSHPublicKey("admin", []byte(pubkey+" "+comment)); err != nil {
+			t.Fatalf("error adding SSH public key: %v", err)
+		}
+	}
+
+	sshCredentials, err := store.FindSSHPublicKeys("admin")
+	if err != nil {
+		t.Fatalf("unexpected error finding SSH public keys: %v", err)
+	}
+	if len(sshCredentials) != 1 {
+		t.Errorf("expected a single SSH public key, got %d: %v", len(sshCredentials), sshCredentials)
+	}
+}
+
+func TestClientsetDeleteSSHCredentialByFingerprint(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	store := NewClientsetCAStore(&kops.Cluster{}, clientset.Kops(), "default").(*ClientsetCAStore)
+
+	pubkey := "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test"
+	if err := store.AddSSHPublicKey("admin", []byte(pubkey)); err != nil {
+		t.Fatalf("error adding SSH public key: %v", err)
+	}
+
+	fingerprint, err := sshcredentials.Fingerprint(pubkey)
+	if err != nil {
+		t.Fatalf("error fingerprinting SSH public key: %v", err)
+	}
+
+	// A different fingerprint must not delete the credential
+	if err := store.DeleteSSHCredentialByFingerprint("admin", "00:11:22:33:44:55:66:77:88:99:aa:bb:cc:dd:ee:ff"); err != nil {
+		t.Fatalf("unexpected error deleting unknown SSH public key: %v", err)
+	}
+	sshCredentials, err := store.FindSSHPublicKeys("admin")
+	if err != nil {
+		t.Fatalf("unexpected error finding SSH public keys: %v", err)
+	}
+	if len(sshCredentials) != 1 {
+		t.Fatalf("expected SSH public key to be kept, got %v", sshCredentials)
+	}
+
+	if err := store.DeleteSSHCredentialByFingerprint("admin", fingerprint); err != nil {
+		t.Fatalf("unexpected error deleting SSH public key: %v", err)
+	}
+	sshCredentials, err = store.FindSSHPublicKeys("admin")
+	if err != nil {
+		t.Fatalf("unexpected error finding SSH public keys: %v", err)
+	}
+	if len(sshCredentials) != 0 {
+		t.Errorf("expected SSH public key to be deleted, got %v", sshCredentials)
+	}
+
+	// Deleting again is not an error
+	if err := store.DeleteSSHCredentialByFingerprint("admin", fingerprint); err != nil {
+		t.Fatalf("unexpected error deleting missing SSH public key: %v", err)
+	}
+}
+
+func TestAddKeysetItemConcurrent(t *testing.T) {
+	concurrentAdds := 0
+
+	// Simulate another writer adding an item between our read and our update, the first time only
+	clientset := newConflictCheckingClientset(t, func(tracker k8stesting.ObjectTracker) {
+		if concurrentAdds > 0 {
+			return
+		}
+		concurrentAdds++
+
+		obj, err := tracker.Get(keysetsResource, "default", "ca")
+		if err != nil {
+			t.Fatalf("error reading keyset: %v", err)
+		}
+		keyset := obj.(*kops.Keyset).DeepCopy()
+		keyset.Spec.Keys = append(keyset.Spec.Keys, kops.KeysetItem{Id: "concurrent"})
+		bumpResourceVersion(t, keyset)
+		if err := tracker.Update(keysetsResource, keyset, "default"); err != nil {
+			t.Fatalf("error updating keyset: %v", err)
+		}
+	})
+	store := NewClientsetCAStore(&kops.Cluster{}, clientset.Kops(), "default").(*ClientsetCAStore)
+
+	if err := store.AddKeysetItem("ca", newTestKeysetItem("1")); err != nil {
+		t.Fatalf("unexpected error adding first item: %v", err)
+	}
+
+	err := store.AddKeysetItem("ca", newTestKeysetItem("2"))
+	if !errors.IsConflict(err) {
+		t.Fatalf("expected conflict error from concurrent add, got %v", err)
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return store.AddKeysetItem("ca", newTestKeysetItem("2"))
+	})
+	if err != nil {
+		t.Fatalf("unexpected error retrying add: %v", err)
+	}
+
+	ids, primaryId := keysetItemIds(t, clientset, "ca")
+	if expected := []string{"1", "2", "concurrent"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("unexpected items: expected %v, got %v", expected, ids)
+	}
+	if primaryId != "1" {
+		t.Errorf("expected primary to remain %q, got %q", "1", primaryId)
+	}
+}
+
+func TestClientsetStoreKeysets(t *testing.T) {
+	failing := map[string]bool{"kubelet": true}
+
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "keysets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		keyset := action.(k8stesting.CreateAction).GetObject().(*kops.Keyset)
+		if failing[keyset.Name] {
+			return true, nil, errors.NewServiceUnavailable("injected failure")
+		}
+		return false, nil, nil
+	})
+	store := NewClientsetCAStore(&kops.Cluster{}, clientset.Kops(), "default").(*ClientsetCAStore)
+
+	keysets := map[string]*Keyset{}
+	for _, name := range []string{"ca", "kubelet", "kube-proxy"} {
+		item := newTestKeysetItem("1")
+		keysets[name] = &Keyset{Items: map[string]*KeysetItem{"1": item}, Primary: item}
+	}
+
+	err := store.StoreKeysets(keysets)
+	storeErr, ok := err.(*StoreKeysetsError)
+	if !ok {
+		t.Fatalf("expected a StoreKeysetsError, got %v", err)
+	}
+	if len(storeErr.Errors) != 1 || storeErr.Errors["kubelet"] == nil {
+		t.Fatalf("expected only keyset %q to fail, got %v", "kubelet", storeErr)
+	}
+
+	// The other keysets are stored despite the failure
+	for _, name := range []string{"ca", "kube-proxy"} {
+		ids, primaryId := keysetItemIds(t, clientset, name)
+		if !reflect.DeepEqual(ids, []string{"1"}) || primaryId != "1" {
+			t.Errorf("unexpected keyset %q: items %v, primary %q", name, ids, primaryId)
+		}
+	}
+
+	// Retrying the failed keysets stores them, without affecting those already stored
+	failing = map[string]bool{}
+	retry := map[string]*Keyset{}
+	for name := range storeErr.Errors {
+		retry[name] = keysets[name]
+	}
+	if err := store.StoreKeysets(retry); err != nil {
+		t.Fatalf("unexpected error retrying failed keysets: %v", err)
+	}
+	for _, name := range []string{"ca", "kubelet", "kube-proxy"} {
+		ids, primaryId := keysetItemIds(t, clientset, name)
+		if !reflect.DeepEqual(ids, []string{"1"}) || primaryId != "1" {
+			t.Errorf("unexpected keyset %q: items %v, primary %q", name, ids, primaryId)
+		}
+	}
+}
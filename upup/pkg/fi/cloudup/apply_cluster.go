@@ -25,11 +25,14 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	kopsbase "k8s.io/kops"
 	"k8s.io/kops/pkg/acls"
@@ -54,8 +57,8 @@ import (
 	"k8s.io/kops/pkg/model/gcemodel"
 	"k8s.io/kops/pkg/model/iam"
 	"k8s.io/kops/pkg/model/openstackmodel"
+	"k8s.io/kops/pkg/pki"
 	"k8s.io/kops/pkg/templates"
-	"k8s.io/kops/pkg/wellknownports"
 	"k8s.io/kops/upup/models"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/aliup"
@@ -121,9 +124,24 @@ type ApplyClusterCmd struct {
 	// AllowKopsDowngrade permits applying with a kops version older than what was last used to apply to the cluster.
 	AllowKopsDowngrade bool
 
+	// SkipDNSPrecreate disables pre-creation of the placeholder DNS records, e.g. when DNS is managed externally.
+	// Pre-creation is already skipped for gossip clusters and for the terraform, cloudformation and dryrun targets.
+	// This does not affect validation of the DNS configuration.
+	SkipDNSPrecreate bool
+
+	// StrictValidation turns the anonymousAuth recommendation, deprecation warnings and the warnings
+	// from cluster validation into errors, rather than printing them and continuing.
+	StrictValidation bool
+
 	// RunTasksOptions defines parameters for task execution, e.g. retry interval
 	RunTasksOptions *fi.RunTasksOptions
 
+	// NodeUpConfigTransform, if set, is applied to the nodeup config built for each instance group.
+	NodeUpConfigTransform NodeUpConfigTransform
+
+	// MirrorResolver, if set, finds the download locations of the file assets in place of the default kOps mirrors.
+	MirrorResolver mirrors.Resolver
+
 	// The channel we are using
 	channel *kops.Channel
 
@@ -138,26 +156,35 @@ type ApplyClusterCmd struct {
 	// GetAssets is whether this is called just to obtain the list of assets.
 	GetAssets bool
 
+	// TaskGraphOut, if set, receives the task graph as JSON before the tasks are run.
+	TaskGraphOut io.Writer
+
 	// TaskMap is the map of tasks that we built (output)
 	TaskMap map[string]fi.Task
 
+	// PendingDeletions is the sorted names of the tasks added to delete cloud objects that are no longer in the model (output).
+	// It is only populated when existing cloud objects are checked, i.e. not for the terraform and cloudformation targets.
+	PendingDeletions []string
+
 	// ImageAssets are the image assets we use (output).
 	ImageAssets []*assets.ImageAsset
 	// FileAssets are the file assets we use (output).
 	FileAssets []*assets.FileAsset
+
+	// TaskTimings is the time spent running each task, including any retries (output).
+	TaskTimings map[string]time.Duration
+	// RunTasksDuration is the time spent running all the tasks (output).
+	RunTasksDuration time.Duration
 }
 
 func (c *ApplyClusterCmd) Run(ctx context.Context) error {
-	if c.InstanceGroups == nil {
-		list, err := c.Clientset.InstanceGroupsFor(c.Cluster).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return err
-		}
-		var instanceGroups []*kops.InstanceGroup
-		for i := range list.Items {
-			instanceGroups = append(instanceGroups, &list.Items[i])
-		}
-		c.InstanceGroups = instanceGroups
+	// Only the AWS model builders have IAM tasks, so the phase would do nothing on other clouds
+	if c.Phase == PhaseIAM && kops.CloudProviderID(c.Cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		return fmt.Errorf("phase %q is only supported on AWS", c.Phase)
+	}
+
+	if err := c.loadInstanceGroups(ctx); err != nil {
+		return err
 	}
 
 	for _, ig := range c.InstanceGroups {
@@ -173,15 +200,15 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		}
 	}
 
-	channel, err := ChannelForCluster(c.Cluster)
-	if err != nil {
-		klog.Warningf("%v", err)
+	if err := c.loadChannel(); err != nil {
+		return err
 	}
-	c.channel = channel
 
 	securityLifecycle := fi.LifecycleSync
 	networkLifecycle := fi.LifecycleSync
 	clusterLifecycle := fi.LifecycleSync
+	// iamLifecycle follows securityLifecycle unless the IAM phase is selected
+	var iamLifecycle fi.Lifecycle
 
 	switch c.Phase {
 	case Phase(""):
@@ -195,6 +222,12 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		networkLifecycle = fi.LifecycleExistsAndWarnIfChanges
 		clusterLifecycle = fi.LifecycleIgnore
 
+	case PhaseIAM:
+		networkLifecycle = fi.LifecycleIgnore
+		securityLifecycle = fi.LifecycleIgnore
+		clusterLifecycle = fi.LifecycleIgnore
+		iamLifecycle = fi.LifecycleSync
+
 	case PhaseCluster:
 		if c.TargetName == TargetDryRun {
 			securityLifecycle = fi.LifecycleExistsAndWarnIfChanges
@@ -207,14 +240,18 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 	default:
 		return fmt.Errorf("unknown phase %q", c.Phase)
 	}
+	if iamLifecycle == "" {
+		iamLifecycle = securityLifecycle
+	}
 	if c.GetAssets {
 		networkLifecycle = fi.LifecycleIgnore
 		securityLifecycle = fi.LifecycleIgnore
 		clusterLifecycle = fi.LifecycleIgnore
+		iamLifecycle = fi.LifecycleIgnore
 	}
 
 	assetBuilder := assets.NewAssetBuilder(c.Cluster, c.GetAssets)
-	err = c.upgradeSpecs(assetBuilder)
+	err := c.upgradeSpecs(assetBuilder)
 	if err != nil {
 		return err
 	}
@@ -266,6 +303,11 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if c.StrictValidation {
+		if warnings := validation.DeepValidateWarnings(c.Cluster, c.InstanceGroups, cloud); len(warnings) != 0 {
+			return fmt.Errorf("cluster validation reported warnings (strict validation is enabled): %s", strings.Join(warnings, "; "))
+		}
+	}
 
 	if cluster.Spec.KubernetesVersion == "" {
 		return fmt.Errorf("KubernetesVersion not set")
@@ -327,6 +369,10 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 			fmt.Println("")
 			fmt.Printf("%s\n", starline)
 			fmt.Println("")
+
+			if c.StrictValidation {
+				return fmt.Errorf("kubelet anonymousAuth is turned on; set 'spec.kubelet.anonymousAuth' to 'false' (strict validation is enabled)")
+			}
 		}
 	}
 
@@ -382,6 +428,10 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		InstanceGroups:  c.InstanceGroups,
 	}
 
+	if err := validateCloudType(cluster.Spec.CloudProvider, cloud); err != nil {
+		return err
+	}
+
 	switch kops.CloudProviderID(cluster.Spec.CloudProvider) {
 	case kops.CloudProviderGCE:
 		{
@@ -412,7 +462,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 			modelContext.AWSAccountID = accountID
 			modelContext.AWSPartition = partition
 
-			if len(sshPublicKeys) == 0 && c.Cluster.Spec.SSHKeyName == nil {
+			if len(sshPublicKeys) == 0 && c.Cluster.Spec.SSHKeyName == nil && !c.Cluster.Spec.DisableSSHAccess {
 				return fmt.Errorf("SSH public key must be specified when running with AWS (create with `kops create secret --name %s sshpublickey admin -i ~/.ssh/id_rsa.pub`)", cluster.ObjectMeta.Name)
 			}
 
@@ -427,6 +477,10 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 			fmt.Println("aliyun support has been deprecated due to lack of maintainers. It may be removed in a future version of kOps.")
 			fmt.Println("")
 
+			if c.StrictValidation {
+				return fmt.Errorf("aliyun support is deprecated (strict validation is enabled)")
+			}
+
 			if !AlphaAllowALI.Enabled() {
 				return fmt.Errorf("aliyun support is currently alpha, and is feature-gated.  export KOPS_FEATURE_FLAGS=AlphaAllowALI")
 			}
@@ -484,7 +538,7 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		cloud:            cloud,
 	}
 
-	configBuilder, err := newNodeUpConfigBuilder(cluster, assetBuilder, c.Assets)
+	configBuilder, err := newNodeUpConfigBuilder(cluster, assetBuilder, c.Assets, c.NodeUpConfigTransform, c.MirrorResolver)
 	if err != nil {
 		return err
 	}
@@ -552,8 +606,8 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 				&awsmodel.FirewallModelBuilder{AWSModelContext: awsModelContext, Lifecycle: securityLifecycle},
 				&awsmodel.SSHKeyModelBuilder{AWSModelContext: awsModelContext, Lifecycle: securityLifecycle},
 				&awsmodel.NetworkModelBuilder{AWSModelContext: awsModelContext, Lifecycle: networkLifecycle},
-				&awsmodel.IAMModelBuilder{AWSModelContext: awsModelContext, Lifecycle: securityLifecycle, Cluster: cluster},
-				&awsmodel.OIDCProviderBuilder{AWSModelContext: awsModelContext, Lifecycle: securityLifecycle, KeyStore: keyStore},
+				&awsmodel.IAMModelBuilder{AWSModelContext: awsModelContext, Lifecycle: iamLifecycle, Cluster: cluster},
+				&awsmodel.OIDCProviderBuilder{AWSModelContext: awsModelContext, Lifecycle: iamLifecycle, KeyStore: keyStore},
 			)
 
 			awsModelBuilder := &awsmodel.AutoscalingGroupModelBuilder{
@@ -725,6 +779,10 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		fmt.Printf("%s\n", starline)
 		fmt.Printf("\n")
 
+		if c.StrictValidation {
+			return fmt.Errorf("kops support for CloudFormation is deprecated (strict validation is enabled)")
+		}
+
 	case TargetDryRun:
 		var out io.Writer = os.Stdout
 		if c.GetAssets {
@@ -742,10 +800,27 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 	c.Target = target
 
 	if checkExisting {
+		// FindDeletions adds to the existing map of tasks, so record which tasks were already present
+		existingTasks := make(map[string]bool, len(c.TaskMap))
+		for name := range c.TaskMap {
+			existingTasks[name] = true
+		}
+
 		c.TaskMap, err = l.FindDeletions(cloud, c.LifecycleOverrides)
 		if err != nil {
 			return fmt.Errorf("error finding deletions: %w", err)
 		}
+
+		c.PendingDeletions = pendingDeletions(existingTasks, c.TaskMap)
+		if dryRunTarget, ok := target.(*fi.DryRunTarget); ok {
+			dryRunTarget.SetPendingDeletions(c.PendingDeletions)
+		}
+	}
+
+	if c.TaskGraphOut != nil {
+		if err := fi.WriteTaskGraph(c.TaskGraphOut, c.TaskMap); err != nil {
+			return err
+		}
 	}
 
 	context, err := fi.NewContext(target, cluster, cloud, keyStore, secretStore, configBase, checkExisting, c.TaskMap)
@@ -792,7 +867,19 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		options.InitDefaults()
 	}
 
+	c.TaskTimings = make(map[string]time.Duration)
+	observeTaskDuration := options.ObserveTaskDuration
+	options.ObserveTaskDuration = func(key string, duration time.Duration) {
+		c.TaskTimings[key] += duration
+		if observeTaskDuration != nil {
+			observeTaskDuration(key, duration)
+		}
+	}
+
+	runTasksStart := time.Now()
 	err = context.RunTasks(options)
+	c.RunTasksDuration = time.Since(runTasksStart)
+	klog.V(2).Infof("ran tasks for phase %q in %v", c.Phase, c.RunTasksDuration)
 	if err != nil {
 		return fmt.Errorf("error running tasks: %v", err)
 	}
@@ -801,6 +888,10 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 		shouldPrecreateDNS = false
 	}
 
+	if c.SkipDNSPrecreate {
+		shouldPrecreateDNS = false
+	}
+
 	if shouldPrecreateDNS && clusterLifecycle != fi.LifecycleIgnore {
 		if err := precreateDNS(ctx, cluster, cloud); err != nil {
 			klog.Warningf("unable to pre-create DNS records - cluster startup may be slower: %v", err)
@@ -818,6 +909,82 @@ func (c *ApplyClusterCmd) Run(ctx context.Context) error {
 	return nil
 }
 
+// ComputeAssets returns all the file assets of the cluster, but only a partial list of its image assets,
+// without building or running the task graph.
+// It is much cheaper than a Run with GetAssets, but the images only include those which are remapped while
+// populating the cluster spec and building the nodeup configuration; images referenced by the addon manifests
+// or added by the model builders (e.g. etcd-manager) are not included. Callers that need the complete list of
+// images, for example to copy them to a container registry, must use Run with GetAssets instead.
+func (c *ApplyClusterCmd) ComputeAssets(ctx context.Context) ([]*assets.ImageAsset, []*assets.FileAsset, error) {
+	if err := c.loadInstanceGroups(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	if err := c.loadChannel(); err != nil {
+		return nil, nil, err
+	}
+
+	assetBuilder := assets.NewAssetBuilder(c.Cluster, true)
+	if err := c.upgradeSpecs(assetBuilder); err != nil {
+		return nil, nil, err
+	}
+
+	if err := c.addFileAssets(assetBuilder); err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := newNodeUpConfigBuilder(c.Cluster, assetBuilder, c.Assets, c.NodeUpConfigTransform, c.MirrorResolver); err != nil {
+		return nil, nil, err
+	}
+
+	return assetBuilder.ImageAssets, assetBuilder.FileAssets, nil
+}
+
+// loadInstanceGroups lists the instance groups of the cluster, unless they were already provided
+func (c *ApplyClusterCmd) loadInstanceGroups(ctx context.Context) error {
+	if c.InstanceGroups != nil {
+		return nil
+	}
+
+	list, err := c.Clientset.InstanceGroupsFor(c.Cluster).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var instanceGroups []*kops.InstanceGroup
+	for i := range list.Items {
+		instanceGroups = append(instanceGroups, &list.Items[i])
+	}
+	c.InstanceGroups = instanceGroups
+	return nil
+}
+
+// loadChannel loads the channel of the cluster
+func (c *ApplyClusterCmd) loadChannel() error {
+	channel, err := ChannelForCluster(c.Cluster)
+	if err != nil {
+		// An explicitly configured channel provides the version checks the user asked for, so we cannot continue without it
+		if c.Cluster.Spec.Channel != "" && c.Cluster.Spec.Channel != kops.DefaultChannel {
+			return fmt.Errorf("unable to load channel %q: %w", c.Cluster.Spec.Channel, err)
+		}
+		klog.Warningf("%v", err)
+	}
+	c.channel = channel
+	return nil
+}
+
+// pendingDeletions returns the sorted names of the tasks which are not in existingTasks,
+// i.e. those added by FindDeletions to delete cloud objects that are no longer in the model.
+func pendingDeletions(existingTasks map[string]bool, tasks map[string]fi.Task) []string {
+	var deletions []string
+	for name := range tasks {
+		if !existingTasks[name] {
+			deletions = append(deletions, name)
+		}
+	}
+	sort.Strings(deletions)
+	return deletions
+}
+
 // upgradeSpecs ensures that fields are fully populated / defaulted
 func (c *ApplyClusterCmd) upgradeSpecs(assetBuilder *assets.AssetBuilder) error {
 	fullCluster, err := PopulateClusterSpec(c.Clientset, c.Cluster, c.Cloud, assetBuilder)
@@ -905,6 +1072,37 @@ func (c *ApplyClusterCmd) validateKopsVersion() error {
 	return nil
 }
 
+// validateCloudType checks that the cloud implements the interface for the cluster's CloudProvider,
+// so that a mismatched cloud is reported as an error rather than a failed type assertion.
+func validateCloudType(cloudProvider string, cloud fi.Cloud) error {
+	if cloud == nil {
+		return fmt.Errorf("cloud not set for CloudProvider %q", cloudProvider)
+	}
+
+	ok := false
+	switch kops.CloudProviderID(cloudProvider) {
+	case kops.CloudProviderGCE:
+		_, ok = cloud.(gce.GCECloud)
+	case kops.CloudProviderDO:
+		_, ok = cloud.(do.DOCloud)
+	case kops.CloudProviderAWS:
+		_, ok = cloud.(awsup.AWSCloud)
+	case kops.CloudProviderALI:
+		_, ok = cloud.(aliup.ALICloud)
+	case kops.CloudProviderAzure:
+		_, ok = cloud.(azure.AzureCloud)
+	case kops.CloudProviderOpenstack:
+		_, ok = cloud.(openstack.OpenstackCloud)
+	default:
+		return fmt.Errorf("unknown CloudProvider %q", cloudProvider)
+	}
+
+	if !ok {
+		return fmt.Errorf("cloud for CloudProvider %q has unexpected type %T (provider %q)", cloudProvider, cloud, cloud.ProviderID())
+	}
+	return nil
+}
+
 // validateKubernetesVersion ensures that kubernetes meet the version requirements / recommendations in the channel
 func (c *ApplyClusterCmd) validateKubernetesVersion() error {
 	parsed, err := util.ParseKubernetesVersion(c.Cluster.Spec.KubernetesVersion)
@@ -958,6 +1156,9 @@ func (c *ApplyClusterCmd) validateKubernetesVersion() error {
 		fmt.Printf("%s\n", starline)
 		fmt.Printf("\n")
 
+		if c.StrictValidation {
+			return fmt.Errorf("kops support for kubernetes version %s is deprecated (strict validation is enabled)", parsed)
+		}
 	}
 
 	// TODO: make util.ParseKubernetesVersion not return a pointer
@@ -1057,21 +1258,27 @@ func (c *ApplyClusterCmd) addFileAssets(assetBuilder *assets.AssetBuilder) error
 			if err != nil {
 				return err
 			}
-			c.Assets[arch] = append(c.Assets[arch], mirrors.BuildMirroredAsset(u, hash))
+			if err := c.addAsset(arch, mirrors.BuildMirroredAsset(u, hash)); err != nil {
+				return err
+			}
 		}
 
 		cniAsset, cniAssetHash, err := findCNIAssets(c.Cluster, assetBuilder, arch)
 		if err != nil {
 			return err
 		}
-		c.Assets[arch] = append(c.Assets[arch], mirrors.BuildMirroredAsset(cniAsset, cniAssetHash))
+		if err := c.addAsset(arch, mirrors.BuildMirroredAsset(cniAsset, cniAssetHash)); err != nil {
+			return err
+		}
 
 		if c.Cluster.Spec.Networking.LyftVPC != nil {
 			lyftAsset, lyftAssetHash, err := findLyftVPCAssets(c.Cluster, assetBuilder, arch)
 			if err != nil {
 				return err
 			}
-			c.Assets[arch] = append(c.Assets[arch], mirrors.BuildMirroredAsset(lyftAsset, lyftAssetHash))
+			if err := c.addAsset(arch, mirrors.BuildMirroredAsset(lyftAsset, lyftAssetHash)); err != nil {
+				return err
+			}
 		}
 
 		var containerRuntimeAssetUrl *url.URL
@@ -1087,18 +1294,33 @@ func (c *ApplyClusterCmd) addFileAssets(assetBuilder *assets.AssetBuilder) error
 		if err != nil {
 			return err
 		}
-		c.Assets[arch] = append(c.Assets[arch], mirrors.BuildMirroredAsset(containerRuntimeAssetUrl, containerRuntimeAssetHash))
+		if err := c.addAsset(arch, mirrors.BuildMirroredAsset(containerRuntimeAssetUrl, containerRuntimeAssetHash)); err != nil {
+			return err
+		}
 
-		asset, err := NodeUpAsset(assetBuilder, arch)
+		asset, err := findNodeUpAsset(c.Cluster, assetBuilder, arch)
+		if err != nil {
+			return err
+		}
+		c.NodeUpAssets[arch], err = mirrors.ResolveMirroredAsset(c.MirrorResolver, asset)
 		if err != nil {
 			return err
 		}
-		c.NodeUpAssets[arch] = asset
 	}
 
 	return nil
 }
 
+// addAsset resolves the mirrors of the asset with the MirrorResolver and adds it to the assets for the architecture.
+func (c *ApplyClusterCmd) addAsset(arch architectures.Architecture, asset *mirrors.MirroredAsset) error {
+	resolved, err := mirrors.ResolveMirroredAsset(c.MirrorResolver, asset)
+	if err != nil {
+		return err
+	}
+	c.Assets[arch] = append(c.Assets[arch], resolved)
+	return nil
+}
+
 // buildPermalink returns a link to our "permalink docs", to further explain an error message
 func buildPermalink(key, anchor string) string {
 	url := "https://github.com/kubernetes/kops/blob/master/permalinks/" + key + ".md"
@@ -1128,6 +1350,10 @@ func needsMounterAsset(c *kops.Cluster, instanceGroups []*kops.InstanceGroup) bo
 	}
 }
 
+// NodeUpConfigTransform can modify the nodeup config and auxiliary config built for an instance group,
+// for example to add static manifests or assets. The hash of the auxiliary config is computed after the transform.
+type NodeUpConfigTransform func(ig *kops.InstanceGroup, config *nodeup.Config, auxConfig *nodeup.AuxConfig) error
+
 type nodeUpConfigBuilder struct {
 	// Assets is a list of sources for files (primarily when not using everything containerized)
 	// Formats:
@@ -1143,9 +1369,10 @@ type nodeUpConfigBuilder struct {
 	images         map[kops.InstanceGroupRole]map[architectures.Architecture][]*nodeup.Image
 	protokubeAsset map[architectures.Architecture][]*mirrors.MirroredAsset
 	channelsAsset  map[architectures.Architecture][]*mirrors.MirroredAsset
+	transform      NodeUpConfigTransform
 }
 
-func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBuilder, assets map[architectures.Architecture][]*mirrors.MirroredAsset) (model.NodeUpConfigBuilder, error) {
+func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBuilder, assets map[architectures.Architecture][]*mirrors.MirroredAsset, transform NodeUpConfigTransform, resolver mirrors.Resolver) (model.NodeUpConfigBuilder, error) {
 	configBase, err := vfs.Context.BuildVfsPath(cluster.Spec.ConfigBase)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing config base %q: %v", cluster.Spec.ConfigBase, err)
@@ -1165,7 +1392,11 @@ func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBui
 	channelsAsset := map[architectures.Architecture][]*mirrors.MirroredAsset{}
 
 	for _, arch := range architectures.GetSupported() {
-		asset, err := ProtokubeAsset(assetBuilder, arch)
+		asset, err := findProtokubeAsset(cluster, assetBuilder, arch)
+		if err != nil {
+			return nil, err
+		}
+		asset, err = mirrors.ResolveMirroredAsset(resolver, asset)
 		if err != nil {
 			return nil, err
 		}
@@ -1173,7 +1404,11 @@ func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBui
 	}
 
 	for _, arch := range architectures.GetSupported() {
-		asset, err := ChannelsAsset(assetBuilder, arch)
+		asset, err := findChannelsAsset(cluster, assetBuilder, arch)
+		if err != nil {
+			return nil, err
+		}
+		asset, err = mirrors.ResolveMirroredAsset(resolver, asset)
 		if err != nil {
 			return nil, err
 		}
@@ -1267,6 +1502,14 @@ func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBui
 			}
 		}
 
+		preloadImages, err := buildPreloadImages(cluster, assetBuilder, role)
+		if err != nil {
+			return nil, err
+		}
+		for _, arch := range architectures.GetSupported() {
+			images[role][arch] = append(images[role][arch], preloadImages...)
+		}
+
 		if isMaster {
 			for _, etcdCluster := range cluster.Spec.EtcdClusters {
 				if etcdCluster.Provider == kops.EtcdProviderTypeManager {
@@ -1287,11 +1530,32 @@ func newNodeUpConfigBuilder(cluster *kops.Cluster, assetBuilder *assets.AssetBui
 		images:         images,
 		protokubeAsset: protokubeAsset,
 		channelsAsset:  channelsAsset,
+		transform:      transform,
 	}
 
 	return &configBuilder, nil
 }
 
+// buildPreloadImages returns the images from the cluster's preloadImages to pull onto instances with the role.
+func buildPreloadImages(cluster *kops.Cluster, assetBuilder *assets.AssetBuilder, role kops.InstanceGroupRole) ([]*nodeup.Image, error) {
+	var images []*nodeup.Image
+	for _, name := range cluster.Spec.PreloadImages[strings.ToLower(string(role))] {
+		remapped, err := assetBuilder.RemapImage(name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to remap image %q: %v", name, err)
+		}
+
+		// containerd requires fully-qualified image references
+		normalized, err := assets.NormalizeImage(remapped)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse image %q: %v", remapped, err)
+		}
+
+		images = append(images, &nodeup.Image{Name: normalized})
+	}
+	return images, nil
+}
+
 // BuildConfig returns the NodeUp config and auxiliary config.
 func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, apiserverAdditionalIPs []string, caResource fi.Resource) (*nodeup.Config, *nodeup.AuxConfig, error) {
 	cluster := n.cluster
@@ -1337,7 +1601,7 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, apiserverAddit
 	if useConfigServer {
 		baseURL := url.URL{
 			Scheme: "https",
-			Host:   net.JoinHostPort("kops-controller.internal."+cluster.ObjectMeta.Name, strconv.Itoa(wellknownports.KopsControllerPort)),
+			Host:   net.JoinHostPort("kops-controller.internal."+cluster.ObjectMeta.Name, strconv.Itoa(cluster.Spec.GetKopsControllerPort())),
 			Path:   "/",
 		}
 
@@ -1346,6 +1610,9 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, apiserverAddit
 			// CA task may not have run yet; we'll retry
 			return nil, nil, fmt.Errorf("failed to read CA certificate: %w", err)
 		}
+		if _, err := pki.ParsePEMCertificateBundle([]byte(ca)); err != nil {
+			return nil, nil, fmt.Errorf("invalid CA certificate bundle for configuration server: %w", err)
+		}
 
 		configServer := &nodeup.ConfigServerOptions{
 			Server:        baseURL.String(),
@@ -1384,5 +1651,17 @@ func (n *nodeUpConfigBuilder) BuildConfig(ig *kops.InstanceGroup, apiserverAddit
 	config.Channels = n.channels
 	config.EtcdManifests = n.etcdManifests[role]
 
+	if n.transform != nil {
+		if err := n.transform(ig, config, auxConfig); err != nil {
+			return nil, nil, fmt.Errorf("error transforming nodeup config for instance group %q: %v", ig.ObjectMeta.Name, err)
+		}
+	}
+
+	if config.ConfigServer != nil {
+		if errs := validation.ValidateConfigServerOptions(config.ConfigServer, field.NewPath("configServer")); len(errs) != 0 {
+			return nil, nil, fmt.Errorf("invalid nodeup config for instance group %q: %v", ig.ObjectMeta.Name, errs.ToAggregate())
+		}
+	}
+
 	return config, auxConfig, nil
 }
@@ -0,0 +1,360 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/kopscodecs"
+	"k8s.io/kops/pkg/testutils"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestValidateKubernetesVersionStrictValidation(t *testing.T) {
+	grid := []struct {
+		KubernetesVersion string
+		StrictValidation  bool
+		ExpectError       bool
+	}{
+		{
+			KubernetesVersion: "1.18.0",
+		},
+		{
+			KubernetesVersion: "1.18.0",
+			StrictValidation:  true,
+			ExpectError:       true,
+		},
+		{
+			KubernetesVersion: OldestRecommendedKubernetesVersion,
+			StrictValidation:  true,
+		},
+	}
+
+	for _, g := range grid {
+		c := &ApplyClusterCmd{
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					KubernetesVersion: g.KubernetesVersion,
+				},
+			},
+			StrictValidation: g.StrictValidation,
+		}
+
+		err := c.validateKubernetesVersion()
+		if g.ExpectError && err == nil {
+			t.Errorf("kubernetes version %s, strict validation %v: expected error", g.KubernetesVersion, g.StrictValidation)
+		}
+		if !g.ExpectError && err != nil {
+			t.Errorf("kubernetes version %s, strict validation %v: unexpected error: %v", g.KubernetesVersion, g.StrictValidation, err)
+		}
+	}
+}
+
+func TestValidateCloudType(t *testing.T) {
+	awsCloud := awsup.BuildMockAWSCloud("us-test-1", "a")
+
+	grid := []struct {
+		CloudProvider kops.CloudProviderID
+		Cloud         fi.Cloud
+		ExpectError   bool
+	}{
+		{
+			CloudProvider: kops.CloudProviderAWS,
+			Cloud:         awsCloud,
+		},
+		{
+			CloudProvider: kops.CloudProviderGCE,
+			Cloud:         awsCloud,
+			ExpectError:   true,
+		},
+		{
+			CloudProvider: kops.CloudProviderOpenstack,
+			Cloud:         awsCloud,
+			ExpectError:   true,
+		},
+		{
+			CloudProvider: kops.CloudProviderAWS,
+			ExpectError:   true,
+		},
+		{
+			CloudProvider: "unknown",
+			Cloud:         awsCloud,
+			ExpectError:   true,
+		},
+	}
+
+	for _, g := range grid {
+		err := validateCloudType(string(g.CloudProvider), g.Cloud)
+		if g.ExpectError && err == nil {
+			t.Errorf("cloud provider %s, cloud %T: expected error", g.CloudProvider, g.Cloud)
+		}
+		if !g.ExpectError && err != nil {
+			t.Errorf("cloud provider %s, cloud %T: unexpected error: %v", g.CloudProvider, g.Cloud, err)
+		}
+	}
+}
+
+func TestPhaseIAMRequiresAWS(t *testing.T) {
+	for _, cloudProvider := range []kops.CloudProviderID{kops.CloudProviderGCE, kops.CloudProviderOpenstack, kops.CloudProviderDO} {
+		applyCmd := &ApplyClusterCmd{
+			Cluster: &kops.Cluster{
+				Spec: kops.ClusterSpec{
+					CloudProvider: string(cloudProvider),
+				},
+			},
+			Phase: PhaseIAM,
+		}
+		err := applyCmd.Run(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "only supported on AWS") {
+			t.Errorf("cloud provider %s: expected an error for phase %q, got %v", cloudProvider, PhaseIAM, err)
+		}
+	}
+}
+
+func TestNodeUpConfigTransform(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.ObjectMeta.Name = "minimal.example.com"
+	cluster.Spec.KubernetesVersion = "1.21.0"
+	cluster.Spec.MasterInternalName = "api.internal.minimal.example.com"
+
+	ig := &kops.InstanceGroup{}
+	ig.ObjectMeta.Name = "nodes"
+	ig.Spec.Role = kops.InstanceGroupRoleNode
+
+	builder := &nodeUpConfigBuilder{
+		assetBuilder: assets.NewAssetBuilder(cluster, false),
+		configBase:   vfs.NewMemFSPath(vfs.NewMemFSContext(), "tests/minimal.example.com"),
+		cluster:      cluster,
+		transform: func(ig *kops.InstanceGroup, config *nodeup.Config, auxConfig *nodeup.AuxConfig) error {
+			config.StaticManifests = append(config.StaticManifests, &nodeup.StaticManifest{Key: "custom", Path: "manifests/custom.yaml"})
+			auxConfig.FileAssets = append(auxConfig.FileAssets, kops.FileAssetSpec{Name: ig.ObjectMeta.Name, Path: "/etc/custom", Content: "custom"})
+			return nil
+		},
+	}
+
+	config, auxConfig, err := builder.BuildConfig(ig, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error building config: %v", err)
+	}
+	if len(config.StaticManifests) != 1 || config.StaticManifests[0].Key != "custom" {
+		t.Errorf("expected transform to add static manifest, got %v", config.StaticManifests)
+	}
+	if len(auxConfig.FileAssets) != 1 || auxConfig.FileAssets[0].Name != "nodes" {
+		t.Errorf("expected transform to add file asset, got %v", auxConfig.FileAssets)
+	}
+
+	builder.transform = func(ig *kops.InstanceGroup, config *nodeup.Config, auxConfig *nodeup.AuxConfig) error {
+		return fmt.Errorf("transform failed")
+	}
+	if _, _, err := builder.BuildConfig(ig, nil, nil); err == nil {
+		t.Errorf("expected error from failing transform")
+	}
+}
+
+func TestBuildPreloadImages(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.Spec.KubernetesVersion = "1.21.0"
+	cluster.Spec.Assets = &kops.Assets{ContainerRegistry: fi.String("registry.example.com")}
+	cluster.Spec.PreloadImages = map[string][]string{
+		"node": {
+			"quay.io/cilium/cilium:v1.10.0",
+			"busybox@sha256:01ba4719c80b6fe911b091a7c05124b64eeece964e09c058ef8f9805daca546b",
+		},
+	}
+	assetBuilder := assets.NewAssetBuilder(cluster, false)
+
+	for role, expected := range map[kops.InstanceGroupRole][]string{
+		kops.InstanceGroupRoleNode: {
+			"registry.example.com/quay.io-cilium-cilium:v1.10.0",
+			"registry.example.com/busybox@sha256:01ba4719c80b6fe911b091a7c05124b64eeece964e09c058ef8f9805daca546b",
+		},
+		kops.InstanceGroupRoleMaster: nil,
+	} {
+		images, err := buildPreloadImages(cluster, assetBuilder, role)
+		if err != nil {
+			t.Fatalf("unexpected error building %s preload images: %v", role, err)
+		}
+
+		var actual []string
+		for _, image := range images {
+			if len(image.Sources) != 0 {
+				t.Errorf("unexpected sources for %s preload image %q: %v", role, image.Name, image.Sources)
+			}
+			actual = append(actual, image.Name)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected %s preload images: expected %v, got %v", role, expected, actual)
+		}
+	}
+}
+
+func TestPendingDeletions(t *testing.T) {
+	existingTasks := map[string]bool{
+		"IAMRole/masters.example.com": true,
+		"IAMRole/nodes.example.com":   true,
+	}
+	tasks := map[string]fi.Task{
+		"IAMRole/masters.example.com":  nil,
+		"IAMRole/nodes.example.com":    nil,
+		"IAMRole/old.example.com":      nil,
+		"IAMRole/bastions.example.com": nil,
+	}
+
+	expected := []string{"IAMRole/bastions.example.com", "IAMRole/old.example.com"}
+	if actual := pendingDeletions(existingTasks, tasks); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected pending deletions: expected %v, got %v", expected, actual)
+	}
+
+	if actual := pendingDeletions(existingTasks, map[string]fi.Task{"IAMRole/masters.example.com": nil}); actual != nil {
+		t.Errorf("expected no pending deletions, got %v", actual)
+	}
+}
+
+func TestComputeAssets(t *testing.T) {
+	h := testutils.NewIntegrationTestHarness(t)
+	defer h.Close()
+	defer func() {
+		nodeUpAsset = nil
+		protokubeAsset = nil
+		channelsAsset = nil
+	}()
+
+	h.SetupMockAWS()
+
+	ctx := context.Background()
+
+	srcDir := "../../../../tests/integration/update_cluster/minimal_gossip"
+	inputYAML, err := ioutil.ReadFile(path.Join(srcDir, "in-v1alpha2.yaml"))
+	if err != nil {
+		t.Fatalf("error reading input: %v", err)
+	}
+	publicKey, err := ioutil.ReadFile(path.Join(srcDir, "id_rsa.pub"))
+	if err != nil {
+		t.Fatalf("error reading public key: %v", err)
+	}
+
+	basePath, err := vfs.Context.BuildVfsPath("memfs://clusters.example.com")
+	if err != nil {
+		t.Fatalf("error building vfspath: %v", err)
+	}
+	clientset := vfsclientset.NewVFSClientset(basePath)
+
+	var cluster *kops.Cluster
+	var instanceGroups []*kops.InstanceGroup
+	for _, section := range bytes.Split(inputYAML, []byte("\n---\n")) {
+		obj, _, err := kopscodecs.Decode(section, nil)
+		if err != nil {
+			t.Fatalf("error parsing input: %v", err)
+		}
+		switch v := obj.(type) {
+		case *kops.Cluster:
+			cluster = v
+		case *kops.InstanceGroup:
+			instanceGroups = append(instanceGroups, v)
+		default:
+			t.Fatalf("unexpected object type %T", obj)
+		}
+	}
+
+	cluster, err = clientset.CreateCluster(ctx, cluster)
+	if err != nil {
+		t.Fatalf("error creating cluster: %v", err)
+	}
+	for _, ig := range instanceGroups {
+		if _, err := clientset.InstanceGroupsFor(cluster).Create(ctx, ig, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("error creating instance group %q: %v", ig.Name, err)
+		}
+	}
+	sshCredentialStore, err := clientset.SSHCredentialStore(cluster)
+	if err != nil {
+		t.Fatalf("error building ssh credential store: %v", err)
+	}
+	if err := sshCredentialStore.AddSSHPublicKey("admin", publicKey); err != nil {
+		t.Fatalf("error adding ssh public key: %v", err)
+	}
+
+	newApplyCmd := func() *ApplyClusterCmd {
+		// The kops binary locations are cached, and would otherwise not be added to the second AssetBuilder
+		nodeUpAsset = nil
+		protokubeAsset = nil
+		channelsAsset = nil
+
+		cloud, err := BuildCloud(cluster)
+		if err != nil {
+			t.Fatalf("error from BuildCloud: %v", err)
+		}
+		return &ApplyClusterCmd{
+			Cloud:      cloud,
+			Clientset:  clientset,
+			Cluster:    cluster.DeepCopy(),
+			DryRun:     true,
+			TargetName: TargetDryRun,
+			GetAssets:  true,
+		}
+	}
+
+	applyCmd := newApplyCmd()
+	if err := applyCmd.Run(ctx); err != nil {
+		t.Fatalf("error from Run: %v", err)
+	}
+
+	imageAssets, fileAssets, err := newApplyCmd().ComputeAssets(ctx)
+	if err != nil {
+		t.Fatalf("error from ComputeAssets: %v", err)
+	}
+
+	fileURLs := func(fileAssets []*assets.FileAsset) []string {
+		var urls []string
+		for _, fileAsset := range fileAssets {
+			urls = append(urls, fmt.Sprintf("%s %s", fileAsset.DownloadURL, fileAsset.SHAValue))
+		}
+		sort.Strings(urls)
+		return urls
+	}
+	if expected, actual := fileURLs(applyCmd.FileAssets), fileURLs(fileAssets); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("unexpected file assets\nexpected: %v\nactual:   %v", expected, actual)
+	}
+
+	// ComputeAssets does not build the model, so it only returns a subset of the images
+	images := make(map[string]bool)
+	for _, imageAsset := range applyCmd.ImageAssets {
+		images[imageAsset.DownloadLocation] = true
+	}
+	if len(imageAssets) == 0 {
+		t.Errorf("expected ComputeAssets to return image assets")
+	}
+	for _, imageAsset := range imageAssets {
+		if !images[imageAsset.DownloadLocation] {
+			t.Errorf("image %q returned by ComputeAssets is not returned by Run", imageAsset.DownloadLocation)
+		}
+	}
+}
@@ -66,6 +66,18 @@ func (m *AWSMachineTypeInfo) EphemeralDevices() []*EphemeralDevice {
 	return disks
 }
 
+// AmazonVPCMaxPods returns the maximum number of pods the machine type supports with the AWS VPC CNI plugin,
+// or 0 if it is not known. The calculation is based on:
+// https://github.com/aws/amazon-vpc-cni-k8s/blob/f52ad45/README.md
+func (m *AWSMachineTypeInfo) AmazonVPCMaxPods() int {
+	enis := m.InstanceENIs
+	ips := m.InstanceIPsPerENI
+	if enis <= 0 || ips <= 0 {
+		return 0
+	}
+	return enis*(ips-1) + 2
+}
+
 func GetMachineTypeInfo(c AWSCloud, machineType string) (*AWSMachineTypeInfo, error) {
 
 	machineTypeMutex.Lock()
@@ -0,0 +1,40 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsup
+
+import (
+	"testing"
+)
+
+func TestAmazonVPCMaxPods(t *testing.T) {
+	grid := []struct {
+		ENIs     int
+		IPs      int
+		Expected int
+	}{
+		{ENIs: 2, IPs: 2, Expected: 4},
+		{ENIs: 3, IPs: 6, Expected: 17},
+		{ENIs: 0, IPs: 6, Expected: 0},
+		{ENIs: 3, IPs: 0, Expected: 0},
+	}
+	for _, g := range grid {
+		info := &AWSMachineTypeInfo{InstanceENIs: g.ENIs, InstanceIPsPerENI: g.IPs}
+		if actual := info.AmazonVPCMaxPods(); actual != g.Expected {
+			t.Errorf("unexpected max pods for %d ENIs with %d IPs: expected %d, got %d", g.ENIs, g.IPs, g.Expected, actual)
+		}
+	}
+}
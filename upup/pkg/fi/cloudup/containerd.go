@@ -57,7 +57,7 @@ func findContainerdAsset(c *kops.Cluster, assetBuilder *assets.AssetBuilder, arc
 		}
 	}
 
-	version := fi.StringValue(containerd.Version)
+	version := fi.StringValue(containerd.VersionForArchitecture(arch))
 	if version == "" {
 		return nil, nil, fmt.Errorf("unable to find containerd version")
 	}
@@ -22,6 +22,9 @@ import (
 	"reflect"
 	"testing"
 
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/util/pkg/architectures"
 )
 
@@ -311,6 +314,69 @@ func TestContainerdVersionHash(t *testing.T) {
 	}
 }
 
+func TestFindContainerdAssetVersionForArchitecture(t *testing.T) {
+	tests := []struct {
+		containerd *kopsapi.ContainerdConfig
+		arch       architectures.Architecture
+		url        string
+	}{
+		{
+			containerd: &kopsapi.ContainerdConfig{
+				Version: fi.String("1.4.6"),
+			},
+			arch: architectures.ArchitectureAmd64,
+			url:  "https://github.com/containerd/containerd/releases/download/v1.4.6/cri-containerd-cni-1.4.6-linux-amd64.tar.gz",
+		},
+		{
+			containerd: &kopsapi.ContainerdConfig{
+				Version: fi.String("1.4.6"),
+			},
+			arch: architectures.ArchitectureArm64,
+			url:  "https://download.docker.com/linux/static/stable/aarch64/docker-20.10.7.tgz",
+		},
+		{
+			containerd: &kopsapi.ContainerdConfig{
+				Version:      fi.String("1.4.6"),
+				VersionAmd64: fi.String("1.3.10"),
+			},
+			arch: architectures.ArchitectureAmd64,
+			url:  "https://github.com/containerd/containerd/releases/download/v1.3.10/cri-containerd-cni-1.3.10-linux-amd64.tar.gz",
+		},
+		{
+			containerd: &kopsapi.ContainerdConfig{
+				Version:      fi.String("1.4.6"),
+				VersionAmd64: fi.String("1.3.10"),
+			},
+			arch: architectures.ArchitectureArm64,
+			url:  "https://download.docker.com/linux/static/stable/aarch64/docker-20.10.7.tgz",
+		},
+		{
+			containerd: &kopsapi.ContainerdConfig{
+				Version:      fi.String("1.4.6"),
+				VersionArm64: fi.String("1.4.4"),
+			},
+			arch: architectures.ArchitectureArm64,
+			url:  "https://download.docker.com/linux/static/stable/aarch64/docker-20.10.6.tgz",
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s-%s-%s", fi.StringValue(test.containerd.VersionAmd64), fi.StringValue(test.containerd.VersionArm64), test.arch), func(t *testing.T) {
+			cluster := &kopsapi.Cluster{}
+			cluster.Spec.KubernetesVersion = "1.21.0"
+			cluster.Spec.Containerd = test.containerd
+			assetBuilder := assets.NewAssetBuilder(cluster, false)
+
+			u, _, err := findContainerdAsset(cluster, assetBuilder, test.arch)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if u.String() != test.url {
+				t.Errorf("actual url %q differs from expected url %q", u.String(), test.url)
+			}
+		})
+	}
+}
+
 func TestContainerdVersionsHashesAmd64(t *testing.T) {
 	if os.Getenv("VERIFY_HASHES") == "" {
 		t.Skip("VERIFY_HASHES not set, won't download & verify docker hashes")
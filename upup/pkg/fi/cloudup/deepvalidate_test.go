@@ -39,6 +39,27 @@ func TestDeepValidate_OK(t *testing.T) {
 	}
 }
 
+func TestDeepValidateWarnings(t *testing.T) {
+	c := buildDefaultCluster(t)
+	var groups []*kopsapi.InstanceGroup
+	for _, subnet := range c.Spec.Subnets {
+		groups = append(groups, buildMinimalMasterInstanceGroup(subnet.Name))
+		groups = append(groups, buildMinimalNodeInstanceGroup(subnet.Name))
+	}
+	if warnings := validation.DeepValidateWarnings(c, groups, nil); len(warnings) != 0 {
+		t.Fatalf("Expected no warnings from DeepValidateWarnings, got %v", warnings)
+	}
+
+	c.Spec.EtcdClusters[0].Members[0].VolumeSize = fi.Int32(10)
+	if err := validation.DeepValidate(c, groups, true, nil); err != nil {
+		t.Fatalf("Expected no error from DeepValidate, got %v", err)
+	}
+	warnings := validation.DeepValidateWarnings(c, groups, nil)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "below the recommended") {
+		t.Fatalf("Expected a volumeSize warning from DeepValidateWarnings, got %v", warnings)
+	}
+}
+
 func TestDeepValidate_NoNodeZones(t *testing.T) {
 	c := buildDefaultCluster(t)
 	var groups []*kopsapi.InstanceGroup
@@ -53,6 +74,28 @@ func TestDeepValidate_NoMasterZones(t *testing.T) {
 	expectErrorFromDeepValidate(t, c, groups, "must configure at least one Master InstanceGroup")
 }
 
+func TestDeepValidate_BastionWithoutInstanceGroup(t *testing.T) {
+	c := buildDefaultCluster(t)
+	c.Spec.Topology = &kopsapi.TopologySpec{
+		Masters: kopsapi.TopologyPrivate,
+		Nodes:   kopsapi.TopologyPrivate,
+		Bastion: &kopsapi.BastionSpec{},
+	}
+	c.Spec.Subnets = append(c.Spec.Subnets, kopsapi.ClusterSubnetSpec{Name: "utility-us-mock-1a", Zone: "us-mock-1a", CIDR: "172.20.4.0/24", Type: kopsapi.SubnetTypeUtility})
+	var groups []*kopsapi.InstanceGroup
+	groups = append(groups, buildMinimalMasterInstanceGroup("subnet-us-mock-1a"))
+	groups = append(groups, buildMinimalNodeInstanceGroup("subnet-us-mock-1a"))
+	expectErrorFromDeepValidate(t, c, groups, "spec.topology.bastion: Required value")
+
+	bastion := buildMinimalNodeInstanceGroup("utility-us-mock-1a")
+	bastion.ObjectMeta.Name = "bastions"
+	bastion.Spec.Role = kopsapi.InstanceGroupRoleBastion
+	groups = append(groups, bastion)
+	if err := validation.DeepValidate(c, groups, true, nil); err != nil {
+		t.Fatalf("Expected no error from DeepValidate with a bastion InstanceGroup, got %v", err)
+	}
+}
+
 func TestDeepValidate_BadZone(t *testing.T) {
 	t.Skipf("Zone validation not checked by DeepValidate")
 	c := buildDefaultCluster(t)
@@ -26,6 +26,9 @@ const (
 	PhaseNetwork Phase = "network"
 	// PhaseSecurity creates IAM profiles and roles, security groups and firewalls
 	PhaseSecurity Phase = "security"
+	// PhaseIAM creates only IAM profiles, roles and OIDC providers. It is only supported on AWS.
+	// It is not named "iam", which is kept as an alias for PhaseSecurity.
+	PhaseIAM Phase = "iam-only"
 	// PhaseCluster creates the servers, and load-alancers
 	PhaseCluster Phase = "cluster"
 )
@@ -33,6 +36,7 @@ const (
 // Phases are used for validation and cli help.
 var Phases = sets.NewString(
 	string(PhaseSecurity),
+	string(PhaseIAM),
 	string(PhaseNetwork),
 	string(PhaseCluster),
 )
@@ -338,6 +338,13 @@ func TestPopulateCluster_NonMasqueradeCIDR_Required(t *testing.T) {
 	expectErrorFromPopulateCluster(t, c, cloud, "nonMasqueradeCIDR")
 }
 
+func TestPopulateCluster_IPv6Subnets_Kubenet(t *testing.T) {
+	cloud, c := buildMinimalCluster()
+	c.Spec.Subnets[0].IPv6CIDR = "2001:db8::/64"
+
+	expectErrorFromPopulateCluster(t, c, cloud, "kubenet")
+}
+
 func TestPopulateCluster_CloudProvider_Required(t *testing.T) {
 	cloud, c := buildMinimalCluster()
 	c.Spec.CloudProvider = ""
@@ -488,7 +488,7 @@ func (tf *TemplateFunctions) KopsControllerConfig() (string, error) {
 
 		pkiDir := "/etc/kubernetes/kops-controller/pki"
 		config.Server = &kopscontrollerconfig.ServerOptions{
-			Listen:                fmt.Sprintf(":%d", wellknownports.KopsControllerPort),
+			Listen:                fmt.Sprintf(":%d", cluster.Spec.GetKopsControllerPort()),
 			ServerCertificatePath: path.Join(pkiDir, "kops-controller.crt"),
 			ServerKeyPath:         path.Join(pkiDir, "kops-controller.key"),
 			CABasePath:            pkiDir,
@@ -24,6 +24,7 @@ import (
 
 	"k8s.io/klog/v2"
 	"k8s.io/kops"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/assets"
 	"k8s.io/kops/util/pkg/architectures"
 	"k8s.io/kops/util/pkg/hashing"
@@ -104,6 +105,41 @@ func NodeUpAsset(assetsBuilder *assets.AssetBuilder, arch architectures.Architec
 	return nodeUpAsset[arch], nil
 }
 
+// findNodeUpAsset returns the asset for where nodeup should be downloaded,
+// preferring the location pinned in the cluster spec to the default location
+func findNodeUpAsset(cluster *kopsapi.Cluster, assetsBuilder *assets.AssetBuilder, arch architectures.Architecture) (*mirrors.MirroredAsset, error) {
+	if nodeUp := cluster.Spec.NodeUp; nodeUp != nil {
+		asset, err := pinnedAsset(assetsBuilder, arch, "nodeup", nodeUp.URL, nodeUp.Hash, nodeUp.URLArm64, nodeUp.HashArm64)
+		if err != nil || asset != nil {
+			return asset, err
+		}
+	}
+
+	return NodeUpAsset(assetsBuilder, arch)
+}
+
+// pinnedAsset returns the asset for the named binary from the URL and hash pinned in the cluster spec
+// for the architecture, or nil if no URL is pinned for it
+func pinnedAsset(assetsBuilder *assets.AssetBuilder, arch architectures.Architecture, binary string, urlAmd64, hashAmd64, urlArm64, hashArm64 string) (*mirrors.MirroredAsset, error) {
+	var assetURL, assetHash string
+	switch arch {
+	case architectures.ArchitectureAmd64:
+		assetURL, assetHash = urlAmd64, hashAmd64
+	case architectures.ArchitectureArm64:
+		assetURL, assetHash = urlArm64, hashArm64
+	}
+	if assetURL == "" {
+		return nil, nil
+	}
+
+	u, h, err := findAssetsUrlHash(assetsBuilder, assetURL, assetHash)
+	if err != nil {
+		return nil, err
+	}
+	klog.V(8).Infof("Using %s location for %s from cluster spec: %q", binary, arch, u.String())
+	return mirrors.BuildMirroredAsset(u, h), nil
+}
+
 // ProtokubeAsset returns the url and hash of the protokube binary
 func ProtokubeAsset(assetsBuilder *assets.AssetBuilder, arch architectures.Architecture) (*mirrors.MirroredAsset, error) {
 	if protokubeAsset == nil {
@@ -124,6 +160,19 @@ func ProtokubeAsset(assetsBuilder *assets.AssetBuilder, arch architectures.Archi
 	return protokubeAsset[arch], nil
 }
 
+// findProtokubeAsset returns the url and hash of the protokube binary,
+// preferring the location pinned in the cluster spec to the default location
+func findProtokubeAsset(cluster *kopsapi.Cluster, assetsBuilder *assets.AssetBuilder, arch architectures.Architecture) (*mirrors.MirroredAsset, error) {
+	if protokube := cluster.Spec.Protokube; protokube != nil {
+		asset, err := pinnedAsset(assetsBuilder, arch, "protokube", protokube.URL, protokube.Hash, protokube.URLArm64, protokube.HashArm64)
+		if err != nil || asset != nil {
+			return asset, err
+		}
+	}
+
+	return ProtokubeAsset(assetsBuilder, arch)
+}
+
 // ChannelsAsset returns the url and hash of the channels binary
 func ChannelsAsset(assetsBuilder *assets.AssetBuilder, arch architectures.Architecture) (*mirrors.MirroredAsset, error) {
 	if channelsAsset == nil {
@@ -144,6 +193,19 @@ func ChannelsAsset(assetsBuilder *assets.AssetBuilder, arch architectures.Archit
 	return channelsAsset[arch], nil
 }
 
+// findChannelsAsset returns the url and hash of the channels binary,
+// preferring the location pinned in the cluster spec to the default location
+func findChannelsAsset(cluster *kopsapi.Cluster, assetsBuilder *assets.AssetBuilder, arch architectures.Architecture) (*mirrors.MirroredAsset, error) {
+	if channels := cluster.Spec.Channels; channels != nil {
+		asset, err := pinnedAsset(assetsBuilder, arch, "channels", channels.URL, channels.Hash, channels.URLArm64, channels.HashArm64)
+		if err != nil || asset != nil {
+			return asset, err
+		}
+	}
+
+	return ChannelsAsset(assetsBuilder, arch)
+}
+
 // KopsFileURL returns the base url for the distribution of kops - in particular for nodeup & docker images
 func KopsFileURL(file string, assetBuilder *assets.AssetBuilder) (*url.URL, *hashing.Hash, error) {
 	base, err := BaseURL()
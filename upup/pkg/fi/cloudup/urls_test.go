@@ -18,11 +18,17 @@ package cloudup
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"k8s.io/kops"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/util/pkg/architectures"
 	"k8s.io/kops/util/pkg/hashing"
 	"k8s.io/kops/util/pkg/mirrors"
 )
@@ -79,3 +85,135 @@ func Test_BuildMirroredAsset(t *testing.T) {
 		})
 	}
 }
+
+func Test_FindKopsBinaryAsset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kopsbinaryasset")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Derive the default location from a base URL whose hashes are only available from the hash cache
+	os.Setenv("KOPS_BASE_URL", "https://example.invalid/kops/")
+	defer os.Unsetenv("KOPS_BASE_URL")
+	resetCaches := func() {
+		kopsBaseURL = nil
+		nodeUpAsset = nil
+		protokubeAsset = nil
+		channelsAsset = nil
+	}
+	resetCaches()
+	defer resetCaches()
+
+	const defaultHash = "1111111111111111111111111111111111111111111111111111111111111111"
+	const pinnedHash = "2222222222222222222222222222222222222222222222222222222222222222"
+
+	binaries := []struct {
+		name string
+		pin  func(spec *kopsapi.ClusterSpec, url, hash, urlArm64, hashArm64 string)
+		find func(cluster *kopsapi.Cluster, assetsBuilder *assets.AssetBuilder, arch architectures.Architecture) (*mirrors.MirroredAsset, error)
+	}{
+		{
+			name: "nodeup",
+			pin: func(spec *kopsapi.ClusterSpec, url, hash, urlArm64, hashArm64 string) {
+				spec.NodeUp = &kopsapi.NodeUpSpec{URL: url, Hash: hash, URLArm64: urlArm64, HashArm64: hashArm64}
+			},
+			find: findNodeUpAsset,
+		},
+		{
+			name: "protokube",
+			pin: func(spec *kopsapi.ClusterSpec, url, hash, urlArm64, hashArm64 string) {
+				spec.Protokube = &kopsapi.ProtokubeSpec{URL: url, Hash: hash, URLArm64: urlArm64, HashArm64: hashArm64}
+			},
+			find: findProtokubeAsset,
+		},
+		{
+			name: "channels",
+			pin: func(spec *kopsapi.ClusterSpec, url, hash, urlArm64, hashArm64 string) {
+				spec.Channels = &kopsapi.ChannelsSpec{URL: url, Hash: hash, URLArm64: urlArm64, HashArm64: hashArm64}
+			},
+			find: findChannelsAsset,
+		},
+	}
+
+	// The URLs are formatted with the name of the binary
+	tests := []struct {
+		name             string
+		url              string
+		hash             string
+		urlArm64         string
+		hashArm64        string
+		arch             architectures.Architecture
+		expectedLocation string
+		expectedHash     string
+	}{
+		{
+			name:             "default amd64",
+			arch:             architectures.ArchitectureAmd64,
+			expectedLocation: "https://example.invalid/kops/linux/amd64/%s",
+			expectedHash:     defaultHash,
+		},
+		{
+			name:             "pinned amd64",
+			url:              "https://example.com/custom/%s",
+			hash:             pinnedHash,
+			arch:             architectures.ArchitectureAmd64,
+			expectedLocation: "https://example.com/custom/%s",
+			expectedHash:     pinnedHash,
+		},
+		{
+			name:             "pinned amd64 only",
+			url:              "https://example.com/custom/%s",
+			hash:             pinnedHash,
+			arch:             architectures.ArchitectureArm64,
+			expectedLocation: "https://example.invalid/kops/linux/arm64/%s",
+			expectedHash:     defaultHash,
+		},
+		{
+			name:             "pinned arm64",
+			urlArm64:         "https://example.com/custom/%s-arm64",
+			hashArm64:        pinnedHash,
+			arch:             architectures.ArchitectureArm64,
+			expectedLocation: "https://example.com/custom/%s-arm64",
+			expectedHash:     pinnedHash,
+		},
+	}
+	for _, binary := range binaries {
+		for _, tc := range tests {
+			t.Run(binary.name+" "+tc.name, func(t *testing.T) {
+				formatURL := func(u string) string {
+					if u == "" {
+						return ""
+					}
+					return fmt.Sprintf(u, binary.name)
+				}
+
+				cluster := &kopsapi.Cluster{}
+				cluster.Spec.KubernetesVersion = "1.21.0"
+				if tc.url != "" || tc.urlArm64 != "" {
+					binary.pin(&cluster.Spec, formatURL(tc.url), tc.hash, formatURL(tc.urlArm64), tc.hashArm64)
+				}
+
+				assetBuilder := assets.NewAssetBuilder(cluster, false)
+				assetBuilder.HashCache = assets.NewHashCache(filepath.Join(dir, "asset-hashes.json"))
+				for _, arch := range architectures.GetSupported() {
+					if err := assetBuilder.HashCache.Put("https://example.invalid/kops/linux/"+string(arch)+"/"+binary.name, hashing.MustFromString(defaultHash)); err != nil {
+						t.Fatalf("error adding hash to cache: %v", err)
+					}
+				}
+
+				actual, err := binary.find(cluster, assetBuilder, tc.arch)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				expectedLocation := formatURL(tc.expectedLocation)
+				if len(actual.Locations) == 0 || actual.Locations[0] != expectedLocation {
+					t.Errorf("unexpected locations: expected %q first, got %v", expectedLocation, actual.Locations)
+				}
+				if actual.Hash.Hex() != tc.expectedHash {
+					t.Errorf("unexpected hash: expected %q, got %q", tc.expectedHash, actual.Hash.Hex())
+				}
+			})
+		}
+	}
+}
@@ -45,6 +45,12 @@ type DryRunTarget struct {
 
 	// assetBuilder records all assets used
 	assetBuilder *assets.AssetBuilder
+
+	// taskSources optionally records the name of the builder that produced each task, keyed by task key
+	taskSources map[string]string
+
+	// pendingDeletions are the keys of the tasks which delete cloud objects that are no longer in the model
+	pendingDeletions []string
 }
 
 type render struct {
@@ -81,6 +87,18 @@ func NewDryRunTarget(assetBuilder *assets.AssetBuilder, out io.Writer) *DryRunTa
 	return t
 }
 
+// SetTaskSources records the builder that produced each task, keyed by task key.
+// When set, the report includes a summary of the tasks grouped by builder.
+func (t *DryRunTarget) SetTaskSources(taskSources map[string]string) {
+	t.taskSources = taskSources
+}
+
+// SetPendingDeletions records the keys of the tasks which delete cloud objects that are no longer in the model.
+// They are reported along with the other deletions.
+func (t *DryRunTarget) SetPendingDeletions(taskKeys []string) {
+	t.pendingDeletions = taskKeys
+}
+
 func (t *DryRunTarget) ProcessDeletions() bool {
 	// We display deletions
 	return true
@@ -242,7 +260,7 @@ func (t *DryRunTarget) PrintReport(taskMap map[string]Task, out io.Writer) error
 		}
 	}
 
-	if len(t.deletions) != 0 {
+	if len(t.deletions) != 0 || len(t.pendingDeletions) != 0 {
 		// Give everything a consistent ordering
 		sort.Sort(DeletionByTaskName(t.deletions))
 
@@ -250,6 +268,18 @@ func (t *DryRunTarget) PrintReport(taskMap map[string]Task, out io.Writer) error
 		for _, d := range t.deletions {
 			fmt.Fprintf(b, "  %-20s %s\n", d.TaskName(), d.Item())
 		}
+		for _, key := range t.pendingDeletions {
+			// Split the task key (taskType/taskName) as for the other deletions
+			taskName, item := key, ""
+			if firstSlash := strings.Index(key, "/"); firstSlash != -1 {
+				taskName, item = key[:firstSlash], key[firstSlash+1:]
+			}
+			fmt.Fprintf(b, "  %-20s %s\n", taskName, item)
+		}
+	}
+
+	if len(t.taskSources) != 0 {
+		printTasksByBuilder(b, taskMap, t.taskSources)
 	}
 
 	if len(t.assetBuilder.ImageAssets) != 0 {
@@ -272,6 +302,36 @@ func (t *DryRunTarget) PrintReport(taskMap map[string]Task, out io.Writer) error
 	return err
 }
 
+// printTasksByBuilder writes the keys of the tasks in taskMap, grouped by the builder that produced them.
+func printTasksByBuilder(b *bytes.Buffer, taskMap map[string]Task, taskSources map[string]string) {
+	byBuilder := make(map[string][]string)
+	for key := range taskMap {
+		builder := taskSources[key]
+		if builder == "" {
+			builder = "(unknown)"
+		}
+		byBuilder[builder] = append(byBuilder[builder], key)
+	}
+
+	var builders []string
+	for builder := range byBuilder {
+		builders = append(builders, builder)
+	}
+	sort.Strings(builders)
+
+	fmt.Fprintf(b, "Tasks by builder:\n")
+	for _, builder := range builders {
+		keys := byBuilder[builder]
+		sort.Strings(keys)
+
+		fmt.Fprintf(b, "  %s\n", builder)
+		for _, key := range keys {
+			fmt.Fprintf(b, "  \t%s\n", key)
+		}
+	}
+	fmt.Fprintf(b, "\n")
+}
+
 type change struct {
 	FieldName   string
 	Description string
@@ -397,6 +457,7 @@ func (t *DryRunTarget) Deletions() []string {
 	for _, d := range t.deletions {
 		deletions = append(deletions, d.TaskName())
 	}
+	deletions = append(deletions, t.pendingDeletions...)
 	return deletions
 }
 
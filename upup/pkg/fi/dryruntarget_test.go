@@ -95,3 +95,61 @@ func Test_DryrunTarget_PrintReport(t *testing.T) {
 	err = target.PrintReport(tasks, &out)
 	assert.NoError(t, err, "target.PrintReport()")
 }
+
+func Test_DryrunTarget_PrintReport_TaskSources(t *testing.T) {
+	builder := assets.NewAssetBuilder(&api.Cluster{
+		Spec: api.ClusterSpec{
+			KubernetesVersion: "1.17.3",
+		},
+	}, false)
+	var stdout bytes.Buffer
+	target := NewDryRunTarget(builder, &stdout)
+	target.SetTaskSources(map[string]string{
+		"testTask/b": "KubeletBuilder",
+		"testTask/a": "KubeletBuilder",
+		"testTask/c": "ContainerdBuilder",
+	})
+
+	tasks := map[string]Task{
+		"testTask/a": &testTask{Name: String("a")},
+		"testTask/b": &testTask{Name: String("b")},
+		"testTask/c": &testTask{Name: String("c")},
+		"testTask/d": &testTask{Name: String("d")},
+	}
+
+	var out bytes.Buffer
+	err := target.PrintReport(tasks, &out)
+	assert.NoError(t, err, "target.PrintReport()")
+
+	expected := "Tasks by builder:\n" +
+		"  (unknown)\n" +
+		"  \ttestTask/d\n" +
+		"  ContainerdBuilder\n" +
+		"  \ttestTask/c\n" +
+		"  KubeletBuilder\n" +
+		"  \ttestTask/a\n" +
+		"  \ttestTask/b\n" +
+		"\n"
+	assert.Equal(t, expected, out.String())
+}
+
+func Test_DryrunTarget_PrintReport_PendingDeletions(t *testing.T) {
+	builder := assets.NewAssetBuilder(&api.Cluster{
+		Spec: api.ClusterSpec{
+			KubernetesVersion: "1.17.3",
+		},
+	}, false)
+	var stdout bytes.Buffer
+	target := NewDryRunTarget(builder, &stdout)
+	target.SetPendingDeletions([]string{"IAMRole/bastions.example.com", "IAMRole/old.example.com"})
+
+	var out bytes.Buffer
+	err := target.PrintReport(map[string]Task{}, &out)
+	assert.NoError(t, err, "target.PrintReport()")
+
+	expected := "Will delete items:\n" +
+		"  IAMRole              bastions.example.com\n" +
+		"  IAMRole              old.example.com\n"
+	assert.Equal(t, expected, out.String())
+	assert.Equal(t, []string{"IAMRole/bastions.example.com", "IAMRole/old.example.com"}, target.Deletions())
+}
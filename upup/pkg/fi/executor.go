@@ -43,6 +43,10 @@ type taskState struct {
 type RunTasksOptions struct {
 	MaxTaskDuration         time.Duration
 	WaitAfterAllTasksFailed time.Duration
+
+	// ObserveTaskDuration, if set, is called with the duration of every attempt to run a task.
+	// It is not called concurrently. Tasks are not timed when it is nil.
+	ObserveTaskDuration func(key string, duration time.Duration)
 }
 
 func (o *RunTasksOptions) InitDefaults() {
@@ -118,7 +122,13 @@ func (e *executor) RunTasks(taskMap map[string]Task) error {
 		var tasks []*taskState
 		tasks = append(tasks, canRun...)
 
-		taskErrors := e.forkJoin(tasks)
+		taskErrors, taskDurations := e.forkJoin(tasks)
+		if e.options.ObserveTaskDuration != nil {
+			for i, ts := range tasks {
+				e.options.ObserveTaskDuration(ts.key, taskDurations[i])
+			}
+		}
+
 		var errors []error
 		for i, err := range taskErrors {
 			ts := tasks[i]
@@ -171,24 +181,34 @@ func (e *executor) RunTasks(taskMap map[string]Task) error {
 	return nil
 }
 
-func (e *executor) forkJoin(tasks []*taskState) []error {
+// forkJoin runs the tasks in parallel, returning their errors and, if tasks are being timed, their durations.
+func (e *executor) forkJoin(tasks []*taskState) ([]error, []time.Duration) {
 	if len(tasks) == 0 {
-		return nil
+		return nil, nil
 	}
 
+	timed := e.options.ObserveTaskDuration != nil
+
 	var wg sync.WaitGroup
 	results := make([]error, len(tasks))
+	durations := make([]time.Duration, len(tasks))
 	for i := 0; i < len(tasks); i++ {
 		wg.Add(1)
 		go func(ts *taskState, index int) {
 			results[index] = fmt.Errorf("function panic")
 			defer wg.Done()
 			klog.V(2).Infof("Executing task %q: %v\n", ts.key, ts.task)
+			if timed {
+				start := time.Now()
+				defer func() {
+					durations[index] = time.Since(start)
+				}()
+			}
 			results[index] = ts.task.Run(e.context)
 		}(tasks[i], i)
 	}
 
 	wg.Wait()
 
-	return results
+	return results, durations
 }
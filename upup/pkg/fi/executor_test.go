@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// retryTask is a trivial task which sleeps, and fails the first failures times it is run.
+type retryTask struct {
+	sleep    time.Duration
+	failures int
+	runs     int
+}
+
+var _ Task = &retryTask{}
+
+func (t *retryTask) Run(c *Context) error {
+	t.runs++
+	time.Sleep(t.sleep)
+	if t.runs <= t.failures {
+		return fmt.Errorf("failing run %d", t.runs)
+	}
+	return nil
+}
+
+func TestRunTasksObserveTaskDuration(t *testing.T) {
+	tasks := map[string]Task{
+		"fast":  &retryTask{},
+		"slow":  &retryTask{sleep: 10 * time.Millisecond},
+		"retry": &retryTask{sleep: 10 * time.Millisecond, failures: 1},
+	}
+	c := &Context{tasks: tasks}
+
+	attempts := make(map[string]int)
+	timings := make(map[string]time.Duration)
+	options := RunTasksOptions{
+		MaxTaskDuration: time.Minute,
+		ObserveTaskDuration: func(key string, duration time.Duration) {
+			attempts[key]++
+			timings[key] += duration
+		},
+	}
+
+	if err := c.RunTasks(options); err != nil {
+		t.Fatalf("unexpected error from RunTasks: %v", err)
+	}
+
+	expectedAttempts := map[string]int{"fast": 1, "slow": 1, "retry": 2}
+	for key, expected := range expectedAttempts {
+		if attempts[key] != expected {
+			t.Errorf("expected %d timing(s) for task %q, got %d", expected, key, attempts[key])
+		}
+	}
+	if timings["slow"] < 10*time.Millisecond {
+		t.Errorf("expected task %q to take at least 10ms, got %v", "slow", timings["slow"])
+	}
+	if timings["retry"] < 20*time.Millisecond {
+		t.Errorf("expected task %q to take at least 20ms over two runs, got %v", "retry", timings["retry"])
+	}
+}
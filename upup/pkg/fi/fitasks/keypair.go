@@ -42,6 +42,8 @@ type Keypair struct {
 	Subject string `json:"subject"`
 	// Type the type of certificate i.e. CA, server, client etc
 	Type string `json:"type"`
+	// KeyType is the type of private key to generate for a new keyset i.e. rsa-2048, rsa-4096, ecdsa-p256
+	KeyType string `json:"keyType,omitempty"`
 	// LegacyFormat is whether the keypair is stored in a legacy format.
 	LegacyFormat bool `json:"oldFormat"`
 
@@ -101,6 +103,8 @@ func (e *Keypair) Find(c *fi.Context) (*Keypair, error) {
 
 	// Avoid spurious changes
 	actual.Lifecycle = e.Lifecycle
+	// The key type only applies when generating a new private key
+	actual.KeyType = e.KeyType
 
 	if err := e.setResources(keyset); err != nil {
 		return nil, fmt.Errorf("error setting resources: %v", err)
@@ -220,6 +224,7 @@ func (_ *Keypair) Render(c *fi.Context, a, e, changes *Keypair) error {
 			Subject:        *subjectPkix,
 			AlternateNames: e.AlternateNames,
 			PrivateKey:     privateKey,
+			KeyType:        e.KeyType,
 			Serial:         serial,
 		}
 		cert, privateKey, _, err := pki.IssueCert(&req, c.Keystore)
@@ -28,6 +28,17 @@ import (
 	"k8s.io/kops/util/pkg/hashing"
 )
 
+// HashMismatchError is returned by DownloadURL when the downloaded content does not match the expected hash.
+type HashMismatchError struct {
+	URL      string
+	Expected *hashing.Hash
+	Actual   *hashing.Hash
+}
+
+func (e *HashMismatchError) Error() string {
+	return fmt.Sprintf("downloaded from %q but hash %q did not match expected %q", e.URL, e.Actual, e.Expected)
+}
+
 func DownloadURL(url string, dest string, hash *hashing.Hash) (*hashing.Hash, error) {
 	if hash != nil {
 		match, err := fileHasHash(dest, hash)
@@ -46,12 +57,12 @@ func DownloadURL(url string, dest string, hash *hashing.Hash) (*hashing.Hash, er
 	}
 
 	if hash != nil {
-		match, err := fileHasHash(dest, hash)
+		actual, err := hash.Algorithm.HashFile(dest)
 		if err != nil {
 			return nil, err
 		}
-		if !match {
-			return nil, fmt.Errorf("downloaded from %q but hash did not match expected %q", url, hash)
+		if !actual.Equal(hash) {
+			return nil, &HashMismatchError{URL: url, Expected: hash, Actual: actual}
 		}
 	} else {
 		hash, err = hashing.HashAlgorithmSHA256.HashFile(dest)
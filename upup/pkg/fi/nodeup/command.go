@@ -24,8 +24,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"net"
+	"net/http"
 	"net/url"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -53,6 +56,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 )
 
@@ -61,12 +65,26 @@ const MaxTaskDuration = 365 * 24 * time.Hour
 
 // NodeUpCommand is the configuration for nodeup
 type NodeUpCommand struct {
-	CacheDir       string
+	CacheDir string
+	// ConfigLocation is the VFS path of the nodeup config, or "-" to read the config from Stdin
 	ConfigLocation string
-	Target         string
-	cluster        *api.Cluster
-	config         *nodeup.Config
-	auxConfig      *nodeup.AuxConfig
+	// Stdin is read for the nodeup config when ConfigLocation is "-"; it defaults to os.Stdin
+	Stdin io.Reader
+	// ClusterConfig, if set, is the cluster config, used instead of reading it from the ClusterLocation or ConfigBase.
+	// A ConfigServer in the nodeup config takes precedence, as it always provides the cluster config.
+	ClusterConfig []byte
+	// AuxConfig, if set, is the auxiliary config, used instead of reading it from the ConfigBase.
+	// It must still match the AuxConfigHash in the nodeup config. A ConfigServer in the nodeup config takes precedence.
+	AuxConfig []byte
+	// SkipBuilders is the type names of builders which should not be run, for example "DockerBuilder"
+	SkipBuilders []string
+	// StrictAssetVerification causes nodeup to abort if any asset does not match its declared hash,
+	// instead of trying the asset's other URLs
+	StrictAssetVerification bool
+	Target                  string
+	cluster                 *api.Cluster
+	config                  *nodeup.Config
+	auxConfig               *nodeup.AuxConfig
 }
 
 // Run is responsible for perform the nodeup process
@@ -74,7 +92,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	ctx := context.Background()
 
 	if c.ConfigLocation != "" {
-		config, err := vfs.Context.ReadFile(c.ConfigLocation)
+		config, err := c.readConfig()
 		if err != nil {
 			return fmt.Errorf("error loading configuration %q: %v", c.ConfigLocation, err)
 		}
@@ -124,66 +142,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		return fmt.Errorf("ConfigBase or ConfigServer is required")
 	}
 
-	c.cluster = &api.Cluster{}
-	if nodeConfig != nil {
-		if err := utils.YamlUnmarshal([]byte(nodeConfig.ClusterFullConfig), c.cluster); err != nil {
-			return fmt.Errorf("error parsing Cluster config response: %w", err)
-		}
-	} else {
-		clusterLocation := fi.StringValue(c.config.ClusterLocation)
-
-		var p vfs.Path
-		if clusterLocation != "" {
-			var err error
-			p, err = vfs.Context.BuildVfsPath(clusterLocation)
-			if err != nil {
-				return fmt.Errorf("error parsing ClusterLocation %q: %v", clusterLocation, err)
-			}
-		} else {
-			p = configBase.Join(registry.PathClusterCompleted)
-		}
-
-		b, err := p.ReadFile()
-		if err != nil {
-			return fmt.Errorf("error loading Cluster %q: %v", p, err)
-		}
-
-		err = utils.YamlUnmarshal(b, c.cluster)
-		if err != nil {
-			return fmt.Errorf("error parsing Cluster %q: %v", p, err)
-		}
-	}
-
-	var auxConfigHash [32]byte
-	if nodeConfig != nil {
-		c.auxConfig = &nodeup.AuxConfig{}
-		if err := utils.YamlUnmarshal([]byte(nodeConfig.AuxConfig), c.auxConfig); err != nil {
-			return fmt.Errorf("error parsing AuxConfig config response: %v", err)
-		}
-		auxConfigHash = sha256.Sum256([]byte(nodeConfig.AuxConfig))
-	} else if c.config.InstanceGroupName != "" {
-		auxConfigLocation := configBase.Join("igconfig", strings.ToLower(string(c.config.InstanceGroupRole)), c.config.InstanceGroupName, "auxconfig.yaml")
-
-		c.auxConfig = &nodeup.AuxConfig{}
-		b, err := auxConfigLocation.ReadFile()
-		if err != nil {
-			return fmt.Errorf("error loading AuxConfig %q: %v", auxConfigLocation, err)
-		}
-
-		if err = utils.YamlUnmarshal(b, c.auxConfig); err != nil {
-			return fmt.Errorf("error parsing AuxConfig %q: %v", auxConfigLocation, err)
-		}
-		auxConfigHash = sha256.Sum256(b)
-	} else {
-		return fmt.Errorf("no instance group defined in nodeup config")
-	}
-
-	if c.config.AuxConfigHash != base64.StdEncoding.EncodeToString(auxConfigHash[:]) {
-		return fmt.Errorf("auxiliary config hash mismatch")
-	}
-
-	err := evaluateSpec(c)
-	if err != nil {
+	if err := c.loadConfig(nodeConfig, configBase); err != nil {
 		return err
 	}
 
@@ -199,6 +158,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 
 	configAssets := c.config.Assets[architecture]
 	assetStore := fi.NewAssetStore(c.CacheDir)
+	assetStore.StrictVerification = c.StrictAssetVerification
 	for _, asset := range configAssets {
 		err := assetStore.Add(asset)
 		if err != nil {
@@ -305,6 +265,7 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	loader.Builders = append(loader.Builders, &model.SecretBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.FirewallBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.SysctlBuilder{NodeupModelContext: modelContext})
+	loader.Builders = append(loader.Builders, &model.KernelModulesBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeAPIServerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeControllerManagerBuilder{NodeupModelContext: modelContext})
 	loader.Builders = append(loader.Builders, &model.KubeSchedulerBuilder{NodeupModelContext: modelContext})
@@ -320,12 +281,22 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	loader.Builders = append(loader.Builders, &networking.LyftVPCBuilder{NodeupModelContext: modelContext})
 
 	loader.Builders = append(loader.Builders, &model.BootstrapClientBuilder{NodeupModelContext: modelContext})
+	if err := loader.SkipBuilders(c.SkipBuilders); err != nil {
+		return err
+	}
 	taskMap, err := loader.Build()
 	if err != nil {
 		return fmt.Errorf("error building loader: %v", err)
 	}
 
 	for i, image := range c.config.Images[architecture] {
+		if len(image.Sources) == 0 {
+			taskMap["PullImage."+strconv.Itoa(i)] = &nodetasks.PullImageTask{
+				Name:    image.Name,
+				Runtime: c.cluster.Spec.ContainerRuntime,
+			}
+			continue
+		}
 		taskMap["LoadImage."+strconv.Itoa(i)] = &nodetasks.LoadImageTask{
 			Sources: image.Sources,
 			Hash:    image.Hash,
@@ -344,7 +315,9 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 		}
 	case "dryrun":
 		assetBuilder := assets.NewAssetBuilder(c.cluster, false)
-		target = fi.NewDryRunTarget(assetBuilder, out)
+		dryRunTarget := fi.NewDryRunTarget(assetBuilder, out)
+		dryRunTarget.SetTaskSources(loader.TaskSources)
+		target = dryRunTarget
 	case "cloudinit":
 		checkExisting = false
 		target = cloudinit.NewCloudInitTarget(out)
@@ -382,6 +355,19 @@ func (c *NodeUpCommand) Run(out io.Writer) error {
 	return nil
 }
 
+// readConfig reads the nodeup config from the ConfigLocation, or from Stdin if the ConfigLocation is "-"
+func (c *NodeUpCommand) readConfig() ([]byte, error) {
+	if c.ConfigLocation != "-" {
+		return vfs.Context.ReadFile(c.ConfigLocation)
+	}
+
+	stdin := c.Stdin
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	return ioutil.ReadAll(stdin)
+}
+
 func completeWarmingLifecycleAction(cloud awsup.AWSCloud, modelContext *model.NodeupModelContext) error {
 	asgName := modelContext.NodeupConfig.InstanceGroupName + "." + modelContext.Cluster.GetName()
 	hookName := "kops-warmpool"
@@ -396,6 +382,10 @@ func completeWarmingLifecycleAction(cloud awsup.AWSCloud, modelContext *model.No
 
 	if len(hooks.LifecycleHooks) > 0 {
 		klog.Info("Found ASG lifecycle hook")
+		if gracePeriod := modelContext.NodeupConfig.LifecycleHookGracePeriod; gracePeriod != nil && gracePeriod.Duration > 0 {
+			klog.Infof("Waiting %v before completing lifecycle action", gracePeriod.Duration)
+			time.Sleep(gracePeriod.Duration)
+		}
 		_, err := svc.CompleteLifecycleAction(&autoscaling.CompleteLifecycleActionInput{
 			AutoScalingGroupName:  &asgName,
 			InstanceId:            &modelContext.InstanceID,
@@ -412,6 +402,80 @@ func completeWarmingLifecycleAction(cloud awsup.AWSCloud, modelContext *model.No
 	return nil
 }
 
+// loadConfig loads the cluster and auxiliary configs, from the config server response if nodeConfig is set,
+// otherwise from the inline configs or configBase, verifies the auxiliary config hash and evaluates the spec.
+func (c *NodeUpCommand) loadConfig(nodeConfig *nodeup.NodeConfig, configBase vfs.Path) error {
+	c.cluster = &api.Cluster{}
+	if nodeConfig != nil {
+		if err := utils.YamlUnmarshal([]byte(nodeConfig.ClusterFullConfig), c.cluster); err != nil {
+			return fmt.Errorf("error parsing Cluster config response: %w", err)
+		}
+	} else if c.ClusterConfig != nil {
+		if err := utils.YamlUnmarshal(c.ClusterConfig, c.cluster); err != nil {
+			return fmt.Errorf("error parsing inline Cluster config: %v", err)
+		}
+	} else {
+		clusterLocation := fi.StringValue(c.config.ClusterLocation)
+
+		var p vfs.Path
+		if clusterLocation != "" {
+			var err error
+			p, err = vfs.Context.BuildVfsPath(clusterLocation)
+			if err != nil {
+				return fmt.Errorf("error parsing ClusterLocation %q: %v", clusterLocation, err)
+			}
+		} else {
+			p = configBase.Join(registry.PathClusterCompleted)
+		}
+
+		b, err := p.ReadFile()
+		if err != nil {
+			return fmt.Errorf("error loading Cluster %q: %v", p, err)
+		}
+
+		err = utils.YamlUnmarshal(b, c.cluster)
+		if err != nil {
+			return fmt.Errorf("error parsing Cluster %q: %v", p, err)
+		}
+	}
+
+	var auxConfigHash [32]byte
+	if nodeConfig != nil {
+		c.auxConfig = &nodeup.AuxConfig{}
+		if err := utils.YamlUnmarshal([]byte(nodeConfig.AuxConfig), c.auxConfig); err != nil {
+			return fmt.Errorf("error parsing AuxConfig config response: %v", err)
+		}
+		auxConfigHash = sha256.Sum256([]byte(nodeConfig.AuxConfig))
+	} else if c.AuxConfig != nil {
+		c.auxConfig = &nodeup.AuxConfig{}
+		if err := utils.YamlUnmarshal(c.AuxConfig, c.auxConfig); err != nil {
+			return fmt.Errorf("error parsing inline AuxConfig: %v", err)
+		}
+		auxConfigHash = sha256.Sum256(c.AuxConfig)
+	} else if c.config.InstanceGroupName != "" {
+		auxConfigLocation := configBase.Join("igconfig", strings.ToLower(string(c.config.InstanceGroupRole)), c.config.InstanceGroupName, "auxconfig.yaml")
+
+		c.auxConfig = &nodeup.AuxConfig{}
+		b, err := auxConfigLocation.ReadFile()
+		if err != nil {
+			return fmt.Errorf("error loading AuxConfig %q: %v", auxConfigLocation, err)
+		}
+
+		if err = utils.YamlUnmarshal(b, c.auxConfig); err != nil {
+			return fmt.Errorf("error parsing AuxConfig %q: %v", auxConfigLocation, err)
+		}
+		auxConfigHash = sha256.Sum256(b)
+	} else {
+		return fmt.Errorf("no instance group defined in nodeup config")
+	}
+
+	if c.config.AuxConfigHash != base64.StdEncoding.EncodeToString(auxConfigHash[:]) {
+		return fmt.Errorf("auxiliary config hash mismatch")
+	}
+
+	return evaluateSpec(c)
+}
+
 func evaluateSpec(c *NodeUpCommand) error {
 	var err error
 
@@ -545,6 +609,40 @@ func evaluateHostnameOverride(hostnameOverride string) (string, error) {
 		return fmt.Sprintf("%s.%s", az, instanceID), nil
 	}
 
+	if k == "@azure" {
+		// @azure means to use the name of the virtual machine from the Azure instance metadata service
+		vBytes, err := vfs.Context.ReadFile("metadata://azure/compute/name")
+		if err != nil {
+			return "", fmt.Errorf("error reading VM name from Azure metadata: %v", err)
+		}
+
+		hostname := strings.TrimSpace(string(vBytes))
+		if hostname == "" {
+			return "", errors.New("VM name for azure instance was empty")
+		}
+
+		return hostname, nil
+	}
+
+	if k == "@openstack" {
+		// We recognize @openstack as meaning the hostname from the OpenStack metadata service
+		// This lets us tolerate broken hostnames (i.e. systemd)
+		b, err := vfs.Context.ReadFile("metadata://openstack/hostname")
+		if err != nil {
+			return "", fmt.Errorf("error reading hostname from OpenStack metadata: %v", err)
+		}
+
+		// We only want to use the first portion of the fully-qualified name
+		// e.g. foo.novalocal => foo
+		fullyQualified := strings.TrimSpace(string(b))
+		bareHostname := strings.Split(fullyQualified, ".")[0]
+		if bareHostname == "" {
+			return "", errors.New("hostname for openstack instance was empty")
+		}
+
+		return bareHostname, nil
+	}
+
 	return hostnameOverride, nil
 }
 
@@ -662,15 +760,20 @@ func modprobe(module string) error {
 	return nil
 }
 
-// loadKernelModules is a hack to force br_netfilter to be loaded
+// loadKernelModules loads br_netfilter and the kernel modules from the cluster and instance group specs now,
+// as the sysctls may depend on them; KernelModulesBuilder makes sure they are loaded again on boot.
 // TODO: Move to tasks architecture
 func loadKernelModules(context *model.NodeupModelContext) error {
-	err := modprobe("br_netfilter")
-	if err != nil {
-		// TODO: Return error in 1.11 (too risky for 1.10)
-		klog.Warningf("error loading br_netfilter module: %v", err)
+	for _, module := range context.KernelModules() {
+		if err := modprobe(module); err != nil {
+			if module == "br_netfilter" {
+				// TODO: Return error in 1.11 (too risky for 1.10)
+				klog.Warningf("error loading br_netfilter module: %v", err)
+				continue
+			}
+			return err
+		}
 	}
-	// TODO: Add to /etc/modules-load.d/ ?
 	return nil
 }
 
@@ -701,6 +804,19 @@ func getNodeConfigFromServer(ctx context.Context, config *nodeup.ConfigServerOpt
 		client.CA = []byte(config.CA)
 	}
 
+	if config.ClientCertFile != "" {
+		clientCert, err := ioutil.ReadFile(config.ClientCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading configuration server client certificate %q: %w", config.ClientCertFile, err)
+		}
+		clientKey, err := ioutil.ReadFile(config.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading configuration server client key %q: %w", config.ClientKeyFile, err)
+		}
+		client.ClientCert = clientCert
+		client.ClientKey = clientKey
+	}
+
 	u, err := url.Parse(config.Server)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse configuration server url %q: %w", config.Server, err)
@@ -711,7 +827,75 @@ func getNodeConfigFromServer(ctx context.Context, config *nodeup.ConfigServerOpt
 		APIVersion:        nodeup.BootstrapAPIVersion,
 		IncludeNodeConfig: true,
 	}
-	return client.QueryBootstrap(ctx, &request)
+
+	timeout := defaultConfigServerTimeout
+	if config.Timeout != nil {
+		timeout = config.Timeout.Duration
+	}
+	return queryBootstrapWithRetry(ctx, client, &request, timeout, configServerBackoff)
+}
+
+// defaultConfigServerTimeout is how long we wait for kops-controller, if ConfigServerOptions doesn't say otherwise
+const defaultConfigServerTimeout = 10 * time.Minute
+
+// configServerBackoff is the delay between attempts to reach kops-controller
+var configServerBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    math.MaxInt32,
+	Cap:      30 * time.Second,
+}
+
+// bootstrapQuerier sends bootstrap requests to kops-controller
+type bootstrapQuerier interface {
+	QueryBootstrap(ctx context.Context, req *nodeup.BootstrapRequest) (*nodeup.BootstrapResponse, error)
+}
+
+// queryBootstrapWithRetry polls kops-controller until it answers the bootstrap request.
+// Transient errors are retried with backoff until the timeout passes; other errors are returned immediately.
+func queryBootstrapWithRetry(ctx context.Context, client bootstrapQuerier, request *nodeup.BootstrapRequest, timeout time.Duration, backoff wait.Backoff) (*nodeup.BootstrapResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		response, err := client.QueryBootstrap(ctx, request)
+		if err == nil {
+			if attempt > 1 {
+				klog.Infof("kops-controller responded after %d attempts", attempt)
+			}
+			return response, nil
+		}
+		if !isRetryableBootstrapError(err) {
+			return nil, fmt.Errorf("querying kops-controller: %w", err)
+		}
+
+		delay := backoff.Step()
+		klog.Warningf("kops-controller is not ready (attempt %d), retrying in %v: %v", attempt, delay.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("kops-controller did not respond within %v after %d attempts: %w", timeout, attempt, err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isRetryableBootstrapError returns true for errors that should go away once kops-controller is up:
+// DNS not being set up yet, network errors, and server-side failures.
+// Authentication failures and other rejected requests are not retried.
+func isRetryableBootstrapError(err error) bool {
+	var tryAgainLater *fi.TryAgainLaterError
+	if errors.As(err, &tryAgainLater) {
+		return true
+	}
+
+	var statusErr *nodetasks.BootstrapStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
 
 func getAWSConfigurationMode(c *model.NodeupModelContext) (string, error) {
@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	api "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/registry"
+	"k8s.io/kops/pkg/apis/nodeup"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+func TestReadConfig(t *testing.T) {
+	config := "clusterName: minimal.example.com\n"
+
+	dir, err := ioutil.TempDir("", "nodeup")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "node.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(config), 0644); err != nil {
+		t.Fatalf("error writing config: %v", err)
+	}
+
+	grid := []struct {
+		Description string
+		Command     *NodeUpCommand
+	}{
+		{
+			Description: "file",
+			Command:     &NodeUpCommand{ConfigLocation: configFile},
+		},
+		{
+			Description: "stdin",
+			Command:     &NodeUpCommand{ConfigLocation: "-", Stdin: strings.NewReader(config)},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			actual, err := g.Command.readConfig()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(actual) != config {
+				t.Errorf("unexpected config: expected %q, got %q", config, string(actual))
+			}
+		})
+	}
+}
+
+// newTestConfigBase returns a ConfigBase holding the cluster and auxiliary configs for the nodes instance group,
+// and the nodeup config which refers to them.
+func newTestConfigBase(t *testing.T) (vfs.Path, *nodeup.Config) {
+	vfs.Context.ResetMemfsContext(true)
+
+	configBase, err := vfs.Context.BuildVfsPath("memfs://tests/minimal.example.com")
+	if err != nil {
+		t.Fatalf("error building vfspath: %v", err)
+	}
+
+	cluster := "metadata:\n  name: minimal.example.com\nspec:\n  kubelet: {}\n  masterKubelet: {}\n"
+	if err := configBase.Join(registry.PathClusterCompleted).WriteFile(strings.NewReader(cluster), nil); err != nil {
+		t.Fatalf("error writing cluster config: %v", err)
+	}
+
+	auxConfig := "{}\n"
+	if err := configBase.Join("igconfig", "node", "nodes", "auxconfig.yaml").WriteFile(strings.NewReader(auxConfig), nil); err != nil {
+		t.Fatalf("error writing auxiliary config: %v", err)
+	}
+	auxConfigHash := sha256.Sum256([]byte(auxConfig))
+
+	config := &nodeup.Config{
+		InstanceGroupName: "nodes",
+		InstanceGroupRole: api.InstanceGroupRoleNode,
+		AuxConfigHash:     base64.StdEncoding.EncodeToString(auxConfigHash[:]),
+	}
+	return configBase, config
+}
+
+func TestLoadConfig(t *testing.T) {
+	configBase, config := newTestConfigBase(t)
+
+	c := &NodeUpCommand{config: config}
+	if err := c.loadConfig(nil, configBase); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if c.cluster == nil || c.cluster.Name != "minimal.example.com" {
+		t.Errorf("unexpected cluster config: %v", c.cluster)
+	}
+	if c.auxConfig == nil {
+		t.Errorf("expected auxiliary config to be loaded")
+	}
+
+	mismatched := *config
+	mismatched.AuxConfigHash = "mismatched"
+	c = &NodeUpCommand{config: &mismatched}
+	err := c.loadConfig(nil, configBase)
+	if err == nil || !strings.Contains(err.Error(), "auxiliary config hash mismatch") {
+		t.Errorf("expected auxiliary config hash mismatch error, got %v", err)
+	}
+}
+
+// fakeBootstrapQuerier returns each of errs in turn, then a response
+type fakeBootstrapQuerier struct {
+	errs  []error
+	calls int
+}
+
+func (f *fakeBootstrapQuerier) QueryBootstrap(ctx context.Context, req *nodeup.BootstrapRequest) (*nodeup.BootstrapResponse, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	return &nodeup.BootstrapResponse{NodeConfig: &nodeup.NodeConfig{}}, nil
+}
+
+func TestQueryBootstrapWithRetry(t *testing.T) {
+	connRefused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	grid := []struct {
+		Description   string
+		Errs          []error
+		Timeout       time.Duration
+		ExpectedCalls int
+		ExpectError   bool
+	}{
+		{
+			Description:   "success",
+			Timeout:       time.Minute,
+			ExpectedCalls: 1,
+		},
+		{
+			Description: "transient errors are retried",
+			Errs: []error{
+				fi.NewTryAgainLaterError("kops-controller DNS not setup yet"),
+				connRefused,
+				&nodetasks.BootstrapStatusError{StatusCode: http.StatusServiceUnavailable},
+			},
+			Timeout:       time.Minute,
+			ExpectedCalls: 4,
+		},
+		{
+			Description:   "authentication errors fail fast",
+			Errs:          []error{connRefused, &nodetasks.BootstrapStatusError{StatusCode: http.StatusForbidden}},
+			Timeout:       time.Minute,
+			ExpectedCalls: 2,
+			ExpectError:   true,
+		},
+		{
+			Description:   "other errors fail fast",
+			Errs:          []error{fmt.Errorf("parsing CA bundle: bad PEM")},
+			Timeout:       time.Minute,
+			ExpectedCalls: 1,
+			ExpectError:   true,
+		},
+		{
+			Description: "deadline passes",
+			Errs:        []error{connRefused, connRefused, connRefused, connRefused, connRefused, connRefused, connRefused, connRefused, connRefused, connRefused},
+			Timeout:     50 * time.Millisecond,
+			ExpectError: true,
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			client := &fakeBootstrapQuerier{errs: g.Errs}
+			backoff := wait.Backoff{Duration: 20 * time.Millisecond, Steps: 100}
+
+			response, err := queryBootstrapWithRetry(context.Background(), client, &nodeup.BootstrapRequest{}, g.Timeout, backoff)
+			if g.ExpectError {
+				if err == nil {
+					t.Errorf("expected error, got response %v", response)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if g.ExpectedCalls != 0 && client.calls != g.ExpectedCalls {
+				t.Errorf("expected %d calls, got %d", g.ExpectedCalls, client.calls)
+			}
+			if g.ExpectedCalls == 0 && client.calls >= len(g.Errs) {
+				t.Errorf("expected to give up before the errors ran out, got %d calls", client.calls)
+			}
+		})
+	}
+}
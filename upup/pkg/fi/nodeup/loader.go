@@ -19,6 +19,8 @@ package nodeup
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	"k8s.io/klog/v2"
 	"k8s.io/kops/upup/pkg/fi"
@@ -27,11 +29,63 @@ import (
 
 type Loader struct {
 	Builders []fi.ModelBuilder
+
+	// TaskSources records the name of the builder that produced each task, keyed by task key.
+	TaskSources map[string]string
+}
+
+// SkipBuilders removes the named builders from the Builders, so that they are not run.
+// Builders are named by their type name, for example "DockerBuilder"; an unknown name is an error.
+func (l *Loader) SkipBuilders(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	skip := make(map[string]bool)
+	for _, name := range names {
+		skip[name] = true
+	}
+
+	var known []string
+	var builders []fi.ModelBuilder
+	for _, builder := range l.Builders {
+		name := builderTypeName(builder)
+		known = append(known, name)
+		if skip[name] {
+			klog.Infof("skipping builder %s", name)
+			delete(skip, name)
+			continue
+		}
+		builders = append(builders, builder)
+	}
+
+	if len(skip) != 0 {
+		var unknown []string
+		for name := range skip {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		sort.Strings(known)
+		return fmt.Errorf("unknown builders to skip %s, valid builders are: %s", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+
+	l.Builders = builders
+	return nil
+}
+
+// builderTypeName returns the name of the builder's type, without its package.
+func builderTypeName(builder fi.ModelBuilder) string {
+	t := reflect.TypeOf(builder)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
 }
 
 // Build is responsible for running the build tasks for nodeup
 func (l *Loader) Build() (map[string]fi.Task, error) {
 	tasks := make(map[string]fi.Task)
+	l.TaskSources = make(map[string]string)
 	for _, builder := range l.Builders {
 		context := &fi.ModelBuilderContext{
 			Tasks: tasks,
@@ -41,6 +95,14 @@ func (l *Loader) Build() (map[string]fi.Task, error) {
 			return nil, fmt.Errorf("building %s: %v", reflect.TypeOf(builder), err)
 		}
 		tasks = context.Tasks
+
+		// Use the same name as SkipBuilders, so that a builder can be skipped by its name in the report
+		builderName := builderTypeName(builder)
+		for key := range tasks {
+			if _, found := l.TaskSources[key]; !found {
+				l.TaskSources[key] = builderName
+			}
+		}
 	}
 
 	// If there is a package task, we need an update packages task
@@ -48,6 +110,7 @@ func (l *Loader) Build() (map[string]fi.Task, error) {
 		if _, ok := t.(*nodetasks.Package); ok {
 			klog.Infof("Package task found; adding UpdatePackages task")
 			tasks["UpdatePackages"] = nodetasks.NewUpdatePackages()
+			l.TaskSources["UpdatePackages"] = "Loader"
 			break
 		}
 	}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeup
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/nodeup/nodetasks"
+)
+
+type fooBuilder struct{}
+
+func (b *fooBuilder) Build(c *fi.ModelBuilderContext) error {
+	c.AddTask(&nodetasks.File{Path: "/etc/foo", Type: nodetasks.FileType_File})
+	return nil
+}
+
+type barBuilder struct{}
+
+func (b *barBuilder) Build(c *fi.ModelBuilderContext) error {
+	return nil
+}
+
+func TestLoaderSkipBuilders(t *testing.T) {
+	grid := []struct {
+		Description string
+		Skip        []string
+		Expected    []string
+		ExpectError bool
+	}{
+		{
+			Description: "no skipped builders",
+			Expected:    []string{"fooBuilder", "barBuilder"},
+		},
+		{
+			Description: "skipped builder",
+			Skip:        []string{"fooBuilder"},
+			Expected:    []string{"barBuilder"},
+		},
+		{
+			Description: "all builders skipped",
+			Skip:        []string{"barBuilder", "fooBuilder"},
+		},
+		{
+			Description: "unknown builder",
+			Skip:        []string{"fooBuilder", "bazBuilder"},
+			ExpectError: true,
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			loader := &Loader{}
+			loader.Builders = append(loader.Builders, &fooBuilder{})
+			loader.Builders = append(loader.Builders, &barBuilder{})
+
+			err := loader.SkipBuilders(g.Skip)
+			if g.ExpectError {
+				if err == nil {
+					t.Fatalf("expected error skipping %v", g.Skip)
+				}
+				if len(loader.Builders) != 2 {
+					t.Errorf("expected builders to be unchanged on error, got %d builders", len(loader.Builders))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error skipping %v: %v", g.Skip, err)
+			}
+
+			var actual []string
+			for _, builder := range loader.Builders {
+				actual = append(actual, builderTypeName(builder))
+			}
+			if !reflect.DeepEqual(actual, g.Expected) {
+				t.Errorf("unexpected builders: expected %v, got %v", g.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestLoaderTaskSources(t *testing.T) {
+	loader := &Loader{}
+	loader.Builders = append(loader.Builders, &fooBuilder{})
+	loader.Builders = append(loader.Builders, &barBuilder{})
+
+	tasks, err := loader.Build()
+	if err != nil {
+		t.Fatalf("unexpected error building tasks: %v", err)
+	}
+
+	for key := range tasks {
+		name := loader.TaskSources[key]
+		if name != "fooBuilder" {
+			t.Errorf("unexpected builder for task %q: %q", key, name)
+		}
+
+		// The name in the report can be passed to SkipBuilders
+		skipped := &Loader{Builders: loader.Builders}
+		if err := skipped.SkipBuilders([]string{name}); err != nil {
+			t.Errorf("unexpected error skipping builder %q: %v", name, err)
+		}
+	}
+	if len(tasks) != 1 {
+		t.Errorf("expected 1 task, got %v", tasks)
+	}
+}
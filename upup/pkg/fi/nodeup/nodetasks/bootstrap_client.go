@@ -131,11 +131,27 @@ func (b *BootstrapClientTask) Run(c *fi.Context) error {
 	return nil
 }
 
+// BootstrapStatusError is returned when kops-controller answers a bootstrap request with an unexpected status code
+type BootstrapStatusError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Detail is the first line of the response body, if any
+	Detail string
+}
+
+func (e *BootstrapStatusError) Error() string {
+	return fmt.Sprintf("bootstrap returned status code %d: %s", e.StatusCode, e.Detail)
+}
+
 type KopsBootstrapClient struct {
 	// Authenticator generates authentication credentials for requests.
 	Authenticator fi.Authenticator
-	// CA is the CA certificate for kops-controller.
+	// CA is the CA certificate bundle for kops-controller; it may hold several concatenated PEM certificates, such as during CA rotation.
 	CA []byte
+	// ClientCert is the PEM-encoded client certificate to present to kops-controller, if any.
+	ClientCert []byte
+	// ClientKey is the PEM-encoded private key for ClientCert.
+	ClientKey []byte
 
 	// BaseURL is the base URL for the server
 	BaseURL url.URL
@@ -146,15 +162,33 @@ type KopsBootstrapClient struct {
 func (b *KopsBootstrapClient) QueryBootstrap(ctx context.Context, req *nodeup.BootstrapRequest) (*nodeup.BootstrapResponse, error) {
 	if b.httpClient == nil {
 		certPool := x509.NewCertPool()
-		certPool.AppendCertsFromPEM(b.CA)
+		if len(b.CA) != 0 {
+			caCerts, err := pki.ParsePEMCertificateBundle(b.CA)
+			if err != nil {
+				return nil, fmt.Errorf("parsing CA bundle: %v", err)
+			}
+			for _, caCert := range caCerts {
+				certPool.AddCert(caCert.Certificate)
+			}
+		}
+
+		tlsConfig := &tls.Config{
+			RootCAs:    certPool,
+			MinVersion: tls.VersionTLS12,
+		}
+
+		if len(b.ClientCert) != 0 || len(b.ClientKey) != 0 {
+			clientCert, err := tls.X509KeyPair(b.ClientCert, b.ClientKey)
+			if err != nil {
+				return nil, fmt.Errorf("loading client certificate: %v", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{clientCert}
+		}
 
 		b.httpClient = &http.Client{
 			Timeout: time.Duration(15) * time.Second,
 			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					RootCAs:    certPool,
-					MinVersion: tls.VersionTLS12,
-				},
+				TLSClientConfig: tlsConfig,
 			},
 		}
 	}
@@ -207,7 +241,7 @@ func (b *KopsBootstrapClient) QueryBootstrap(ctx context.Context, req *nodeup.Bo
 				detail = scanner.Text()
 			}
 		}
-		return nil, fmt.Errorf("bootstrap returned status code %d: %s", resp.StatusCode, detail)
+		return nil, &BootstrapStatusError{StatusCode: resp.StatusCode, Detail: detail}
 	}
 
 	var bootstrapResp nodeup.BootstrapResponse
@@ -17,7 +17,9 @@ limitations under the License.
 package nodetasks
 
 import (
+	"archive/tar"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -41,6 +43,15 @@ type LoadImageTask struct {
 	Runtime string
 }
 
+type imageArchiveFormatType string
+
+const (
+	// imageArchiveFormatDocker is an image archive as produced by "docker save"
+	imageArchiveFormatDocker imageArchiveFormatType = "docker"
+	// imageArchiveFormatOCI is an image archive containing only an OCI image layout
+	imageArchiveFormatOCI imageArchiveFormatType = "oci"
+)
+
 var _ fi.Task = &LoadImageTask{}
 var _ fi.HasDependencies = &LoadImageTask{}
 
@@ -144,15 +155,15 @@ func (_ *LoadImageTask) RenderLocal(t *local.LocalTarget, a, e, changes *LoadIma
 		tarFile = localFile
 	}
 
+	format, err := imageArchiveFormat(tarFile)
+	if err != nil {
+		return fmt.Errorf("error inspecting container image %s: %v", primaryURL, err)
+	}
+
 	// Load the container image
-	var args []string
-	switch runtime {
-	case "docker":
-		args = []string{"docker", "load", "-i", tarFile}
-	case "containerd":
-		args = []string{"ctr", "--namespace", "k8s.io", "images", "import", tarFile}
-	default:
-		return fmt.Errorf("unknown container runtime: %s", runtime)
+	args, err := loadImageArgs(runtime, format, tarFile)
+	if err != nil {
+		return fmt.Errorf("unable to load container image %s: %v", primaryURL, err)
 	}
 	human := strings.Join(args, " ")
 
@@ -166,6 +177,57 @@ func (_ *LoadImageTask) RenderLocal(t *local.LocalTarget, a, e, changes *LoadIma
 	return nil
 }
 
+// imageArchiveFormat inspects the container image tar file and returns its format.
+// Archives containing a docker manifest.json are loaded as docker archives, even if they
+// also contain an OCI layout, as produced by recent versions of "docker save".
+func imageArchiveFormat(tarFile string) (imageArchiveFormatType, error) {
+	f, err := os.Open(tarFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasDockerManifest := false
+	hasOCILayout := false
+	r := tar.NewReader(f)
+	for {
+		hdr, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading tar file %q: %v", tarFile, err)
+		}
+		switch path.Clean(hdr.Name) {
+		case "manifest.json":
+			hasDockerManifest = true
+		case "oci-layout":
+			hasOCILayout = true
+		}
+	}
+
+	if hasOCILayout && !hasDockerManifest {
+		return imageArchiveFormatOCI, nil
+	}
+	return imageArchiveFormatDocker, nil
+}
+
+// loadImageArgs returns the command used to load an image archive of the given format into the container runtime.
+func loadImageArgs(runtime string, format imageArchiveFormatType, tarFile string) ([]string, error) {
+	switch runtime {
+	case "docker":
+		if format == imageArchiveFormatOCI {
+			return nil, fmt.Errorf("docker cannot load OCI layout image archives, use the containerd runtime or a docker image archive")
+		}
+		return []string{"docker", "load", "-i", tarFile}, nil
+	case "containerd":
+		// ctr can import both docker and OCI layout image archives
+		return []string{"ctr", "--namespace", "k8s.io", "images", "import", tarFile}, nil
+	default:
+		return nil, fmt.Errorf("unknown container runtime: %s", runtime)
+	}
+}
+
 func (_ *LoadImageTask) RenderCloudInit(t *cloudinit.CloudInitTarget, a, e, changes *LoadImageTask) error {
 	return fmt.Errorf("LoadImageTask::RenderCloudInit not implemented")
 }
@@ -17,6 +17,11 @@ limitations under the License.
 package nodetasks
 
 import (
+	"archive/tar"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -39,3 +44,114 @@ func TestLoadImageTask_Deps(t *testing.T) {
 	}
 
 }
+
+func writeTestImageArchive(t *testing.T, dir string, name string, files ...string) string {
+	p := filepath.Join(dir, name)
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatalf("error creating %q: %v", p, err)
+	}
+	defer f.Close()
+
+	w := tar.NewWriter(f)
+	for _, file := range files {
+		contents := []byte("{}")
+		if err := w.WriteHeader(&tar.Header{Name: file, Mode: 0644, Size: int64(len(contents))}); err != nil {
+			t.Fatalf("error writing tar header: %v", err)
+		}
+		if _, err := w.Write(contents); err != nil {
+			t.Fatalf("error writing tar contents: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing tar file: %v", err)
+	}
+	return p
+}
+
+func TestLoadImageArchiveFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "loadimage")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	grid := []struct {
+		files    []string
+		expected imageArchiveFormatType
+	}{
+		{
+			files:    []string{"manifest.json", "repositories", "abc/layer.tar"},
+			expected: imageArchiveFormatDocker,
+		},
+		{
+			files:    []string{"oci-layout", "index.json", "blobs/sha256/abc"},
+			expected: imageArchiveFormatOCI,
+		},
+		{
+			files:    []string{"./oci-layout", "./index.json", "./blobs/sha256/abc"},
+			expected: imageArchiveFormatOCI,
+		},
+		{
+			files:    []string{"oci-layout", "index.json", "manifest.json", "blobs/sha256/abc"},
+			expected: imageArchiveFormatDocker,
+		},
+	}
+
+	for i, g := range grid {
+		tarFile := writeTestImageArchive(t, dir, fmt.Sprintf("image-%d.tar", i), g.files...)
+		actual, err := imageArchiveFormat(tarFile)
+		if err != nil {
+			t.Errorf("unexpected error inspecting %v: %v", g.files, err)
+			continue
+		}
+		if actual != g.expected {
+			t.Errorf("unexpected format for %v: expected %q, got %q", g.files, g.expected, actual)
+		}
+	}
+}
+
+func TestLoadImageArgs(t *testing.T) {
+	grid := []struct {
+		runtime  string
+		format   imageArchiveFormatType
+		expected []string
+	}{
+		{
+			runtime:  "docker",
+			format:   imageArchiveFormatDocker,
+			expected: []string{"docker", "load", "-i", "image.tar"},
+		},
+		{
+			runtime: "docker",
+			format:  imageArchiveFormatOCI,
+		},
+		{
+			runtime:  "containerd",
+			format:   imageArchiveFormatDocker,
+			expected: []string{"ctr", "--namespace", "k8s.io", "images", "import", "image.tar"},
+		},
+		{
+			runtime:  "containerd",
+			format:   imageArchiveFormatOCI,
+			expected: []string{"ctr", "--namespace", "k8s.io", "images", "import", "image.tar"},
+		},
+	}
+
+	for _, g := range grid {
+		actual, err := loadImageArgs(g.runtime, g.format, "image.tar")
+		if g.expected == nil {
+			if err == nil {
+				t.Errorf("expected error loading %s archive with %s, got %v", g.format, g.runtime, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error loading %s archive with %s: %v", g.format, g.runtime, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, g.expected) {
+			t.Errorf("unexpected args loading %s archive with %s: expected %v, got %v", g.format, g.runtime, g.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"errors"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/util/pkg/vfs"
+)
+
+// ErrReadOnlyCAStore is returned by the write methods of a ReadOnlyCAStore
+var ErrReadOnlyCAStore = errors.New("the CA store is read-only")
+
+// errSSHCredentialStoreNotSupported is returned when the wrapped CAStore is not also an SSHCredentialStore
+var errSSHCredentialStoreNotSupported = errors.New("the wrapped CA store does not store SSH credentials")
+
+// ReadOnlyCAStore wraps a CAStore, and the SSHCredentialStore if the CAStore also implements it,
+// delegating the read methods and rejecting all writes with ErrReadOnlyCAStore.
+// It is intended for controllers that should never write keys.
+type ReadOnlyCAStore struct {
+	store              CAStore
+	sshCredentialStore SSHCredentialStore
+}
+
+var _ CAStore = &ReadOnlyCAStore{}
+var _ SSHCredentialStore = &ReadOnlyCAStore{}
+
+// NewReadOnlyCAStore is the constructor for ReadOnlyCAStore
+func NewReadOnlyCAStore(store CAStore) *ReadOnlyCAStore {
+	c := &ReadOnlyCAStore{
+		store: store,
+	}
+	if sshCredentialStore, ok := store.(SSHCredentialStore); ok {
+		c.sshCredentialStore = sshCredentialStore
+	}
+
+	return c
+}
+
+// FindPrimaryKeypair implements pki.Keystore
+func (c *ReadOnlyCAStore) FindPrimaryKeypair(name string) (*pki.Certificate, *pki.PrivateKey, error) {
+	return c.store.FindPrimaryKeypair(name)
+}
+
+// FindKeyset implements Keystore
+func (c *ReadOnlyCAStore) FindKeyset(name string) (*Keyset, error) {
+	return c.store.FindKeyset(name)
+}
+
+// StoreKeyset implements Keystore, and always fails
+func (c *ReadOnlyCAStore) StoreKeyset(name string, keyset *Keyset) error {
+	return ErrReadOnlyCAStore
+}
+
+// StoreKeysets implements CAStore, and always fails
+func (c *ReadOnlyCAStore) StoreKeysets(keysets map[string]*Keyset) error {
+	return ErrReadOnlyCAStore
+}
+
+// MirrorTo implements Keystore, and always fails, as mirroring writes the keys
+func (c *ReadOnlyCAStore) MirrorTo(basedir vfs.Path) error {
+	return ErrReadOnlyCAStore
+}
+
+// FindCertificatePool implements CAStore
+func (c *ReadOnlyCAStore) FindCertificatePool(name string) (*CertificatePool, error) {
+	return c.store.FindCertificatePool(name)
+}
+
+// FindCertificateKeyset implements CAStore
+func (c *ReadOnlyCAStore) FindCertificateKeyset(name string) (*kops.Keyset, error) {
+	return c.store.FindCertificateKeyset(name)
+}
+
+// FindPrivateKey implements CAStore
+func (c *ReadOnlyCAStore) FindPrivateKey(name string) (*pki.PrivateKey, error) {
+	return c.store.FindPrivateKey(name)
+}
+
+// FindPrivateKeyset implements CAStore
+func (c *ReadOnlyCAStore) FindPrivateKeyset(name string) (*kops.Keyset, error) {
+	return c.store.FindPrivateKeyset(name)
+}
+
+// FindCert implements CAStore
+func (c *ReadOnlyCAStore) FindCert(name string) (*pki.Certificate, error) {
+	return c.store.FindCert(name)
+}
+
+// ListKeysets implements CAStore
+func (c *ReadOnlyCAStore) ListKeysets() ([]*kops.Keyset, error) {
+	return c.store.ListKeysets()
+}
+
+// ListKeysetsByType implements CAStore
+func (c *ReadOnlyCAStore) ListKeysetsByType(t kops.KeysetType) ([]*kops.Keyset, error) {
+	return c.store.ListKeysetsByType(t)
+}
+
+// DeleteKeysetItem implements CAStore, and always fails
+func (c *ReadOnlyCAStore) DeleteKeysetItem(item *kops.Keyset, id string) error {
+	return ErrReadOnlyCAStore
+}
+
+// PromoteToPrimary implements CAStore, and always fails
+func (c *ReadOnlyCAStore) PromoteToPrimary(name string, id string) error {
+	return ErrReadOnlyCAStore
+}
+
+// ExportClientBundle implements CAStore
+func (c *ReadOnlyCAStore) ExportClientBundle(caName, clientName string) ([]byte, []byte, []byte, error) {
+	return c.store.ExportClientBundle(caName, clientName)
+}
+
+// DeleteSSHCredential implements SSHCredentialStore, and always fails
+func (c *ReadOnlyCAStore) DeleteSSHCredential(item *kops.SSHCredential) error {
+	return ErrReadOnlyCAStore
+}
+
+// DeleteSSHCredentialByFingerprint implements SSHCredentialStore, and always fails
+func (c *ReadOnlyCAStore) DeleteSSHCredentialByFingerprint(name string, fingerprint string) error {
+	return ErrReadOnlyCAStore
+}
+
+// AddSSHPublicKey implements SSHCredentialStore, and always fails
+func (c *ReadOnlyCAStore) AddSSHPublicKey(name string, data []byte) error {
+	return ErrReadOnlyCAStore
+}
+
+// ListSSHCredentials implements SSHCredentialStore
+func (c *ReadOnlyCAStore) ListSSHCredentials() ([]*kops.SSHCredential, error) {
+	if c.sshCredentialStore == nil {
+		return nil, errSSHCredentialStoreNotSupported
+	}
+	return c.sshCredentialStore.ListSSHCredentials()
+}
+
+// FindSSHPublicKeys implements SSHCredentialStore
+func (c *ReadOnlyCAStore) FindSSHPublicKeys(name string) ([]*kops.SSHCredential, error) {
+	if c.sshCredentialStore == nil {
+		return nil, errSSHCredentialStoreNotSupported
+	}
+	return c.sshCredentialStore.FindSSHPublicKeys(name)
+}
+
+// FindSSHPublicKeyByFingerprint implements SSHCredentialStore
+func (c *ReadOnlyCAStore) FindSSHPublicKeyByFingerprint(name string, fingerprint string) (*kops.SSHCredential, error) {
+	if c.sshCredentialStore == nil {
+		return nil, errSSHCredentialStoreNotSupported
+	}
+	return c.sshCredentialStore.FindSSHPublicKeyByFingerprint(name, fingerprint)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/clientset_generated/clientset/fake"
+	"k8s.io/kops/pkg/sshcredentials"
+)
+
+func TestReadOnlyCAStore(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	store := NewClientsetCAStore(&kops.Cluster{}, clientset.Kops(), "default").(*ClientsetCAStore)
+	if err := store.AddKeysetItem("ca", newTestKeysetItem("1")); err != nil {
+		t.Fatalf("unexpected error adding item: %v", err)
+	}
+
+	pubkey := "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test"
+	if err := store.AddSSHPublicKey("admin", []byte(pubkey)); err != nil {
+		t.Fatalf("unexpected error adding SSH public key: %v", err)
+	}
+
+	fingerprint, err := sshcredentials.Fingerprint(pubkey)
+	if err != nil {
+		t.Fatalf("error fingerprinting SSH public key: %v", err)
+	}
+
+	readOnly := NewReadOnlyCAStore(store)
+
+	keysets, err := readOnly.ListKeysets()
+	if err != nil {
+		t.Fatalf("unexpected error listing keysets: %v", err)
+	}
+	if len(keysets) != 1 || keysets[0].Name != "ca" {
+		t.Errorf("unexpected keysets: %v", keysets)
+	}
+	sshCredentials, err := readOnly.FindSSHPublicKeys("admin")
+	if err != nil {
+		t.Fatalf("unexpected error finding SSH public keys: %v", err)
+	}
+	if len(sshCredentials) != 1 {
+		t.Errorf("expected one SSH public key, got %v", sshCredentials)
+	}
+
+	writes := map[string]func() error{
+		"StoreKeyset": func() error {
+			return readOnly.StoreKeyset("ca", &Keyset{Items: map[string]*KeysetItem{"2": newTestKeysetItem("2")}, Primary: newTestKeysetItem("2")})
+		},
+		"StoreKeysets": func() error {
+			return readOnly.StoreKeysets(map[string]*Keyset{"ca": {Items: map[string]*KeysetItem{"2": newTestKeysetItem("2")}, Primary: newTestKeysetItem("2")}})
+		},
+		"MirrorTo": func() error {
+			return readOnly.MirrorTo(nil)
+		},
+		"DeleteKeysetItem": func() error {
+			return readOnly.DeleteKeysetItem(keysets[0], "1")
+		},
+		"PromoteToPrimary": func() error {
+			return readOnly.PromoteToPrimary("ca", "1")
+		},
+		"AddSSHPublicKey": func() error {
+			return readOnly.AddSSHPublicKey("admin", []byte(pubkey))
+		},
+		"DeleteSSHCredential": func() error {
+			return readOnly.DeleteSSHCredential(sshCredentials[0])
+		},
+		"DeleteSSHCredentialByFingerprint": func() error {
+			return readOnly.DeleteSSHCredentialByFingerprint("admin", fingerprint)
+		},
+	}
+	for name, write := range writes {
+		if err := write(); err != ErrReadOnlyCAStore {
+			t.Errorf("expected %s to be rejected, got error %v", name, err)
+		}
+	}
+
+	ids, primaryId := keysetItemIds(t, clientset, "ca")
+	if len(ids) != 1 || ids[0] != "1" || primaryId != "1" {
+		t.Errorf("keyset was modified: items %v, primary %q", ids, primaryId)
+	}
+	sshCredentials, err = store.FindSSHPublicKeys("admin")
+	if err != nil {
+		t.Fatalf("unexpected error finding SSH public keys: %v", err)
+	}
+	if len(sshCredentials) != 1 {
+		t.Errorf("SSH public keys were modified: %v", sshCredentials)
+	}
+}
+
+func TestReadOnlyCAStoreWithoutSSHCredentialStore(t *testing.T) {
+	readOnly := NewReadOnlyCAStore(&readOnlyTestCAStore{})
+
+	if _, err := readOnly.ListSSHCredentials(); err == nil {
+		t.Errorf("expected an error listing SSH credentials of a CA store which does not store them")
+	}
+	if err := readOnly.AddSSHPublicKey("admin", nil); err != ErrReadOnlyCAStore {
+		t.Errorf("expected AddSSHPublicKey to be rejected, got error %v", err)
+	}
+}
+
+// readOnlyTestCAStore is a CAStore which does not implement SSHCredentialStore
+type readOnlyTestCAStore struct {
+	CAStore
+}
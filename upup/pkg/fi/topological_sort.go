@@ -17,8 +17,11 @@ limitations under the License.
 package fi
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
 
 	"k8s.io/klog/v2"
 	"k8s.io/kops/util/pkg/reflectutils"
@@ -76,6 +79,42 @@ func FindTaskDependencies(tasks map[string]Task) map[string][]string {
 	return edges
 }
 
+// TaskGraphNode describes a task and its dependencies, for export of the task graph.
+type TaskGraphNode struct {
+	// Name is the key of the task in the task map
+	Name string `json:"name"`
+	// Type is the go type of the task
+	Type string `json:"type"`
+	// Dependencies are the keys of the tasks this task depends on
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// WriteTaskGraph writes the tasks and their dependencies to w as JSON, sorted by task name.
+func WriteTaskGraph(w io.Writer, tasks map[string]Task) error {
+	edges := FindTaskDependencies(tasks)
+
+	nodes := []TaskGraphNode{}
+	for k, t := range tasks {
+		dependencies := append([]string(nil), edges[k]...)
+		sort.Strings(dependencies)
+		nodes = append(nodes, TaskGraphNode{
+			Name:         k,
+			Type:         reflect.Indirect(reflect.ValueOf(t)).Type().String(),
+			Dependencies: dependencies,
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(nodes); err != nil {
+		return fmt.Errorf("error writing task graph: %v", err)
+	}
+	return nil
+}
+
 func reflectForDependencies(tasks map[string]Task, task Task) []Task {
 	v := reflect.ValueOf(task).Elem()
 	return getDependencies(tasks, v)
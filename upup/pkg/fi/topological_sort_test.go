@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// dependentTask is a trivial task which depends on the tasks it references.
+type dependentTask struct {
+	Name      string
+	DependsOn []*dependentTask
+}
+
+var _ Task = &dependentTask{}
+
+func (t *dependentTask) Run(c *Context) error {
+	return nil
+}
+
+func TestWriteTaskGraph(t *testing.T) {
+	vpc := &dependentTask{Name: "vpc"}
+	subnet := &dependentTask{Name: "subnet", DependsOn: []*dependentTask{vpc}}
+	instance := &dependentTask{Name: "instance", DependsOn: []*dependentTask{vpc, subnet}}
+	tasks := map[string]Task{
+		"vpc":      vpc,
+		"subnet":   subnet,
+		"instance": instance,
+	}
+
+	var b bytes.Buffer
+	if err := WriteTaskGraph(&b, tasks); err != nil {
+		t.Fatalf("unexpected error writing task graph: %v", err)
+	}
+
+	var actual []TaskGraphNode
+	if err := json.Unmarshal(b.Bytes(), &actual); err != nil {
+		t.Fatalf("error parsing task graph %q: %v", b.String(), err)
+	}
+
+	expected := []TaskGraphNode{
+		{Name: "instance", Type: "fi.dependentTask", Dependencies: []string{"subnet", "vpc"}},
+		{Name: "subnet", Type: "fi.dependentTask", Dependencies: []string{"vpc"}},
+		{Name: "vpc", Type: "fi.dependentTask"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected task graph: expected %+v, got %+v", expected, actual)
+	}
+}
@@ -40,6 +40,9 @@ type VFSCAStore struct {
 	basedir vfs.Path
 	cluster *kops.Cluster
 
+	// aclOverride, if set, is consulted for the ACL of each file written, before falling back to acls.GetACL
+	aclOverride vfs.ACLOracle
+
 	mutex    sync.Mutex
 	cachedCA *Keyset
 }
@@ -47,24 +50,49 @@ type VFSCAStore struct {
 var _ CAStore = &VFSCAStore{}
 var _ SSHCredentialStore = &VFSCAStore{}
 
-func NewVFSCAStore(cluster *kops.Cluster, basedir vfs.Path) *VFSCAStore {
+// VFSCAStoreOption configures optional behaviour of a VFSCAStore.
+type VFSCAStoreOption func(c *VFSCAStore)
+
+// WithACLOverride makes the VFSCAStore write files with the ACL returned by aclOverride,
+// for example to grant bucket-owner-full-control in a bucket shared between accounts.
+// If aclOverride returns a nil ACL, the ACL is computed by acls.GetACL as usual.
+func WithACLOverride(aclOverride vfs.ACLOracle) VFSCAStoreOption {
+	return func(c *VFSCAStore) {
+		c.aclOverride = aclOverride
+	}
+}
+
+func NewVFSCAStore(cluster *kops.Cluster, basedir vfs.Path, options ...VFSCAStoreOption) *VFSCAStore {
 	c := &VFSCAStore{
 		basedir: basedir,
 		cluster: cluster,
 	}
 
+	for _, option := range options {
+		option(c)
+	}
+
 	return c
 }
 
 // NewVFSSSHCredentialStore creates a SSHCredentialStore backed by VFS
-func NewVFSSSHCredentialStore(cluster *kops.Cluster, basedir vfs.Path) SSHCredentialStore {
+func NewVFSSSHCredentialStore(cluster *kops.Cluster, basedir vfs.Path, options ...VFSCAStoreOption) SSHCredentialStore {
 	// Note currently identical to NewVFSCAStore
-	c := &VFSCAStore{
-		basedir: basedir,
-		cluster: cluster,
-	}
+	return NewVFSCAStore(cluster, basedir, options...)
+}
 
-	return c
+// getACL returns the ACL for writing the file at p, preferring aclOverride if it is set.
+func getACL(p vfs.Path, cluster *kops.Cluster, aclOverride vfs.ACLOracle) (vfs.ACL, error) {
+	if aclOverride != nil {
+		acl, err := aclOverride(p)
+		if err != nil {
+			return nil, fmt.Errorf("error from acl override for %q: %v", p, err)
+		}
+		if acl != nil {
+			return acl, nil
+		}
+	}
+	return acls.GetACL(p, cluster)
 }
 
 func (c *VFSCAStore) VFSPath() vfs.Path {
@@ -192,7 +220,7 @@ func (c *VFSCAStore) writeKeysetBundle(p vfs.Path, name string, keyset *Keyset,
 		return err
 	}
 
-	acl, err := acls.GetACL(p, c.cluster)
+	acl, err := getACL(p, c.cluster, c.aclOverride)
 	if err != nil {
 		return err
 	}
@@ -388,6 +416,52 @@ func (c *VFSCAStore) ListKeysets() ([]*kops.Keyset, error) {
 	return items, nil
 }
 
+// ListKeysetsByType implements CAStore::ListKeysetsByType
+// The type of a keyset is not recorded in the directory structure, so this reads the keyset.yaml of every keyset:
+// it costs one read per keyset, in addition to the listing done by ListKeysets.
+func (c *VFSCAStore) ListKeysetsByType(t kops.KeysetType) ([]*kops.Keyset, error) {
+	keysets, err := c.ListKeysets()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*kops.Keyset
+	for _, keyset := range keysets {
+		keysetType, err := c.loadKeysetType(keyset.Name)
+		if err != nil {
+			return nil, err
+		}
+		if keysetType == t {
+			keyset.Spec.Type = keysetType
+			items = append(items, keyset)
+		}
+	}
+	return items, nil
+}
+
+// loadKeysetType returns the type of the named keyset, as recorded in its keyset.yaml
+func (c *VFSCAStore) loadKeysetType(name string) (kops.KeysetType, error) {
+	p := c.buildCertificatePoolPath(name).Join("keyset.yaml")
+	data, err := p.ReadFile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Keysets which predate keyset.yaml only hold keypairs
+			return kops.SecretTypeKeypair, nil
+		}
+		return "", fmt.Errorf("unable to read bundle %q: %v", p, err)
+	}
+
+	o, _, err := c.parseKeysetYaml(data)
+	if err != nil {
+		return "", fmt.Errorf("error parsing bundle %q: %v", p, err)
+	}
+
+	if o.Spec.Type == "" {
+		return kops.SecretTypeKeypair, nil
+	}
+	return o.Spec.Type, nil
+}
+
 // ListSSHCredentials implements SSHCredentialStore::ListSSHCredentials
 func (c *VFSCAStore) ListSSHCredentials() ([]*kops.SSHCredential, error) {
 	var items []*kops.SSHCredential
@@ -440,7 +514,7 @@ func (c *VFSCAStore) MirrorTo(basedir vfs.Path) error {
 	}
 
 	for _, keyset := range keysets {
-		if err := mirrorKeyset(c.cluster, basedir, keyset); err != nil {
+		if err := mirrorKeyset(c.cluster, c.aclOverride, basedir, keyset); err != nil {
 			return err
 		}
 	}
@@ -451,7 +525,7 @@ func (c *VFSCAStore) MirrorTo(basedir vfs.Path) error {
 	}
 
 	for _, sshCredential := range sshCredentials {
-		if err := mirrorSSHCredential(c.cluster, basedir, sshCredential); err != nil {
+		if err := mirrorSSHCredential(c.cluster, c.aclOverride, basedir, sshCredential); err != nil {
 			return err
 		}
 	}
@@ -460,7 +534,7 @@ func (c *VFSCAStore) MirrorTo(basedir vfs.Path) error {
 }
 
 // mirrorKeyset writes Keyset bundles for the certificates & privatekeys.
-func mirrorKeyset(cluster *kops.Cluster, basedir vfs.Path, keyset *kops.Keyset) error {
+func mirrorKeyset(cluster *kops.Cluster, aclOverride vfs.ACLOracle, basedir vfs.Path, keyset *kops.Keyset) error {
 	primary := FindPrimary(keyset)
 	if primary == nil {
 		return fmt.Errorf("found keyset with no primary data: %s", keyset.Name)
@@ -474,7 +548,7 @@ func mirrorKeyset(cluster *kops.Cluster, basedir vfs.Path, keyset *kops.Keyset)
 				return err
 			}
 			p := basedir.Join("issued", keyset.Name, "keyset.yaml")
-			acl, err := acls.GetACL(p, cluster)
+			acl, err := getACL(p, cluster, aclOverride)
 			if err != nil {
 				return err
 			}
@@ -491,7 +565,7 @@ func mirrorKeyset(cluster *kops.Cluster, basedir vfs.Path, keyset *kops.Keyset)
 				return err
 			}
 			p := basedir.Join("private", keyset.Name, "keyset.yaml")
-			acl, err := acls.GetACL(p, cluster)
+			acl, err := getACL(p, cluster, aclOverride)
 			if err != nil {
 				return err
 			}
@@ -510,14 +584,14 @@ func mirrorKeyset(cluster *kops.Cluster, basedir vfs.Path, keyset *kops.Keyset)
 }
 
 // mirrorSSHCredential writes the SSH credential file to the mirror location
-func mirrorSSHCredential(cluster *kops.Cluster, basedir vfs.Path, sshCredential *kops.SSHCredential) error {
+func mirrorSSHCredential(cluster *kops.Cluster, aclOverride vfs.ACLOracle, basedir vfs.Path, sshCredential *kops.SSHCredential) error {
 	id, err := sshcredentials.Fingerprint(sshCredential.Spec.PublicKey)
 	if err != nil {
 		return fmt.Errorf("error fingerprinting SSH public key %q: %v", sshCredential.Name, err)
 	}
 
 	p := basedir.Join("ssh", "public", sshCredential.Name, id)
-	acl, err := acls.GetACL(p, cluster)
+	acl, err := getACL(p, cluster, aclOverride)
 	if err != nil {
 		return err
 	}
@@ -548,6 +622,12 @@ func (c *VFSCAStore) StoreKeyset(name string, keyset *Keyset) error {
 	return nil
 }
 
+// StoreKeysets implements CAStore::StoreKeysets
+// The bundles of different Keysets are written concurrently.
+func (c *VFSCAStore) StoreKeysets(keysets map[string]*Keyset) error {
+	return storeKeysets(c, keysets)
+}
+
 func (c *VFSCAStore) findPrivateKeyset(id string) (*Keyset, error) {
 	var keys *Keyset
 	var err error
@@ -685,7 +765,7 @@ func (c *VFSCAStore) AddSSHPublicKey(name string, pubkey []byte) error {
 
 	p := c.buildSSHPublicKeyPath(name, id)
 
-	acl, err := acls.GetACL(p, c.cluster)
+	acl, err := getACL(p, c.cluster, c.aclOverride)
 	if err != nil {
 		return err
 	}
@@ -695,7 +775,7 @@ func (c *VFSCAStore) AddSSHPublicKey(name string, pubkey []byte) error {
 
 func (c *VFSCAStore) buildSSHPublicKeyPath(name string, id string) vfs.Path {
 	// id is fingerprint with colons, but we store without colons
-	id = strings.Replace(id, ":", "", -1)
+	id = sshcredentials.NormalizeFingerprint(id)
 	return c.basedir.Join("ssh", "public", name, id)
 }
 
@@ -731,6 +811,21 @@ func (c *VFSCAStore) FindSSHPublicKeys(name string) ([]*kops.SSHCredential, erro
 	return items, nil
 }
 
+// FindSSHPublicKeyByFingerprint implements SSHCredentialStore::FindSSHPublicKeyByFingerprint
+func (c *VFSCAStore) FindSSHPublicKeyByFingerprint(name string, fingerprint string) (*kops.SSHCredential, error) {
+	return findSSHPublicKeyByFingerprint(c, name, fingerprint)
+}
+
+// ExportClientBundle implements CAStore::ExportClientBundle
+func (c *VFSCAStore) ExportClientBundle(caName, clientName string) ([]byte, []byte, []byte, error) {
+	return exportClientBundle(c, caName, clientName)
+}
+
+// PromoteToPrimary implements CAStore::PromoteToPrimary
+func (c *VFSCAStore) PromoteToPrimary(name string, id string) error {
+	return promoteToPrimary(c, name, id)
+}
+
 // DeleteKeysetItem implements CAStore::DeleteKeysetItem
 func (c *VFSCAStore) DeleteKeysetItem(item *kops.Keyset, id string) error {
 	switch item.Spec.Type {
@@ -772,3 +867,26 @@ func (c *VFSCAStore) DeleteSSHCredential(item *kops.SSHCredential) error {
 	p := c.buildSSHPublicKeyPath(item.Name, id)
 	return p.Remove()
 }
+
+// DeleteSSHCredentialByFingerprint implements SSHCredentialStore::DeleteSSHCredentialByFingerprint
+func (c *VFSCAStore) DeleteSSHCredentialByFingerprint(name string, fingerprint string) error {
+	id := sshcredentials.NormalizeFingerprint(fingerprint)
+	if id == "" {
+		return fmt.Errorf("must specify fingerprint to delete SSHCredential")
+	}
+
+	p := c.buildSSHPublicKeyPath(name, id)
+
+	// Not every backend reports removing a missing file as os.ErrNotExist (GCS does not), so check first
+	if _, err := p.ReadFile(); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading SSH public key %q: %v", p, err)
+	}
+
+	if err := p.Remove(); err != nil {
+		return fmt.Errorf("error deleting SSH public key %q: %v", p, err)
+	}
+	return nil
+}
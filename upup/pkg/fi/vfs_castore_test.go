@@ -17,14 +17,20 @@ limitations under the License.
 package fi
 
 import (
+	"fmt"
+	"io"
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/pki"
+	"k8s.io/kops/pkg/sshcredentials"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
@@ -364,3 +370,290 @@ spec:
 		}
 	}
 }
+
+// aclRecordingPath is a vfs.Path which records the ACLs with which files are written.
+type aclRecordingPath struct {
+	*vfs.MemFSPath
+	written map[string]vfs.ACL
+}
+
+func (p *aclRecordingPath) Join(relativePath ...string) vfs.Path {
+	return &aclRecordingPath{MemFSPath: p.MemFSPath.Join(relativePath...).(*vfs.MemFSPath), written: p.written}
+}
+
+func (p *aclRecordingPath) WriteFile(data io.ReadSeeker, acl vfs.ACL) error {
+	p.written[p.Path()] = acl
+	return p.MemFSPath.WriteFile(data, acl)
+}
+
+func TestVFSCAStoreACLOverride(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building vfspath: %v", err)
+	}
+
+	pubkey := "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test"
+	overrideACL := &vfs.S3Acl{RequestACL: String("bucket-owner-full-control")}
+
+	grid := []struct {
+		Description string
+		Override    vfs.ACLOracle
+		ExpectedACL vfs.ACL
+	}{
+		{
+			Description: "no override",
+		},
+		{
+			Description: "override",
+			Override: func(p vfs.Path) (vfs.ACL, error) {
+				return overrideACL, nil
+			},
+			ExpectedACL: overrideACL,
+		},
+		{
+			Description: "override falls back",
+			Override: func(p vfs.Path) (vfs.ACL, error) {
+				return nil, nil
+			},
+		},
+	}
+
+	for _, g := range grid {
+		t.Run(g.Description, func(t *testing.T) {
+			written := make(map[string]vfs.ACL)
+			basedir := &aclRecordingPath{MemFSPath: basePath.(*vfs.MemFSPath), written: written}
+
+			var options []VFSCAStoreOption
+			if g.Override != nil {
+				options = append(options, WithACLOverride(g.Override))
+			}
+			s := NewVFSCAStore(&kops.Cluster{}, basedir, options...)
+
+			if err := s.AddSSHPublicKey("admin", []byte(pubkey)); err != nil {
+				t.Fatalf("error adding SSH public key: %v", err)
+			}
+
+			sshCredential := &kops.SSHCredential{}
+			sshCredential.Name = "admin"
+			sshCredential.Spec.PublicKey = pubkey
+			if err := mirrorSSHCredential(s.cluster, s.aclOverride, basedir.Join("mirror"), sshCredential); err != nil {
+				t.Fatalf("error mirroring SSH credential: %v", err)
+			}
+
+			if len(written) != 2 {
+				t.Fatalf("expected 2 files to be written, got %v", written)
+			}
+			for p, acl := range written {
+				if acl != g.ExpectedACL {
+					t.Errorf("unexpected ACL writing %q: expected %v, got %v", p, g.ExpectedACL, acl)
+				}
+			}
+		})
+	}
+}
+
+func TestVFSCAStoreACLOverrideError(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building vfspath: %v", err)
+	}
+
+	s := NewVFSCAStore(&kops.Cluster{}, basePath, WithACLOverride(func(p vfs.Path) (vfs.ACL, error) {
+		return nil, fmt.Errorf("no ACL for %s", p)
+	}))
+
+	pubkey := "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test"
+	if err := s.AddSSHPublicKey("admin", []byte(pubkey)); err == nil {
+		t.Errorf("expected error from ACL override")
+	}
+}
+
+func TestVFSCAStoreListKeysetsByType(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building vfspath: %v", err)
+	}
+	s := NewVFSCAStore(&kops.Cluster{}, basePath)
+
+	keysetYaml := func(name string, keysetType kops.KeysetType) string {
+		return "apiVersion: kops.k8s.io/v1alpha2\nkind: Keyset\nmetadata:\n  name: " + name + "\nspec:\n  type: " + string(keysetType) + "\n"
+	}
+	for p, data := range map[string]string{
+		"issued/ca/keyset.yaml":        keysetYaml("ca", kops.SecretTypeKeypair),
+		"issued/kubelet/keyset.yaml":   keysetYaml("kubelet", ""),
+		"issued/admin/keyset.yaml":     keysetYaml("admin", kops.SecretTypeSecret),
+		"issued/legacy/1234567890.crt": "",
+	} {
+		if err := basePath.Join(p).WriteFile(strings.NewReader(data), nil); err != nil {
+			t.Fatalf("error writing %q: %v", p, err)
+		}
+	}
+
+	for keysetType, expected := range map[kops.KeysetType][]string{
+		// Keysets with no type, or with no keyset.yaml, are keypairs
+		kops.SecretTypeKeypair: {"ca", "kubelet", "legacy"},
+		kops.SecretTypeSecret:  {"admin"},
+		"unknown":              nil,
+	} {
+		keysets, err := s.ListKeysetsByType(keysetType)
+		if err != nil {
+			t.Fatalf("unexpected error listing %s keysets: %v", keysetType, err)
+		}
+
+		var names []string
+		for _, keyset := range keysets {
+			names = append(names, keyset.Name)
+			if keyset.Spec.Type != keysetType {
+				t.Errorf("unexpected type %q for keyset %q, expected %q", keyset.Spec.Type, keyset.Name, keysetType)
+			}
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("unexpected %s keysets: expected %v, got %v", keysetType, expected, names)
+		}
+	}
+}
+
+func TestVFSCAStoreFindSSHPublicKeyByFingerprint(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building vfspath: %v", err)
+	}
+	s := NewVFSCAStore(&kops.Cluster{}, basePath)
+
+	pubkeys := []string{
+		"ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test",
+		"ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQDF2sghZsClUBXJB4mBMIw8rb0hJWjg1Vz4eUeXwYmTdi92Gf1zNc5xISSip9Y+PWX/jJokPB7tgPnMD/2JOAKhG1bi4ZqB15pYRmbbBekVpM4o4E0dx+czbqjiAm6wlccTrINK5LYenbucAAQt19eH+D0gJwzYUK9SYz1hWnlGS+qurt2bz7rrsG73lN8E2eiNvGtIXqv3GabW/Hea3acOBgCUJQWUDTRu0OmmwxzKbFN/UpNKeRaHlCqwZWjVAsmqA8TX8LIocq7Np7MmIBwt7EpEeZJxThcmC8DEJs9ClAjD+jlLIvMPXKC3JWCPgwCLGxHjy7ckSGFCSzbyPduh",
+	}
+	for _, pubkey := range pubkeys {
+		if err := s.AddSSHPublicKey("admin", []byte(pubkey)); err != nil {
+			t.Fatalf("error adding SSH public key: %v", err)
+		}
+	}
+
+	for _, pubkey := range pubkeys {
+		fingerprint, err := sshcredentials.Fingerprint(pubkey)
+		if err != nil {
+			t.Fatalf("error fingerprinting SSH public key: %v", err)
+		}
+
+		// Fingerprints are accepted with or without colons
+		for _, id := range []string{fingerprint, strings.Replace(fingerprint, ":", "", -1)} {
+			sshCredential, err := s.FindSSHPublicKeyByFingerprint("admin", id)
+			if err != nil {
+				t.Fatalf("unexpected error finding SSH public key %q: %v", id, err)
+			}
+			if sshCredential == nil {
+				t.Fatalf("SSH public key %q not found", id)
+			}
+			if sshCredential.Name != "admin" || sshCredential.Spec.PublicKey != pubkey {
+				t.Errorf("unexpected SSH public key for %q: %v", id, sshCredential)
+			}
+		}
+	}
+
+	for _, g := range []struct {
+		name        string
+		fingerprint string
+	}{
+		{name: "admin", fingerprint: "00:11:22:33:44:55:66:77:88:99:aa:bb:cc:dd:ee:ff"},
+		{name: "other", fingerprint: "af:7c:5b:9c:cd:24:69:c9:b8:4e:1c:ce:0c:8b:05:7b"},
+	} {
+		sshCredential, err := s.FindSSHPublicKeyByFingerprint(g.name, g.fingerprint)
+		if err != nil {
+			t.Fatalf("unexpected error finding SSH public key %s/%s: %v", g.name, g.fingerprint, err)
+		}
+		if sshCredential != nil {
+			t.Errorf("unexpected SSH public key found for %s/%s: %v", g.name, g.fingerprint, sshCredential)
+		}
+	}
+}
+
+func TestVFSCAStoreSSHPublicKeyDedup(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	basePath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building vfspath: %v", err)
+	}
+	s := NewVFSCAStore(&kops.Cluster{}, basePath)
+
+	pubkey := "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x"
+	for _, comment := range []string{"justin@test", "justin@other"} {
+		if err := s.AddSSHPublicKey("admin", []byte(pubkey+" "+comment)); err != nil {
+			t.Fatalf("error adding SSH public key: %v", err)
+		}
+	}
+
+	sshCredentials, err := s.FindSSHPublicKeys("admin")
+	if err != nil {
+		t.Fatalf("unexpected error finding SSH public keys: %v", err)
+	}
+	if len(sshCredentials) != 1 {
+		t.Errorf("expected a single SSH public key, got %d: %v", len(sshCredentials), sshCredentials)
+	}
+}
+
+// strictRemovePath is a memfs path whose Remove fails for missing files with a plain error, as GCS does
+type strictRemovePath struct {
+	*vfs.MemFSPath
+}
+
+func (p strictRemovePath) Join(relativePath ...string) vfs.Path {
+	return strictRemovePath{p.MemFSPath.Join(relativePath...).(*vfs.MemFSPath)}
+}
+
+func (p strictRemovePath) Remove() error {
+	if _, err := p.MemFSPath.ReadFile(); err != nil {
+		return fmt.Errorf("error deleting %s: googleapi: Error 404: No such object", p)
+	}
+	return p.MemFSPath.Remove()
+}
+
+func TestVFSCAStoreDeleteSSHCredentialByFingerprint(t *testing.T) {
+	vfs.Context.ResetMemfsContext(true)
+
+	memfsPath, err := vfs.Context.BuildVfsPath("memfs://tests")
+	if err != nil {
+		t.Fatalf("error building vfspath: %v", err)
+	}
+	s := NewVFSCAStore(&kops.Cluster{}, strictRemovePath{memfsPath.(*vfs.MemFSPath)})
+
+	pubkey := "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQCySdqIU+FhCWl3BNrAvPaOe5VfL2aCARUWwy91ZP+T7LBwFa9lhdttfjp/VX1D1/PVwntn2EhN079m8c2kfdmiZ/iCHqrLyIGSd+BOiCz0lT47znvANSfxYjLUuKrWWWeaXqerJkOsAD4PHchRLbZGPdbfoBKwtb/WT4GMRQmb9vmiaZYjsfdPPM9KkWI9ECoWFGjGehA8D+iYIPR711kRacb1xdYmnjHqxAZHFsb5L8wDWIeAyhy49cBD+lbzTiioq2xWLorXuFmXh6Do89PgzvHeyCLY6816f/kCX6wIFts8A2eaEHFL4rAOsuh6qHmSxGCR9peSyuRW8DxV725x justin@test"
+	fingerprint, err := sshcredentials.Fingerprint(pubkey)
+	if err != nil {
+		t.Fatalf("error fingerprinting SSH public key: %v", err)
+	}
+
+	if err := s.AddSSHPublicKey("admin", []byte(pubkey)); err != nil {
+		t.Fatalf("error adding SSH public key: %v", err)
+	}
+
+	// Deleting an unknown key is not an error
+	if err := s.DeleteSSHCredentialByFingerprint("admin", "00:11:22:33:44:55:66:77:88:99:aa:bb:cc:dd:ee:ff"); err != nil {
+		t.Fatalf("unexpected error deleting unknown SSH public key: %v", err)
+	}
+	if err := s.DeleteSSHCredentialByFingerprint("other", fingerprint); err != nil {
+		t.Fatalf("unexpected error deleting unknown SSH public key: %v", err)
+	}
+
+	if err := s.DeleteSSHCredentialByFingerprint("admin", strings.ToUpper(strings.Replace(fingerprint, ":", "", -1))); err != nil {
+		t.Fatalf("unexpected error deleting SSH public key: %v", err)
+	}
+
+	sshCredentials, err := s.FindSSHPublicKeys("admin")
+	if err != nil {
+		t.Fatalf("unexpected error finding SSH public keys: %v", err)
+	}
+	if len(sshCredentials) != 0 {
+		t.Errorf("expected SSH public key to be deleted, got %v", sshCredentials)
+	}
+}
@@ -54,6 +54,50 @@ func BuildMirroredAsset(u *url.URL, hash *hashing.Hash) *MirroredAsset {
 	return a
 }
 
+// Resolver finds the locations from which a file asset can be downloaded, replacing the default kOps mirrors.
+type Resolver interface {
+	// Resolve returns the locations of the asset published at the upstream URL u, in order of preference.
+	// hash is nil if the hash of the asset is not known.
+	Resolve(u *url.URL, hash *hashing.Hash) ([]string, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface.
+type ResolverFunc func(u *url.URL, hash *hashing.Hash) ([]string, error)
+
+// Resolve calls f(u, hash).
+func (f ResolverFunc) Resolve(u *url.URL, hash *hashing.Hash) ([]string, error) {
+	return f(u, hash)
+}
+
+// ResolveMirroredAsset returns a copy of the asset with the locations found by the resolver for its upstream URL,
+// which is always the first location. The asset is returned unchanged if resolver is nil.
+func ResolveMirroredAsset(resolver Resolver, a *MirroredAsset) (*MirroredAsset, error) {
+	if resolver == nil {
+		return a, nil
+	}
+	if len(a.Locations) == 0 {
+		return nil, fmt.Errorf("asset has no locations")
+	}
+
+	u, err := url.Parse(a.Locations[0])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing asset location %q: %v", a.Locations[0], err)
+	}
+
+	locations, err := resolver.Resolve(u, a.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving mirrors for %q: %v", u, err)
+	}
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("no mirrors found for %q", u)
+	}
+
+	return &MirroredAsset{
+		Locations: locations,
+		Hash:      a.Hash,
+	}, nil
+}
+
 func (a *MirroredAsset) CompactString() string {
 	var s string
 	if a.Hash != nil {
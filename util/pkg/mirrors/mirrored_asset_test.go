@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mirrors
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/util/pkg/hashing"
+)
+
+func TestResolveMirroredAsset(t *testing.T) {
+	hash := hashing.MustFromString("0123456789012345678901234567890123456789012345678901234567890123")
+	asset := &MirroredAsset{
+		Locations: []string{"https://example.com/nodeup", "https://mirror.example.com/nodeup"},
+		Hash:      hash,
+	}
+
+	t.Run("nil resolver", func(t *testing.T) {
+		actual, err := ResolveMirroredAsset(nil, asset)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual != asset {
+			t.Errorf("expected asset to be returned unchanged, got %v", actual.CompactString())
+		}
+	})
+
+	t.Run("custom resolver", func(t *testing.T) {
+		resolver := ResolverFunc(func(u *url.URL, h *hashing.Hash) ([]string, error) {
+			if u.String() != "https://example.com/nodeup" {
+				t.Errorf("unexpected upstream URL %q", u)
+			}
+			if h != hash {
+				t.Errorf("unexpected hash %v", h)
+			}
+			return []string{"https://internal.example.com/nodeup", u.String()}, nil
+		})
+
+		actual, err := ResolveMirroredAsset(resolver, asset)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []string{"https://internal.example.com/nodeup", "https://example.com/nodeup"}
+		if !reflect.DeepEqual(actual.Locations, expected) {
+			t.Errorf("expected locations %v, got %v", expected, actual.Locations)
+		}
+		if actual.Hash != hash {
+			t.Errorf("expected hash to be preserved, got %v", actual.Hash)
+		}
+		if len(asset.Locations) != 2 || asset.Locations[0] != "https://example.com/nodeup" {
+			t.Errorf("original asset was modified: %v", asset.CompactString())
+		}
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		resolver := ResolverFunc(func(u *url.URL, h *hashing.Hash) ([]string, error) {
+			return nil, errors.New("unavailable")
+		})
+		if _, err := ResolveMirroredAsset(resolver, asset); err == nil {
+			t.Errorf("expected error from resolver to be returned")
+		}
+	})
+
+	t.Run("no mirrors", func(t *testing.T) {
+		resolver := ResolverFunc(func(u *url.URL, h *hashing.Hash) ([]string, error) {
+			return nil, nil
+		})
+		if _, err := ResolveMirroredAsset(resolver, asset); err == nil {
+			t.Errorf("expected error when resolver returns no mirrors")
+		}
+	})
+}
@@ -121,6 +121,11 @@ func (c *VFSContext) ReadFile(location string, options ...VFSOption) ([]byte, er
 			case "openstack":
 				httpURL := "http://169.254.169.254/latest/meta-data/" + u.Path
 				return c.readHTTPLocation(httpURL, nil, opts)
+			case "azure":
+				httpURL := "http://169.254.169.254/metadata/instance" + u.Path + "?api-version=2020-06-01&format=text"
+				httpHeaders := make(map[string]string)
+				httpHeaders["Metadata"] = "True"
+				return c.readHTTPLocation(httpURL, httpHeaders, opts)
 			default:
 				return nil, fmt.Errorf("unknown metadata type: %q in %q", u.Host, location)
 			}